@@ -9,8 +9,7 @@ document.addEventListener('DOMContentLoaded', async () => {
 });
 
 async function loadAuctions() {
-    const res = await fetch(`${API_BASE_URL}/auctions`, { credentials: 'include' });
-    const auctions = await res.json();
+    const auctions = await apiRequestAll('/auctions', true);
 
     const container = document.getElementById('auction-list');
     container.innerHTML = '';
@@ -13,8 +13,7 @@ document.addEventListener('DOMContentLoaded', async () => {
 });
 
 async function loadUsers() {
-  const res = await fetch(`${API_BASE_URL}/admin/users`, { method: 'GET', credentials: 'include' });
-  const users = await res.json();
+  const users = await apiRequestAll('/admin/users', true);
 
   const container = document.getElementById('user-list');
   container.innerHTML = '';
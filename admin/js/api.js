@@ -45,4 +45,18 @@ async function apiRequest(endpoint, method = 'GET', data = null, requiresAuth =
     }
   
     return response.json();
-  }
\ No newline at end of file
+  }
+// Follows next_cursor through a paginated list and returns every item
+async function apiRequestAll(endpoint, requiresAuth = false) {
+    const items = [];
+    let cursor = '';
+
+    do {
+      const separator = endpoint.includes('?') ? '&' : '?';
+      const page = await apiRequest(`${endpoint}${separator}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`, 'GET', null, requiresAuth);
+      items.push(...page.items);
+      cursor = page.next_cursor;
+    } while (cursor);
+
+    return items;
+  }
@@ -68,3 +68,18 @@ async function apiRequest(path, method = 'GET', body = null, withAuth = false, r
 
   return response.json();
 }
+
+// Follows next_cursor through a paginated list and returns every item
+async function apiRequestAll(path, withAuth = false) {
+  const items = [];
+  let cursor = '';
+
+  do {
+      const separator = path.includes('?') ? '&' : '?';
+      const page = await apiRequest(`${path}${separator}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`, 'GET', null, withAuth);
+      items.push(...page.items);
+      cursor = page.next_cursor;
+  } while (cursor);
+
+  return items;
+}
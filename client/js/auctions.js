@@ -58,7 +58,7 @@ async function loadAuctions() {
                       `&category=${encodeURIComponent(filters.category)}` +
                       `&status=${encodeURIComponent(filters.status)}`;
 
-        const auctions = await apiRequest(`/auctions?${query}`, 'GET', null, true);
+        const { items: auctions, next_cursor } = await apiRequest(`/auctions?${query}`, 'GET', null, true);
         const container = document.getElementById('auction-list');
         container.innerHTML = '';
 
@@ -98,7 +98,7 @@ async function loadAuctions() {
         });
 
         document.getElementById('prev-page').disabled = currentPage === 1;
-        document.getElementById('next-page').disabled = auctions.length < pageSize;
+        document.getElementById('next-page').disabled = !next_cursor;
         document.getElementById('page-info').textContent = `Page ${currentPage}`;
     } catch (error) {
       console.error('API Error Details:', error);
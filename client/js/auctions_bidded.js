@@ -14,7 +14,7 @@ document.addEventListener('DOMContentLoaded', async () => {
 
 async function loadBiddedAuctionDetails() {
     try {
-        const auctions = await apiRequest('/auctions/bidded', 'GET', null, true);
+        const auctions = await apiRequestAll('/auctions/bidded', true);
         const detailsDiv = document.getElementById('auction-details');
 
         if (!auctions.length) {
@@ -14,7 +14,7 @@ document.addEventListener('DOMContentLoaded', async () => {
 
 async function loadCreatedAuctions() {
     try {
-        const auctions = await apiRequest('/auctions/created-auctions', 'GET', null, true);
+        const auctions = await apiRequestAll('/auctions/created-auctions', true);
         const gridContainer = document.getElementById('auctions-grid-container'); 
 
         if (!auctions.length) {
@@ -7,23 +7,30 @@ document.addEventListener('DOMContentLoaded', async () => {
 async function loadWonAuctions() {
     try {
         console.log('Starting loadWonAuctions()...');
-        const res = await fetch(`${API_BASE_URL}/auctions/won`, {
-            credentials: 'include'
-        });
+        // follow next_cursor so every won auction is listed
+        const auctions = [];
+        let cursor = '';
+        do {
+            const res = await fetch(`${API_BASE_URL}/auctions/won?limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`, {
+                credentials: 'include'
+            });
 
-        if (res.status === 401) {
-            window.location.href = 'auth.html';
-            return;
-        }
-        
-        if (!res.ok) {
-            const errText = await res.text();  // Optional: for logging
-            console.error(`Error ${res.status}: ${errText}`);
-            document.getElementById('auction-list').innerHTML = '<p>Failed to load your auctions. Please try again later.</p>';
-            return;
-        }
+            if (res.status === 401) {
+                window.location.href = 'auth.html';
+                return;
+            }
+
+            if (!res.ok) {
+                const errText = await res.text();  // Optional: for logging
+                console.error(`Error ${res.status}: ${errText}`);
+                document.getElementById('auction-list').innerHTML = '<p>Failed to load your auctions. Please try again later.</p>';
+                return;
+            }
 
-        const auctions = await res.json();
+            const page = await res.json();
+            auctions.push(...page.items);
+            cursor = page.next_cursor;
+        } while (cursor);
         console.log('Auctions:', auctions);
 
         const container = document.getElementById('auction-list');
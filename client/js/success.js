@@ -4,13 +4,13 @@ document.addEventListener('DOMContentLoaded', async () => {
 
     if (sessionId) {
         try {
-            const res = await fetch(`${API_BASE_URL}/stripe/session/${sessionId}`, { credentials: 'include' });
-            const session = await res.json();
+            const res = await fetch(`${API_BASE_URL}/payments/session/${sessionId}/status`, { credentials: 'include' });
+            if (!res.ok) throw new Error('failed to load payment status');
+            const payment = await res.json();
 
             document.getElementById('payment-info').innerHTML = `
-                <p><strong>Amount Paid:</strong> $${(session.amount_total / 100).toFixed(2)}</p>
-                <p><strong>Status:</strong> ${session.payment_status}</p>
-                <p><strong>Amount Paid:</strong> ${(session.currency)}</p>
+                <p><strong>Amount Paid:</strong> $${payment.amount.toFixed(2)}</p>
+                <p><strong>Status:</strong> ${payment.status}</p>
             `;
         } catch (error) {
             document.getElementById('payment-info').textContent = 'Could not load payment details.';
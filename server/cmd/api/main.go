@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
+
 	"github.com/go-redis/redis/v8"
 	_ "github.com/lib/pq"
 	"github.com/puremike/online_auction_api/docs"
 	"github.com/puremike/online_auction_api/internal/auth"
 	"github.com/puremike/online_auction_api/internal/config"
 	"github.com/puremike/online_auction_api/internal/db"
-	"github.com/puremike/online_auction_api/internal/payments"
 	"github.com/puremike/online_auction_api/internal/routes"
+	"github.com/puremike/online_auction_api/internal/services"
 	"github.com/puremike/online_auction_api/internal/store"
 	"github.com/puremike/online_auction_api/internal/store/cache"
+	"github.com/puremike/online_auction_api/internal/tax"
+	"github.com/puremike/online_auction_api/internal/workers"
 	"github.com/puremike/online_auction_api/internal/ws"
 	"go.uber.org/zap"
 )
@@ -75,6 +79,21 @@ func main() {
 
 	gLm, sLm, hLm := config.MyRateLimiters(cfg)
 
+	taxes, err := tax.Load(cfg.TaxConf.RulesFile)
+	if err != nil {
+		logger.Fatalw("Failed to load tax rules", "error", err)
+	}
+
+	fees, err := config.MyFeeSchedules(cfg)
+	if err != nil {
+		logger.Fatalw("Failed to load fee schedules", "error", err)
+	}
+
+	sealer, err := config.MySealer(cfg)
+	if err != nil {
+		logger.Fatalw("Failed to set up two-factor secret sealing", "error", err)
+	}
+
 	app := &config.Application{
 		AppConfig: cfg,
 		Logger:    logger,
@@ -85,12 +104,24 @@ func main() {
 		GeneralRateLimiter:   gLm,
 		SensitiveRateLimiter: sLm,
 		HeavyOpsRateLimiter:  hLm,
-		Stripe:               payments.NewStripePayment(cfg.StripeConf.StripeSecretKey, cfg.StripeConf.CancelURL, cfg.StripeConf.SuccessURL),
+		Payments:             config.MyPaymentProvider(cfg),
+		Payouts:              config.MyPayoutProvider(cfg),
+		Tax:                  taxes,
+		Fees:                 fees,
 		RedisCache:           cache.NewRDBCacheStorage(rdb),
+		Mailer:               config.MyMailer(cfg),
+		Sealer:               sealer,
 	}
 
 	go app.WsHub.Run()
+	go workers.NewWatchReminder(app).Run(context.Background())
+	go workers.NewLedgerRelease(app).Run(context.Background())
+
+	paymentServices := services.NewPaymentServices(app)
+	go workers.NewPaymentReconciler(app, paymentServices.Payments).Run(context.Background())
+	go workers.NewOrderAutoComplete(app, paymentServices.Orders).Run(context.Background())
+	go workers.NewDisputeDeadline(app, paymentServices.Disputes).Run(context.Background())
 
-	mux := routes.Routes(app)
+	mux := routes.Routes(app, paymentServices)
 	logger.Fatal(routes.RunServer(mux, cfg.Port, logger))
 }
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// Sealer encrypts secrets the server has to read back, such as TOTP secrets,
+// with AES-256-GCM so a database dump alone doesn't reveal them
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer derives the encryption key from key with SHA-256
+func NewSealer(key string) (*Sealer, error) {
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts plaintext; the random nonce is prepended to the result
+func (s *Sealer) Seal(plaintext string) (string, error) {
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// Open decrypts what Seal returned
+func (s *Sealer) Open(sealed string) (string, error) {
+
+	b, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	if len(b) < s.aead.NonceSize() {
+		return "", errors.New("sealed value is too short")
+	}
+
+	plaintext, err := s.aead.Open(nil, b[:s.aead.NonceSize()], b[s.aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the SHA-1 secret of RFC 6238 appendix B, "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+
+	// the RFC lists 8 digit codes; ours are their last 6 digits
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range cases {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+}
+
+func TestValidateTOTPAcceptsAdjacentSteps(t *testing.T) {
+
+	now := time.Unix(1234567890, 0)
+	previous, err := TOTPCode(rfcSecret, TOTPStep(now)-1)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	stale, err := TOTPCode(rfcSecret, TOTPStep(now)-2)
+	require.NoError(t, err)
+
+	_, ok = ValidateTOTP(rfcSecret, stale, now)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(rfcSecret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+
+	uri := TOTPURI("OWAS", "jane@example.com", rfcSecret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/OWAS:jane@example.com?"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=OWAS")
+}
+
+func TestSealerRoundTrip(t *testing.T) {
+
+	sealer, err := NewSealer("key")
+	require.NoError(t, err)
+
+	sealed, err := sealer.Seal(rfcSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, rfcSecret)
+
+	opened, err := sealer.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, rfcSecret, opened)
+
+	other, err := NewSealer("other key")
+	require.NoError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+}
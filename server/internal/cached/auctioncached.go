@@ -79,6 +79,9 @@ func (m *AuctionCached) DeleteAuctionFromCache(ctx context.Context, auctionId st
 
 	// Delete user from database
 	if err := m.app.Store.Auctions.DeleteAuction(ctx, auctionId); err != nil {
+		if errors.Is(err, errs.ErrAuctionHasInvoice) {
+			return err
+		}
 		m.app.Logger.Errorw("failed to delete auction from db", "error", err)
 		return errs.ErrAuctionNotFound
 	}
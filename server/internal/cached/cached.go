@@ -11,6 +11,7 @@ import (
 type CachedUserInterface interface {
 	GetUserFromCache(ctx context.Context, userId string) (*models.User, error)
 	DeleteUserFromCache(ctx context.Context, userId string) error
+	EvictUser(ctx context.Context, userId string) error
 }
 
 type CachedAuctionInterface interface {
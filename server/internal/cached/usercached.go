@@ -91,3 +91,21 @@ func (m *UserCached) DeleteUserFromCache(ctx context.Context, userId string) err
 
 	return nil
 }
+
+// EvictUser drops a user from the cache, without touching the database, after
+// a change the auth middleware has to see on the next request
+func (m *UserCached) EvictUser(ctx context.Context, userId string) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	if !m.app.AppConfig.RedisCacheConf.Enabled {
+		return nil
+	}
+
+	if err := m.app.RedisCache.Users.Delete(ctx, userId); err != nil {
+		m.app.Logger.Errorw("failed to evict user from cache", "error", err)
+		return errs.NewHTTPError("failed to evict user from cache", http.StatusInternalServerError)
+	}
+
+	return nil
+}
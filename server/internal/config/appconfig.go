@@ -4,10 +4,12 @@ import (
 	"time"
 
 	"github.com/puremike/online_auction_api/internal/auth"
+	"github.com/puremike/online_auction_api/internal/mailer"
 	"github.com/puremike/online_auction_api/internal/payments"
 	"github.com/puremike/online_auction_api/internal/ratelimiters"
 	"github.com/puremike/online_auction_api/internal/store"
 	"github.com/puremike/online_auction_api/internal/store/cache"
+	"github.com/puremike/online_auction_api/internal/tax"
 
 	"github.com/puremike/online_auction_api/internal/ws"
 	"github.com/puremike/online_auction_api/pkg"
@@ -24,8 +26,13 @@ type Application struct {
 	GeneralRateLimiter   ratelimiters.Limiter
 	SensitiveRateLimiter ratelimiters.Limiter
 	HeavyOpsRateLimiter  ratelimiters.Limiter
-	Stripe               *payments.StripePayment
+	Payments             payments.PaymentProvider
+	Payouts              payments.PayoutProvider
+	Tax                  *tax.Engine
+	Fees                 *payments.FeeSchedules
 	RedisCache           *cache.Storage
+	Mailer               mailer.Mailer
+	Sealer               *auth.Sealer
 }
 
 type AppConfig struct {
@@ -37,8 +44,28 @@ type AppConfig struct {
 	SensitiveRL    RateLimiterConf
 	HeavyOpsRL     RateLimiterConf
 	StripeConf     StripeConf
+	PaymentConf    PaymentConf
+	CheckoutConf   CheckoutConf
+	TaxConf        TaxConf
+	FeeConf        FeeConf
+	HoldConf       HoldConf
+	LedgerConf     LedgerConf
+	ReconcileConf  ReconcileConf
+	OrderConf      OrderConf
+	DisputeConf    DisputeConf
+	FeedbackConf   FeedbackConf
+	MailConf       MailConf
+	VerifyConf     VerificationConf
+	ResetConf      PasswordResetConf
+	TwoFactorConf  TwoFactorConf
 	S3Bucket       string
 	RedisCacheConf RedisCacheConf
+	WatchlistConf  WatchlistConf
+}
+
+type WatchlistConf struct {
+	ReminderLeadTimes []time.Duration // how long before EndTime watchers are reminded
+	ReminderInterval  time.Duration   // how often the reminder worker scans for due reminders
 }
 
 type RedisCacheConf struct {
@@ -50,10 +77,121 @@ type RedisCacheConf struct {
 
 type StripeConf struct {
 	StripeSecretKey string
+	WebhookSecret   string
 	CancelURL       string
 	SuccessURL      string
 }
 
+// PaymentConf selects the payment provider. "fake" runs checkout entirely
+// in-process for local development and CI; it reuses the Stripe success and
+// cancel URLs.
+type PaymentConf struct {
+	Provider          string
+	FakeBaseURL       string // public base of the API the fake checkout pages and webhooks are served from
+	FakeWebhookSecret string
+}
+
+// CheckoutConf holds the default buyer's premium added to the final auction price at checkout
+type CheckoutConf struct {
+	FeePercent float64
+	FeeFixed   float64
+}
+
+// FeeConf points to the JSON fee schedules: per category and per seller buyer's
+// premiums and tiered seller fees. What it leaves out of the default schedule
+// comes from CheckoutConf and LedgerConf.
+type FeeConf struct {
+	ScheduleFile string
+}
+
+// HoldConf sizes the card holds bidders authorize on auctions with a hold threshold
+type HoldConf struct {
+	Percent   float64       // of the auction's hold threshold
+	ValidFor  time.Duration // how long an authorization can be relied on; card networks expire them after about a week
+	ReturnURL string        // where bidders land after authorizing a hold
+}
+
+// TaxConf points to the JSON rules the tax charged at checkout is computed
+// from. Without a rules file no tax is charged.
+type TaxConf struct {
+	RulesFile string
+}
+
+// LedgerConf configures what sellers are credited for a sale and when they can be paid out
+type LedgerConf struct {
+	PlatformFeePercent float64
+	PlatformFeeFixed   float64
+	HoldPeriod         time.Duration // how long sale funds without an order are held
+	ReleaseInterval    time.Duration // how often the release worker scans for funds past their hold period
+	PayoutProvider     string
+}
+
+// ReconcileConf configures the worker that checks pending payments against the
+// provider, for when a webhook never arrived
+type ReconcileConf struct {
+	Interval     time.Duration // how often pending payments are reconciled
+	PendingAge   time.Duration // how long a payment must have been pending before it is checked
+	AbandonAfter time.Duration // how long a checkout may stay open before its session is expired
+}
+
+// OrderConf configures the worker that completes orders the buyer never confirms
+type OrderConf struct {
+	ShippedAutoComplete   time.Duration // how long after shipping an order without a delivery confirmation is completed
+	DeliveredAutoComplete time.Duration // how long after delivery an order the buyer didn't complete or dispute is completed
+	AutoCompleteInterval  time.Duration // how often the worker scans for orders to complete
+}
+
+// DisputeConf sets how long buyers and sellers have to act on a dispute before
+// it goes to an admin, and how often the worker enforcing that runs
+type DisputeConf struct {
+	RespondWithin    time.Duration // how long a seller has to answer a dispute or confirm a returned item arrived
+	ReturnWithin     time.Duration // how long a buyer has to ship a return
+	DeadlineInterval time.Duration // how often the worker escalates disputes past their deadline
+}
+
+// FeedbackConf sets how long a rating can be changed after it is left
+type FeedbackConf struct {
+	EditWindow time.Duration
+}
+
+// MailConf selects how email is sent. "console" prints messages, or appends
+// them to File when it is set, for local development.
+type MailConf struct {
+	Provider     string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	File         string
+}
+
+// VerificationConf configures email verification. With Required off, unverified
+// users can still bid and create auctions.
+type VerificationConf struct {
+	TokenTTL       time.Duration // how long a verification link works
+	ResendCooldown time.Duration // how long a user waits before asking for another link
+	Required       bool
+	URL            string // page the link points to; the token is appended as ?token=
+}
+
+// PasswordResetConf configures the links mailed to users who forgot their password
+type PasswordResetConf struct {
+	TokenTTL time.Duration // how long a reset link works
+	URL      string        // page the link points to; the token is appended as ?token=
+}
+
+// TwoFactorConf configures TOTP two-factor authentication. SecretKey seals the
+// TOTP secrets in the database; changing it turns existing enrollments off in
+// effect, as their codes can no longer be checked. It defaults to the JWT secret.
+type TwoFactorConf struct {
+	Issuer            string        // name authenticator apps list the account under
+	ChallengeTTL      time.Duration // how long a password-verified login waits for its code
+	MaxAttempts       int           // wrong codes a login challenge takes before it is used up
+	RequiredForAdmins bool          // admins without two-factor can't log in or use admin routes
+	SecretKey         string
+}
+
 type RateLimiterConf struct {
 	Window   time.Duration
 	Limit    int
@@ -125,9 +263,100 @@ func MyCfg() *AppConfig {
 
 		StripeConf: StripeConf{
 			StripeSecretKey: pkg.GetEnvString("STRIPE_SECRET_KEY", ""),
+			WebhookSecret:   pkg.GetEnvString("STRIPE_WEBHOOK_SECRET", ""),
 			CancelURL:       pkg.GetEnvString("STRIPE_CANCEL_URL", ""),
 			SuccessURL:      pkg.GetEnvString("STRIPE_SUCCESS_URL", ""),
 		},
+
+		PaymentConf: PaymentConf{
+			Provider:          pkg.GetEnvString("PAYMENT_PROVIDER", "stripe"),
+			FakeBaseURL:       pkg.GetEnvString("FAKE_PAYMENTS_BASE_URL", "http://localhost:"+pkg.GetEnvString("PORT", "8080")+"/api/v1"),
+			FakeWebhookSecret: pkg.GetEnvString("FAKE_PAYMENTS_WEBHOOK_SECRET", "fake_whsec"),
+		},
+
+		CheckoutConf: CheckoutConf{
+			FeePercent: pkg.GetEnvFloat("CHECKOUT_FEE_PERCENT", 0),
+			FeeFixed:   pkg.GetEnvFloat("CHECKOUT_FEE_FIXED", 0),
+		},
+
+		FeeConf: FeeConf{
+			ScheduleFile: pkg.GetEnvString("FEE_SCHEDULE_FILE", ""),
+		},
+
+		HoldConf: HoldConf{
+			Percent:   pkg.GetEnvFloat("HOLD_PERCENT", 10),
+			ValidFor:  pkg.GetEnvTDuration("HOLD_VALID_FOR", 6*24*time.Hour),
+			ReturnURL: pkg.GetEnvString("HOLD_RETURN_URL", ""),
+		},
+
+		TaxConf: TaxConf{
+			RulesFile: pkg.GetEnvString("TAX_RULES_FILE", ""),
+		},
+
+		LedgerConf: LedgerConf{
+			PlatformFeePercent: pkg.GetEnvFloat("PLATFORM_FEE_PERCENT", 5),
+			PlatformFeeFixed:   pkg.GetEnvFloat("PLATFORM_FEE_FIXED", 0),
+			HoldPeriod:         pkg.GetEnvTDuration("PAYOUT_HOLD_PERIOD", 14*24*time.Hour),
+			ReleaseInterval:    pkg.GetEnvTDuration("PAYOUT_RELEASE_INTERVAL", 10*time.Minute),
+			PayoutProvider:     pkg.GetEnvString("PAYOUT_PROVIDER", "stub"),
+		},
+
+		ReconcileConf: ReconcileConf{
+			Interval:     pkg.GetEnvTDuration("RECONCILE_INTERVAL", 15*time.Minute),
+			PendingAge:   pkg.GetEnvTDuration("RECONCILE_PENDING_AGE", 30*time.Minute),
+			AbandonAfter: pkg.GetEnvTDuration("RECONCILE_ABANDON_AFTER", 24*time.Hour),
+		},
+
+		OrderConf: OrderConf{
+			ShippedAutoComplete:   pkg.GetEnvTDuration("ORDER_SHIPPED_AUTO_COMPLETE", 14*24*time.Hour),
+			DeliveredAutoComplete: pkg.GetEnvTDuration("ORDER_DELIVERED_AUTO_COMPLETE", 3*24*time.Hour),
+			AutoCompleteInterval:  pkg.GetEnvTDuration("ORDER_AUTO_COMPLETE_INTERVAL", time.Hour),
+		},
+
+		DisputeConf: DisputeConf{
+			RespondWithin:    pkg.GetEnvTDuration("DISPUTE_RESPOND_WITHIN", 3*24*time.Hour),
+			ReturnWithin:     pkg.GetEnvTDuration("DISPUTE_RETURN_WITHIN", 7*24*time.Hour),
+			DeadlineInterval: pkg.GetEnvTDuration("DISPUTE_DEADLINE_INTERVAL", 15*time.Minute),
+		},
+
+		FeedbackConf: FeedbackConf{
+			EditWindow: pkg.GetEnvTDuration("FEEDBACK_EDIT_WINDOW", 7*24*time.Hour),
+		},
+
+		MailConf: MailConf{
+			Provider:     pkg.GetEnvString("MAILER", "console"),
+			From:         pkg.GetEnvString("MAIL_FROM", "no-reply@localhost"),
+			SMTPHost:     pkg.GetEnvString("SMTP_HOST", "localhost"),
+			SMTPPort:     pkg.GetEnvInt("SMTP_PORT", 587),
+			SMTPUsername: pkg.GetEnvString("SMTP_USERNAME", ""),
+			SMTPPassword: pkg.GetEnvString("SMTP_PASSWORD", ""),
+			File:         pkg.GetEnvString("MAILER_FILE", ""),
+		},
+
+		VerifyConf: VerificationConf{
+			TokenTTL:       pkg.GetEnvTDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			ResendCooldown: pkg.GetEnvTDuration("EMAIL_VERIFICATION_RESEND_COOLDOWN", time.Minute),
+			Required:       pkg.GetEnvBool("EMAIL_VERIFICATION_REQUIRED", false),
+			URL:            pkg.GetEnvString("EMAIL_VERIFICATION_URL", pkg.GetEnvString("FRONTEND_URL", "http://localhost:3000")+"/verify-email"),
+		},
+
+		ResetConf: PasswordResetConf{
+			TokenTTL: pkg.GetEnvTDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+			URL:      pkg.GetEnvString("PASSWORD_RESET_URL", pkg.GetEnvString("FRONTEND_URL", "http://localhost:3000")+"/reset-password"),
+		},
+
+		TwoFactorConf: TwoFactorConf{
+			Issuer:            pkg.GetEnvString("TWO_FACTOR_ISSUER", "OWAS"),
+			ChallengeTTL:      pkg.GetEnvTDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+			MaxAttempts:       pkg.GetEnvInt("TWO_FACTOR_MAX_ATTEMPTS", 5),
+			RequiredForAdmins: pkg.GetEnvBool("TWO_FACTOR_REQUIRED_FOR_ADMINS", false),
+			SecretKey:         pkg.GetEnvString("TWO_FACTOR_SECRET_KEY", ""),
+		},
+
+		WatchlistConf: WatchlistConf{
+			ReminderLeadTimes: pkg.GetEnvDurations("WATCHLIST_REMINDER_LEAD_TIMES", []time.Duration{24 * time.Hour, time.Hour}),
+			ReminderInterval:  pkg.GetEnvTDuration("WATCHLIST_REMINDER_INTERVAL", time.Minute),
+		},
 	}
 }
 
@@ -150,3 +379,48 @@ func MyRateLimiters(cfg *AppConfig) (gLm, sLm, hLm ratelimiters.Limiter) {
 
 	return generalRL, sensitiveRL, heavyOpsRL
 }
+
+func MyPaymentProvider(cfg *AppConfig) payments.PaymentProvider {
+
+	if cfg.PaymentConf.Provider == "fake" {
+		return payments.NewFakePayment(cfg.PaymentConf.FakeBaseURL, cfg.PaymentConf.FakeWebhookSecret, cfg.StripeConf.CancelURL, cfg.StripeConf.SuccessURL)
+	}
+
+	return payments.NewStripePayment(cfg.StripeConf.StripeSecretKey, cfg.StripeConf.WebhookSecret, cfg.StripeConf.CancelURL, cfg.StripeConf.SuccessURL)
+}
+
+// MyFeeSchedules loads the fee schedules, defaulting to a flat buyer's premium
+// and a single-tier seller fee from the environment
+func MyFeeSchedules(cfg *AppConfig) (*payments.FeeSchedules, error) {
+	return payments.LoadFeeSchedules(cfg.FeeConf.ScheduleFile, payments.FeeSchedule{
+		BuyerPremium: &payments.Fees{Percent: cfg.CheckoutConf.FeePercent, Fixed: cfg.CheckoutConf.FeeFixed},
+		SellerFee:    &payments.TieredFee{Tiers: []payments.Tier{{Percent: cfg.LedgerConf.PlatformFeePercent}}, Fixed: cfg.LedgerConf.PlatformFeeFixed},
+	})
+}
+
+// MyPayoutProvider returns the provider seller payouts are sent through. Only
+// the local stub exists until sellers can onboard to Stripe Connect.
+func MyPayoutProvider(cfg *AppConfig) payments.PayoutProvider {
+	return payments.NewStubPayout()
+}
+
+// MyMailer returns the mailer transactional email is sent through
+func MyMailer(cfg *AppConfig) mailer.Mailer {
+
+	if cfg.MailConf.Provider == "smtp" {
+		return mailer.NewSMTPMailer(cfg.MailConf.SMTPHost, cfg.MailConf.SMTPPort, cfg.MailConf.SMTPUsername, cfg.MailConf.SMTPPassword, cfg.MailConf.From)
+	}
+
+	return mailer.NewConsoleMailer(cfg.MailConf.File)
+}
+
+// MySealer returns the sealer TOTP secrets are encrypted with
+func MySealer(cfg *AppConfig) (*auth.Sealer, error) {
+
+	key := cfg.TwoFactorConf.SecretKey
+	if key == "" {
+		key = cfg.AuthConfig.Secret
+	}
+
+	return auth.NewSealer(key)
+}
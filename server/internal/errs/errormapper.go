@@ -55,15 +55,39 @@ var (
 	ErrInvalidPassword        = NewHTTPError("invalid password", http.StatusBadRequest)
 	ErrFailedToChangePassword = NewHTTPError("failed to change password", http.StatusBadRequest)
 
-	ErrTokenNotFound           = NewHTTPError("token not found", http.StatusNotFound)
-	ErrInvalidRefreshToken     = NewHTTPError("invalid refresh token", http.StatusBadRequest)
-	ErrRefreshTokenNotFound    = NewHTTPError("refresh token not found", http.StatusNotFound)
-	ErrFailedToGenToken        = NewHTTPError("failed to generate token", http.StatusInternalServerError)
-	ErrFailedToGenRefreshToken = NewHTTPError("failed to generate refresh token", http.StatusInternalServerError)
-	ErrFailedToStoreToken      = NewHTTPError("failed to store token", http.StatusInternalServerError)
-	ErrFailedToHashPassword    = NewHTTPError("failed to hash password", http.StatusInternalServerError)
+	ErrTokenNotFound            = NewHTTPError("token not found", http.StatusNotFound)
+	ErrInvalidRefreshToken      = NewHTTPError("invalid or expired refresh token", http.StatusUnauthorized)
+	ErrRefreshTokenReused       = NewHTTPError("refresh token was already used, log in again", http.StatusUnauthorized)
+	ErrSessionNotFound          = NewHTTPError("session not found", http.StatusNotFound)
+	ErrFailedToRetrieveSessions = NewHTTPError("failed to retrieve sessions", http.StatusInternalServerError)
+	ErrFailedToRevokeSession    = NewHTTPError("failed to revoke session", http.StatusInternalServerError)
+	ErrRefreshTokenNotFound     = NewHTTPError("refresh token not found", http.StatusNotFound)
+	ErrFailedToGenToken         = NewHTTPError("failed to generate token", http.StatusInternalServerError)
+	ErrFailedToGenRefreshToken  = NewHTTPError("failed to generate refresh token", http.StatusInternalServerError)
+	ErrFailedToStoreToken       = NewHTTPError("failed to store token", http.StatusInternalServerError)
+	ErrFailedToHashPassword     = NewHTTPError("failed to hash password", http.StatusInternalServerError)
+
+	// Email verification and password reset related errors
+	ErrInvalidToken              = NewHTTPError("this link is invalid or has expired", http.StatusBadRequest)
+	ErrEmailNotVerified          = NewHTTPError("verify your email address to continue", http.StatusForbidden)
+	ErrEmailAlreadyVerified      = NewHTTPError("your email address is already verified", http.StatusConflict)
+	ErrVerificationResendTooSoon = NewHTTPError("a verification email was sent recently, try again later", http.StatusTooManyRequests)
+	ErrFailedToSendVerification  = NewHTTPError("failed to send verification email", http.StatusInternalServerError)
+	ErrFailedToVerifyEmail       = NewHTTPError("failed to verify email", http.StatusInternalServerError)
+	ErrFailedToResetPassword     = NewHTTPError("failed to reset password", http.StatusInternalServerError)
+
+	// Two-factor authentication related errors
+	ErrTwoFactorNotEnabled       = NewHTTPError("two-factor authentication is not enabled", http.StatusConflict)
+	ErrTwoFactorAlreadyEnabled   = NewHTTPError("two-factor authentication is already enabled", http.StatusConflict)
+	ErrNotAnAdmin                = NewHTTPError("user is not an admin", http.StatusUnauthorized)
+	ErrTwoFactorRequired         = NewHTTPError("admins must enable two-factor authentication", http.StatusForbidden)
+	ErrInvalidTwoFactorCode      = NewHTTPError("invalid authentication code", http.StatusUnauthorized)
+	ErrInvalidTwoFactorChallenge = NewHTTPError("login challenge is invalid or has expired, log in again", http.StatusUnauthorized)
+	ErrFailedToSetUpTwoFactor    = NewHTTPError("failed to set up two-factor authentication", http.StatusInternalServerError)
+	ErrFailedToVerifyTwoFactor   = NewHTTPError("failed to verify authentication code", http.StatusInternalServerError)
 
 	ErrAuctionNotFound             = NewHTTPError("auction not found", http.StatusNotFound)
+	ErrAuctionHasInvoice           = NewHTTPError("auction has an invoice and can't be deleted", http.StatusConflict)
 	ErrInvalidAuctionDetails       = NewHTTPError("invalid auction details", http.StatusBadRequest)
 	ErrFailedToCreateAuction       = NewHTTPError("failed to create auction", http.StatusBadRequest)
 	ErrFailedToUpdateAuction       = NewHTTPError("failed to update auction", http.StatusBadRequest)
@@ -82,6 +106,73 @@ var (
 	ErrDuplicateSealedBid          = NewHTTPError("duplicate sealed bid", http.StatusBadRequest)
 	ErrFailedToDeleteBids          = NewHTTPError("failed to delete bids", http.StatusBadRequest)
 	ErrFailedToDeleteNotifications = NewHTTPError("failed to delete notifications", http.StatusBadRequest)
+	ErrSearchQueryTooLong          = NewHTTPError("search query is too long", http.StatusBadRequest)
+	ErrInvalidAuctionFilter        = NewHTTPError("invalid auction filter", http.StatusBadRequest)
+	ErrInvalidCursor               = NewHTTPError("invalid pagination cursor", http.StatusBadRequest)
+	ErrInvalidPageSize             = NewHTTPError("limit must be a positive number", http.StatusBadRequest)
+	ErrInvalidSortKey              = NewHTTPError("invalid sort key, use one of: ending_soon, newest, price_asc, price_desc, most_bids", http.StatusBadRequest)
+
+	// Watchlist related errors
+	ErrWatchNotFound          = NewHTTPError("auction is not on your watchlist", http.StatusNotFound)
+	ErrCannotWatchOwnAuction  = NewHTTPError("seller cannot watch their own auction", http.StatusBadRequest)
+	ErrFailedToWatchAuction   = NewHTTPError("failed to watch auction", http.StatusInternalServerError)
+	ErrFailedToUnwatchAuction = NewHTTPError("failed to unwatch auction", http.StatusInternalServerError)
+
+	// Saved search related errors
+	ErrSavedSearchNotFound         = NewHTTPError("saved search not found", http.StatusNotFound)
+	ErrInvalidSavedSearch          = NewHTTPError("invalid saved search details", http.StatusBadRequest)
+	ErrInvalidPriceRange           = NewHTTPError("min_price cannot be greater than max_price", http.StatusBadRequest)
+	ErrFailedToSaveSearch          = NewHTTPError("failed to save search", http.StatusInternalServerError)
+	ErrFailedToUpdateSavedSearch   = NewHTTPError("failed to update saved search", http.StatusInternalServerError)
+	ErrFailedToDeleteSavedSearch   = NewHTTPError("failed to delete saved search", http.StatusInternalServerError)
+	ErrFailedToRetrieveSavedSearch = NewHTTPError("failed to retrieve saved searches", http.StatusInternalServerError)
+
+	// Shipping related errors
+	ErrAddressNotFound            = NewHTTPError("address not found", http.StatusNotFound)
+	ErrFailedToSaveAddress        = NewHTTPError("failed to save address", http.StatusInternalServerError)
+	ErrFailedToDeleteAddress      = NewHTTPError("failed to delete address", http.StatusInternalServerError)
+	ErrFailedToRetrieveAddresses  = NewHTTPError("failed to retrieve addresses", http.StatusInternalServerError)
+	ErrInvalidShippingOptions     = NewHTTPError("invalid shipping options, offer each method once, give flat rates a price and weight rates a rates table covering the item's weight", http.StatusBadRequest)
+	ErrShippingMethodNotOffered   = NewHTTPError("the seller does not offer this shipping method", http.StatusBadRequest)
+	ErrShippingAddressRequired    = NewHTTPError("a shipping address is required unless picking up", http.StatusBadRequest)
+	ErrNotAllowedToSelectShipping = NewHTTPError("only the winner of a closed auction can choose its shipping", http.StatusForbidden)
+	ErrShippingSelectionNotFound  = NewHTTPError("no shipping has been chosen for this auction", http.StatusNotFound)
+	ErrShippingNotSelected        = NewHTTPError("choose a shipping address and method before checking out", http.StatusConflict)
+	ErrFailedToSelectShipping     = NewHTTPError("failed to save shipping selection", http.StatusInternalServerError)
+	ErrFailedToRetrieveShipping   = NewHTTPError("failed to retrieve shipping selection", http.StatusInternalServerError)
+	ErrNotAllowedToViewShipping   = NewHTTPError("only the buyer, the seller or an admin can see an auction's shipping", http.StatusForbidden)
+
+	// Order related errors
+	ErrOrderNotFound           = NewHTTPError("order not found", http.StatusNotFound)
+	ErrIllegalOrderTransition  = NewHTTPError("the order can't move to that status from its current one", http.StatusConflict)
+	ErrNotAllowedToUpdateOrder = NewHTTPError("only the buyer or the seller of the order can do this", http.StatusForbidden)
+	ErrFailedToCreateOrder     = NewHTTPError("failed to create order", http.StatusInternalServerError)
+	ErrFailedToUpdateOrder     = NewHTTPError("failed to update order", http.StatusInternalServerError)
+	ErrFailedToRetrieveOrders  = NewHTTPError("failed to retrieve orders", http.StatusInternalServerError)
+
+	// Dispute related errors
+	ErrDisputeNotFound            = NewHTTPError("dispute not found", http.StatusNotFound)
+	ErrIllegalDisputeTransition   = NewHTTPError("the dispute can't move to that status from its current one", http.StatusConflict)
+	ErrInvalidDisputeStatus       = NewHTTPError("invalid dispute status", http.StatusBadRequest)
+	ErrOrderNotDisputable         = NewHTTPError("only orders that are not yet completed can be disputed, and only once", http.StatusConflict)
+	ErrNotAllowedToOpenDispute    = NewHTTPError("only the buyer of an order can dispute it", http.StatusForbidden)
+	ErrNotAllowedToUpdateDispute  = NewHTTPError("only the party whose turn it is can do this", http.StatusForbidden)
+	ErrFailedToCreateDispute      = NewHTTPError("failed to open dispute", http.StatusInternalServerError)
+	ErrFailedToUpdateDispute      = NewHTTPError("failed to update dispute", http.StatusInternalServerError)
+	ErrFailedToRetrieveDisputes   = NewHTTPError("failed to retrieve disputes", http.StatusInternalServerError)
+	ErrFailedToPostDisputeMessage = NewHTTPError("failed to post dispute message", http.StatusInternalServerError)
+
+	// Feedback related errors
+	ErrFeedbackNotFound           = NewHTTPError("feedback not found", http.StatusNotFound)
+	ErrFeedbackNotAllowed         = NewHTTPError("feedback can only be left on completed orders, by their buyer or seller", http.StatusForbidden)
+	ErrFeedbackAlreadyLeft        = NewHTTPError("you already left feedback for this order, edit it instead", http.StatusConflict)
+	ErrFeedbackEditWindowClosed   = NewHTTPError("this feedback can no longer be edited", http.StatusConflict)
+	ErrNotAllowedToUpdateFeedback = NewHTTPError("only the author of a feedback can edit it", http.StatusForbidden)
+	ErrNotAllowedToReplyFeedback  = NewHTTPError("only the seller a feedback is about can reply to it", http.StatusForbidden)
+	ErrFeedbackAlreadyReplied     = NewHTTPError("this feedback already has a reply", http.StatusConflict)
+	ErrInvalidFeedbackRole        = NewHTTPError("invalid feedback role, use seller or buyer", http.StatusBadRequest)
+	ErrFailedToSaveFeedback       = NewHTTPError("failed to save feedback", http.StatusInternalServerError)
+	ErrFailedToRetrieveFeedback   = NewHTTPError("failed to retrieve feedback", http.StatusInternalServerError)
 
 	// Payment related errors
 	ErrFailedToCreateStripeCheckout   = NewHTTPError("failed to create Stripe checkout session", http.StatusInternalServerError)
@@ -90,9 +181,44 @@ var (
 	ErrMissingRequiredMetadata        = NewHTTPError("missing required metadata", http.StatusBadRequest)
 	ErrMissingRequiredSessionMetadata = NewHTTPError("missing required session metadata", http.StatusBadRequest)
 	ErrFailedToUnmarshalEvent         = NewHTTPError("failed to unmarshal Stripe event", http.StatusBadRequest)
+	ErrInvalidWebhookSignature        = NewHTTPError("invalid webhook signature", http.StatusBadRequest)
 	ErrFailedToGetPayment             = NewHTTPError("failed to get payment record", http.StatusNotFound)
 	ErrFailedToUpdatePayment          = NewHTTPError("failed to update payment record", http.StatusInternalServerError)
 	ErrFailedToCreatePayment          = NewHTTPError("failed to create payment record", http.StatusInternalServerError)
+	ErrPaymentNotFound                = NewHTTPError("payment not found", http.StatusNotFound)
+	ErrAuctionNotClosed               = NewHTTPError("auction must be closed before checkout", http.StatusBadRequest)
+	ErrNotAuctionWinner               = NewHTTPError("only the winner of the auction can check out", http.StatusForbidden)
+	ErrAuctionAlreadyPaid             = NewHTTPError("auction has already been paid for", http.StatusConflict)
+	ErrCheckoutAlreadyCompleted       = NewHTTPError("checkout already completed, payment is being confirmed", http.StatusConflict)
+	ErrIllegalPaymentTransition       = NewHTTPError("illegal payment status transition", http.StatusConflict)
+	ErrFailedToRecordWebhookEvent     = NewHTTPError("failed to record webhook event", http.StatusInternalServerError)
+	ErrFailedToProcessWebhookEvent    = NewHTTPError("failed to process webhook event", http.StatusInternalServerError)
+	ErrWebhookEventNotFound           = NewHTTPError("webhook event not found", http.StatusNotFound)
+	ErrWebhookEventNotReplayable      = NewHTTPError("only failed webhook events can be replayed", http.StatusConflict)
+	ErrInvalidWebhookEventStatus      = NewHTTPError("invalid webhook event status", http.StatusBadRequest)
+	ErrFailedToRetrieveWebhookEvents  = NewHTTPError("failed to retrieve webhook events", http.StatusInternalServerError)
+	ErrPaymentNotRefundable           = NewHTTPError("only completed payments can be refunded", http.StatusConflict)
+	ErrRefundExceedsPayment           = NewHTTPError("refund exceeds the amount left to refund", http.StatusBadRequest)
+	ErrInvalidRefund                  = NewHTTPError("refund amount must be positive and a reason is required", http.StatusBadRequest)
+	ErrNotAllowedToRefund             = NewHTTPError("only an admin or the seller can refund this payment", http.StatusForbidden)
+	ErrFailedToRefundPayment          = NewHTTPError("failed to refund payment", http.StatusBadGateway)
+	ErrFailedToRetrieveRefunds        = NewHTTPError("failed to retrieve refunds", http.StatusInternalServerError)
+	ErrNothingToPayOut                = NewHTTPError("no available balance to pay out", http.StatusConflict)
+	ErrFailedToGetBalance             = NewHTTPError("failed to get balance", http.StatusInternalServerError)
+	ErrFailedToPayOut                 = NewHTTPError("failed to pay out balance", http.StatusBadGateway)
+	ErrFailedToRetrievePayouts        = NewHTTPError("failed to retrieve payouts", http.StatusInternalServerError)
+	ErrPaymentNotCompleted            = NewHTTPError("payment is not completed", http.StatusConflict)
+	ErrFailedToReconcilePayments      = NewHTTPError("failed to reconcile payments", http.StatusInternalServerError)
+	ErrInvalidMismatchAction          = NewHTTPError("invalid payment mismatch action", http.StatusBadRequest)
+	ErrFailedToRetrieveMismatches     = NewHTTPError("failed to retrieve payment mismatches", http.StatusInternalServerError)
+	ErrInvoiceNotFound                = NewHTTPError("invoice not found", http.StatusNotFound)
+	ErrFailedToIssueInvoice           = NewHTTPError("failed to issue invoice", http.StatusInternalServerError)
+	ErrInvalidInvoiceFormat           = NewHTTPError("invalid invoice format, use json or html", http.StatusBadRequest)
+	ErrBidHoldRequired                = NewHTTPError("an authorized card hold is required to bid this amount on this auction", http.StatusPaymentRequired)
+	ErrBidHoldNotRequired             = NewHTTPError("this auction does not require a card hold", http.StatusBadRequest)
+	ErrBidHoldNotFound                = NewHTTPError("bid hold not found", http.StatusNotFound)
+	ErrFailedToCreateBidHold          = NewHTTPError("failed to create bid hold", http.StatusBadGateway)
+	ErrFailedToGetBidHold             = NewHTTPError("failed to get bid hold", http.StatusInternalServerError)
 )
 
 // MapServiceErrors maps service-level errors to appropriate HTTP responses.
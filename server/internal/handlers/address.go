@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type AddressHandler struct {
+	service services.AddressServiceInterface
+}
+
+func NewAddressHandler(service services.AddressServiceInterface) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+	}
+}
+
+// CreateAddress godoc
+//
+//	@Summary		Add an address
+//	@Description	Adds a shipping address to the authenticated user's address book. The first address, or one sent with is_default, becomes the default.
+//	@Tags			Addresses
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.AddressRequest	true	"Address payload"
+//	@Success		201		{object}	models.Address			"Created address"
+//	@Failure		400		{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H					"Internal Server Error - failed to save address"
+//	@Router			/me/addresses [post]
+//
+//	@Security		jwtCookieAuth
+func (a *AddressHandler) CreateAddress(c *gin.Context) {
+
+	var payload models.AddressRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	address, err := a.service.CreateAddress(c.Request.Context(), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// GetAddresses godoc
+//
+//	@Summary		Get my addresses
+//	@Description	Retrieves the authenticated user's address book.
+//	@Tags			Addresses
+//	@Produce		json
+//	@Param			limit	query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.Address]	"Page of addresses"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve addresses"
+//	@Router			/me/addresses [get]
+//
+//	@Security		jwtCookieAuth
+func (a *AddressHandler) GetAddresses(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	addresses, err := a.service.GetAddresses(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// UpdateAddress godoc
+//
+//	@Summary		Update an address
+//	@Description	Replaces one of the authenticated user's addresses. Sending is_default makes it the default; shipping already chosen for an auction keeps the address it was chosen with.
+//	@Tags			Addresses
+//	@Accept			json
+//	@Produce		json
+//	@Param			addressID	path		string					true	"ID of the address"
+//	@Param			payload		body		models.AddressRequest	true	"Address payload"
+//	@Success		200			{object}	models.Address			"Updated address"
+//	@Failure		400			{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H					"NotFound - address not found"
+//	@Failure		500			{object}	gin.H					"Internal Server Error - failed to save address"
+//	@Router			/me/addresses/{addressID} [put]
+//
+//	@Security		jwtCookieAuth
+func (a *AddressHandler) UpdateAddress(c *gin.Context) {
+
+	var payload models.AddressRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	address, err := a.service.UpdateAddress(c.Request.Context(), c.Param("addressID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress godoc
+//
+//	@Summary		Delete an address
+//	@Description	Removes one of the authenticated user's addresses. If it was the default, the newest remaining address becomes the default.
+//	@Tags			Addresses
+//	@Produce		json
+//	@Param			addressID	path		string	true	"ID of the address"
+//	@Success		200			{object}	gin.H	"Deleted address message"
+//	@Failure		401			{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H	"NotFound - address not found"
+//	@Failure		500			{object}	gin.H	"Internal Server Error - failed to delete address"
+//	@Router			/me/addresses/{addressID} [delete]
+//
+//	@Security		jwtCookieAuth
+func (a *AddressHandler) DeleteAddress(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	message, err := a.service.DeleteAddress(c.Request.Context(), c.Param("addressID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -7,13 +7,18 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/puremike/online_auction_api/contexts"
 	"github.com/puremike/online_auction_api/internal/config"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/services"
 )
 
+// maxSearchQueryLength caps the q parameter of GET /auctions
+const maxSearchQueryLength = 200
+
 type AuctionHandler struct {
 	service services.AuctionServiceInterface
 	app     *config.Application
@@ -67,18 +72,21 @@ func (a *AuctionHandler) CreateAuction(c *gin.Context) {
 	}
 
 	auction := &models.Auction{
-		SellerID:      authUser.ID,
-		Title:         payload.Title,
-		Description:   payload.Description,
-		StartingPrice: payload.StartingPrice,
-		CurrentPrice:  payload.StartingPrice,
-		Type:          strings.ToLower(payload.Type),
-		Status:        "open",
-		StartTime:     startDate,
-		EndTime:       endDate,
-		ImagePath:     payload.ImagePath,
-		Category:      payload.Category,
-		IsPaid:        false,
+		SellerID:        authUser.ID,
+		Title:           payload.Title,
+		Description:     payload.Description,
+		StartingPrice:   payload.StartingPrice,
+		CurrentPrice:    payload.StartingPrice,
+		Type:            strings.ToLower(payload.Type),
+		Status:          "open",
+		StartTime:       startDate,
+		EndTime:         endDate,
+		ImagePath:       payload.ImagePath,
+		Category:        payload.Category,
+		IsPaid:          false,
+		HoldThreshold:   payload.HoldThreshold,
+		WeightKg:        payload.WeightKg,
+		ShippingOptions: payload.ShippingOptions,
 	}
 
 	createdAuction, err := a.service.CreateAuction(c.Request.Context(), auction)
@@ -88,19 +96,22 @@ func (a *AuctionHandler) CreateAuction(c *gin.Context) {
 	}
 
 	res := &models.CreateAuctionResponse{
-		ID:            createdAuction.ID,
-		SellerID:      createdAuction.SellerID,
-		Title:         createdAuction.Title,
-		Description:   createdAuction.Description,
-		StartingPrice: createdAuction.StartingPrice,
-		CurrentPrice:  createdAuction.CurrentPrice,
-		Type:          createdAuction.Type,
-		Status:        createdAuction.Status,
-		StartTime:     createdAuction.StartTime,
-		EndTime:       createdAuction.EndTime,
-		CreatedAt:     createdAuction.CreatedAt,
-		ImagePath:     createdAuction.ImagePath,
-		Category:      createdAuction.Category,
+		ID:              createdAuction.ID,
+		SellerID:        createdAuction.SellerID,
+		Title:           createdAuction.Title,
+		Description:     createdAuction.Description,
+		StartingPrice:   createdAuction.StartingPrice,
+		CurrentPrice:    createdAuction.CurrentPrice,
+		Type:            createdAuction.Type,
+		Status:          createdAuction.Status,
+		StartTime:       createdAuction.StartTime,
+		EndTime:         createdAuction.EndTime,
+		CreatedAt:       createdAuction.CreatedAt,
+		ImagePath:       createdAuction.ImagePath,
+		Category:        createdAuction.Category,
+		HoldThreshold:   createdAuction.HoldThreshold,
+		WeightKg:        createdAuction.WeightKg,
+		ShippingOptions: createdAuction.ShippingOptions,
 	}
 
 	c.JSON(http.StatusCreated, res)
@@ -160,15 +171,18 @@ func (a *AuctionHandler) UpdateAuction(c *gin.Context) {
 	}
 
 	auction := &models.Auction{
-		SellerID:      authUser.ID,
-		Title:         payload.Title,
-		Description:   payload.Description,
-		StartingPrice: payload.StartingPrice,
-		CurrentPrice:  payload.StartingPrice,
-		Type:          strings.ToLower(payload.Type),
-		Status:        "open",
-		StartTime:     startDate,
-		EndTime:       endDate,
+		SellerID:        authUser.ID,
+		Title:           payload.Title,
+		Description:     payload.Description,
+		StartingPrice:   payload.StartingPrice,
+		CurrentPrice:    payload.StartingPrice,
+		Type:            strings.ToLower(payload.Type),
+		Status:          "open",
+		StartTime:       startDate,
+		EndTime:         endDate,
+		HoldThreshold:   payload.HoldThreshold,
+		WeightKg:        payload.WeightKg,
+		ShippingOptions: payload.ShippingOptions,
 	}
 
 	updatedAuction, err := a.service.UpdateAuction(c.Request.Context(), auction, existingAuction.ID)
@@ -191,6 +205,7 @@ func (a *AuctionHandler) UpdateAuction(c *gin.Context) {
 //	@Success		200			{object}	models.Auction	"Deleted auction"
 //	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
 //	@Failure		404			{object}	gin.H			"NotFound - auction not found"
+//	@Failure		409			{object}	gin.H			"Conflict - auction has an invoice"
 //	@Failure		500			{object}	gin.H			"Internal Server Error - failed to delete auction"
 //	@Router			/auctions/{auctionID} [delete]
 //
@@ -234,6 +249,7 @@ func (a *AuctionHandler) DeleteAuction(c *gin.Context) {
 //	@Success		200			{object}	models.Auction	"Deleted auction"
 //	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
 //	@Failure		404			{object}	gin.H			"NotFound - auction not found"
+//	@Failure		409			{object}	gin.H			"Conflict - auction has an invoice"
 //	@Failure		500			{object}	gin.H			"Internal Server Error - failed to delete auction"
 //	@Router			/admin/auctions/{auctionID} [delete]
 //
@@ -296,18 +312,22 @@ func (a *AuctionHandler) GetAuctionById(c *gin.Context) {
 	}
 
 	res := &models.CreateAuctionResponse{
-		ID:            auction.ID,
-		SellerID:      auction.SellerID,
-		Title:         auction.Title,
-		Description:   auction.Description,
-		StartingPrice: auction.StartingPrice,
-		CurrentPrice:  auction.CurrentPrice,
-		Type:          auction.Type,
-		Status:        auction.Status,
-		StartTime:     auction.StartTime,
-		EndTime:       auction.EndTime,
-		CreatedAt:     auction.CreatedAt,
-		ImagePath:     auction.ImagePath,
+		ID:              auction.ID,
+		SellerID:        auction.SellerID,
+		Title:           auction.Title,
+		Description:     auction.Description,
+		StartingPrice:   auction.StartingPrice,
+		CurrentPrice:    auction.CurrentPrice,
+		Type:            auction.Type,
+		Status:          auction.Status,
+		StartTime:       auction.StartTime,
+		EndTime:         auction.EndTime,
+		CreatedAt:       auction.CreatedAt,
+		ImagePath:       auction.ImagePath,
+		WatcherCount:    auction.WatcherCount,
+		HoldThreshold:   auction.HoldThreshold,
+		WeightKg:        auction.WeightKg,
+		ShippingOptions: auction.ShippingOptions,
 	}
 
 	c.JSON(http.StatusOK, res)
@@ -320,16 +340,42 @@ func (a *AuctionHandler) GetAuctionById(c *gin.Context) {
 //	@Tags			Auctions
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		models.CreateAuctionResponse	"List of auctions"
-//	@Failure		401	{object}	gin.H							"Unauthorized - user not authenticated"
-//	@Failure		500	{object}	gin.H							"Internal Server Error - failed to retrieve auctions"
+//	@Param			limit			query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor			query		string							false	"Cursor from the previous page's next_cursor"
+//	@Param			offset			query		int								false	"Deprecated: page offset, ignored when cursor is set"	default(0)
+//	@Param			type			query		string							false	"Auction type"
+//	@Param			category		query		string							false	"Auction category"
+//	@Param			status			query		string							false	"Auction status"
+//	@Param			starting_price	query		number							false	"Exact starting price"
+//	@Param			min_price		query		number							false	"Minimum current price"
+//	@Param			max_price		query		number							false	"Maximum current price"
+//	@Param			keyword			query		string							false	"Keyword contained in the title or description"
+//	@Param			q				query		string							false	"Full-text search over title and description; results are ranked and include highlighted snippets"
+//	@Param			seller_id		query		string							false	"Only auctions listed by this seller"
+//	@Param			ending_within	query		int								false	"Only open auctions ending within this many hours"
+//	@Param			has_bids		query		bool							false	"Only auctions with (true) or without (false) bids"
+//	@Param			is_paid			query		bool							false	"Only paid (true) or unpaid (false) auctions"
+//	@Param			sort			query		string							false	"Sort order"	Enums(ending_soon, newest, price_asc, price_desc, most_bids)
+//	@Success		200				{object}	pagination.Page[models.CreateAuctionResponse]	"Page of auctions"
+//	@Failure		400				{object}	gin.H							"Bad Request - invalid filter, sort key, limit or cursor"
+//	@Failure		401				{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		500				{object}	gin.H							"Internal Server Error - failed to retrieve auctions"
 //	@Router			/auctions [get]
 //
 //	@Security		jwtCookieAuth
 func (a *AuctionHandler) GetAuctions(c *gin.Context) {
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	// offset paging is kept for older clients that have no cursor yet
+	if offset, _ := strconv.Atoi(c.Query("offset")); page.Cursor == nil && offset > 0 {
+		page.Cursor = &pagination.Cursor{Offset: offset}
+	}
+
 	filter := &models.AuctionFilter{
 		Type:     c.Query("type"),
 		Category: c.Query("category"),
@@ -338,6 +384,26 @@ func (a *AuctionHandler) GetAuctions(c *gin.Context) {
 			p, _ := strconv.ParseFloat(c.Query("starting_price"), 64)
 			return p
 		}(),
+		MinPrice: func() float64 {
+			p, _ := strconv.ParseFloat(c.Query("min_price"), 64)
+			return p
+		}(),
+		MaxPrice: func() float64 {
+			p, _ := strconv.ParseFloat(c.Query("max_price"), 64)
+			return p
+		}(),
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+		Query:   strings.TrimSpace(c.Query("q")),
+	}
+
+	if len(filter.Query) > maxSearchQueryLength {
+		errs.MapServiceErrors(c, errs.ErrSearchQueryTooLong)
+		return
+	}
+
+	if err := parseListingFilters(c, filter); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
 	}
 
 	authUser, err := contexts.GetUserFromContext(c)
@@ -346,33 +412,13 @@ func (a *AuctionHandler) GetAuctions(c *gin.Context) {
 		return
 	}
 
-	auctions, err := a.service.GetAuctions(c.Request.Context(), limit, offset, filter)
+	auctions, err := a.service.GetAuctions(c.Request.Context(), page, filter)
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	res := &[]models.CreateAuctionResponse{}
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			Category:      auction.Category,
-			IsPaid:        auction.IsPaid,
-		})
-	}
-
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, auctions)
 }
 
 // GetMyWonAuctions godoc
@@ -382,7 +428,10 @@ func (a *AuctionHandler) GetAuctions(c *gin.Context) {
 //	@Tags			Auctions
 //	@Accept			json
 //	@Produce		json
-//	@Success		200			{array}		models.CreateAuctionResponse	"List of auctions"
+//	@Param			limit		query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor		query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200			{object}	pagination.Page[models.CreateAuctionResponse]	"Page of auctions"
+//	@Failure		400			{object}	gin.H							"Bad Request - invalid limit or cursor"
 //	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
 //	@Failure		500			{object}	gin.H							"Internal Server Error - failed to retrieve auctions"
 //	@Router			/auctions/won [get]
@@ -395,33 +444,19 @@ func (a *AuctionHandler) GetMyWonAuctions(c *gin.Context) {
 		return
 	}
 
-	auctions, err := a.service.GetWonAuctionsByWinnerID(c.Request.Context(), authUser.ID)
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load won auctions"})
+		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	res := &[]models.CreateAuctionResponse{}
-
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			IsPaid:        auction.IsPaid,
-		})
+	auctions, err := a.service.GetWonAuctionsByWinnerID(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load won auctions"})
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, auctions)
 }
 
 // GetBiddedAuctions godoc
@@ -431,7 +466,10 @@ func (a *AuctionHandler) GetMyWonAuctions(c *gin.Context) {
 //	@Tags			Auctions
 //	@Accept			json
 //	@Produce		json
-//	@Success		200			{array}		models.CreateAuctionResponse	"List of auctions"
+//	@Param			limit		query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor		query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200			{object}	pagination.Page[models.CreateAuctionResponse]	"Page of auctions"
+//	@Failure		400			{object}	gin.H							"Bad Request - invalid limit or cursor"
 //	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
 //	@Failure		500			{object}	gin.H							"Internal Server Error - failed to retrieve auctions"
 //	@Router			/auctions/bidded [get]
@@ -444,68 +482,78 @@ func (a *AuctionHandler) GetBiddedAuctions(c *gin.Context) {
 		return
 	}
 
-	auctions, err := a.service.GetBiddedAuctionsForUser(c.Request.Context(), authUser.ID)
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bidded auctions"})
+		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	res := &[]models.CreateAuctionResponse{}
-
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			IsPaid:        auction.IsPaid,
-		})
+	auctions, err := a.service.GetBiddedAuctionsForUser(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bidded auctions"})
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, auctions)
 }
 
 func (a *AuctionHandler) GetAuctionsBySellerID(c *gin.Context) {
-
 	authUser, err := contexts.GetUserFromContext(c)
 	if authUser == nil || err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	auctions, err := a.service.GetAuctionsBySellerID(c.Request.Context(), authUser.ID)
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	auctions, err := a.service.GetAuctionsBySellerID(c.Request.Context(), authUser.ID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auctions"})
 		return
 	}
 
-	res := &[]models.CreateAuctionResponse{}
+	c.JSON(http.StatusOK, auctions)
+}
+
+// parseListingFilters reads the optional listing filters of GET /auctions and
+// rejects malformed values instead of silently ignoring them
+func parseListingFilters(c *gin.Context, filter *models.AuctionFilter) error {
 
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			IsPaid:        auction.IsPaid,
-		})
+	if sellerID := c.Query("seller_id"); sellerID != "" {
+		if _, err := uuid.Parse(sellerID); err != nil {
+			return errs.ErrInvalidAuctionFilter
+		}
+		filter.SellerID = sellerID
 	}
 
-	c.JSON(http.StatusOK, res)
+	if hours := c.Query("ending_within"); hours != "" {
+		h, err := strconv.Atoi(hours)
+		if err != nil || h <= 0 {
+			return errs.ErrInvalidAuctionFilter
+		}
+		filter.EndingWithinHours = h
+	}
+
+	for key, dest := range map[string]**bool{"has_bids": &filter.HasBids, "is_paid": &filter.IsPaid} {
+		raw := c.Query(key)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errs.ErrInvalidAuctionFilter
+		}
+		*dest = &v
+	}
+
+	filter.Sort = strings.ToLower(strings.TrimSpace(c.Query("sort")))
+	if !models.IsValidAuctionSort(filter.Sort) {
+		return errs.ErrInvalidSortKey
+	}
+
+	return nil
 }
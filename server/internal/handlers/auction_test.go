@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuctionsRejectsUnknownSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the sort key is checked before the service is called
+	handler := NewAuctionHandler(nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auctions?sort=bogus", nil)
+
+	handler.GetAuctions(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var res errs.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, errs.ErrInvalidSortKey.PublicMessage(), res.Message)
+}
@@ -25,6 +25,7 @@ type PlaceBidRequest struct {
 //	@Success		200			{object}	models.BidResponse	"Bid placed successfully"
 //	@Failure		400			{object}	gin.H				"Bad Request - invalid input"
 //	@Failure		401			{object}	gin.H				"Unauthorized - user not authenticated or authorized"
+//	@Failure		402			{object}	gin.H				"Payment Required - the bid needs an authorized card hold on this auction"
 //	@Failure		404			{object}	gin.H				"NotFound - auction not found"
 //	@Failure		500			{object}	gin.H				"Internal Server Error - failed to place bid"
 //	@Router			/auctions/{auctionID}/bids [post]
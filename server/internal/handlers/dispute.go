@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/imagesuploader"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type DisputeHandler struct {
+	service      services.DisputeServiceInterface
+	imageService imagesuploader.ImageServiceInterface
+}
+
+func NewDisputeHandler(service services.DisputeServiceInterface, imageService imagesuploader.ImageServiceInterface) *DisputeHandler {
+	return &DisputeHandler{
+		service:      service,
+		imageService: imageService,
+	}
+}
+
+// OpenDispute godoc
+//
+//	@Summary		Dispute an order
+//	@Description	Lets the buyer open a dispute on an order that isn't completed. The order is marked disputed, the seller's funds stay held and the seller has until the deadline to refund, ask for a return or escalate to an admin.
+//	@Tags			Disputes
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderID	path		string						true	"Order ID"
+//	@Param			payload	body		models.OpenDisputeRequest	true	"Reason and description"
+//	@Success		201		{object}	models.Dispute				"Dispute"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H						"Forbidden - not the buyer"
+//	@Failure		404		{object}	gin.H						"Not Found - order not found"
+//	@Failure		409		{object}	gin.H						"Conflict - order is completed or already disputed"
+//	@Router			/orders/{orderID}/dispute [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) OpenDispute(c *gin.Context) {
+
+	var payload models.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.OpenDispute(c.Request.Context(), c.Param("orderID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dispute)
+}
+
+// GetDisputes godoc
+//
+//	@Summary		Get my disputes
+//	@Description	Retrieves the disputes the authenticated user opened as buyer or received as seller, newest first
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			limit	query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.Dispute]	"Page of disputes"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve disputes"
+//	@Router			/disputes [get]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) GetDisputes(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	disputes, err := d.service.GetDisputes(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disputes)
+}
+
+// GetDispute godoc
+//
+//	@Summary		Get a dispute
+//	@Description	Returns a dispute to its buyer, its seller or an admin
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string			true	"Dispute ID"
+//	@Success		200			{object}	models.Dispute	"Dispute"
+//	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H			"Not Found - dispute not found"
+//	@Router			/disputes/{disputeID} [get]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) GetDispute(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.GetDispute(c.Request.Context(), c.Param("disputeID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// GetMessages godoc
+//
+//	@Summary		Get a dispute's thread
+//	@Description	Returns the messages, evidence and status changes of a dispute, oldest first
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string									true	"Dispute ID"
+//	@Param			limit		query		int										false	"Page size, capped at 100"	default(10)
+//	@Param			cursor		query		string									false	"Cursor from the previous page's next_cursor"
+//	@Success		200			{object}	pagination.Page[models.DisputeMessage]	"Page of messages"
+//	@Failure		400			{object}	gin.H									"Bad Request - invalid limit or cursor"
+//	@Failure		401			{object}	gin.H									"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H									"Not Found - dispute not found"
+//	@Router			/disputes/{disputeID}/messages [get]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) GetMessages(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	messages, err := d.service.GetMessages(c.Request.Context(), c.Param("disputeID"), authUser, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// PostMessage godoc
+//
+//	@Summary		Message a dispute
+//	@Description	Adds a message from the buyer, the seller or an admin to the thread of an unresolved dispute. The other party is notified.
+//	@Tags			Disputes
+//	@Accept			json
+//	@Produce		json
+//	@Param			disputeID	path		string							true	"Dispute ID"
+//	@Param			payload		body		models.DisputeMessageRequest	true	"Message"
+//	@Success		201			{object}	models.DisputeMessage			"Message"
+//	@Failure		400			{object}	gin.H							"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H							"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H							"Conflict - dispute is resolved"
+//	@Router			/disputes/{disputeID}/messages [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) PostMessage(c *gin.Context) {
+
+	var payload models.DisputeMessageRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	message, err := d.service.PostMessage(c.Request.Context(), c.Param("disputeID"), authUser, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// AddEvidence godoc
+//
+//	@Summary		Add evidence to a dispute
+//	@Description	Uploads a JPEG or PNG image of at most 3 MB and adds it to the thread of an unresolved dispute
+//	@Tags			Disputes
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			disputeID	path		string					true	"Dispute ID"
+//	@Param			image		formData	file					true	"the image to upload"
+//	@Success		201			{object}	models.DisputeMessage	"Message carrying the image"
+//	@Failure		400			{object}	gin.H					"Bad Request - no file uploaded, or not a JPEG or PNG image"
+//	@Failure		401			{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H					"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H					"Conflict - dispute is resolved"
+//	@Router			/disputes/{disputeID}/evidence [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) AddEvidence(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// check access before storing anything
+	if _, err := d.service.GetDispute(c.Request.Context(), c.Param("disputeID"), authUser); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			errs.MapServiceErrors(c, errs.NewHTTPError("no file uploaded", http.StatusBadRequest))
+		} else {
+			log.Printf("failed to parse form file: %v", err)
+			errs.MapServiceErrors(c, errs.NewHTTPError("failed to process file upload", http.StatusInternalServerError))
+		}
+
+		return
+	}
+
+	imagePath, err := d.imageService.UploadImage(c.Request.Context(), file)
+	if err != nil {
+		log.Printf("image service failed to upload dispute evidence: %v", err)
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	message, err := d.service.AddEvidence(c.Request.Context(), c.Param("disputeID"), authUser, imagePath)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// AcceptDispute godoc
+//
+//	@Summary		Accept a dispute
+//	@Description	Lets the seller refund the buyer in full without asking for the item back. The order is cancelled and the buyer is notified.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string			true	"Dispute ID"
+//	@Success		200			{object}	models.Dispute	"Dispute"
+//	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H			"Forbidden - not the seller"
+//	@Failure		404			{object}	gin.H			"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H			"Conflict - dispute is resolved or payment not refundable"
+//	@Failure		502			{object}	gin.H			"Bad Gateway - payment provider refused the refund"
+//	@Router			/disputes/{disputeID}/accept [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) AcceptDispute(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.AcceptDispute(c.Request.Context(), c.Param("disputeID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// RequestReturn godoc
+//
+//	@Summary		Ask for a return
+//	@Description	Lets the seller of an open dispute ask for the item back before refunding. The buyer is notified and has until the deadline to ship it.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string			true	"Dispute ID"
+//	@Success		200			{object}	models.Dispute	"Dispute"
+//	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H			"Forbidden - not the seller"
+//	@Failure		404			{object}	gin.H			"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H			"Conflict - dispute is not open"
+//	@Router			/disputes/{disputeID}/request-return [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) RequestReturn(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.RequestReturn(c.Request.Context(), c.Param("disputeID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ShipReturn godoc
+//
+//	@Summary		Ship a return
+//	@Description	Lets the buyer record the carrier and tracking number of a returned item. The seller is notified and has until the deadline to confirm it arrived.
+//	@Tags			Disputes
+//	@Accept			json
+//	@Produce		json
+//	@Param			disputeID	path		string						true	"Dispute ID"
+//	@Param			payload		body		models.ReturnShipmentRequest	true	"Return shipment details"
+//	@Success		200			{object}	models.Dispute				"Dispute"
+//	@Failure		400			{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H						"Forbidden - not the buyer"
+//	@Failure		404			{object}	gin.H						"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H						"Conflict - no return is awaited"
+//	@Router			/disputes/{disputeID}/return [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) ShipReturn(c *gin.Context) {
+
+	var payload models.ReturnShipmentRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.ShipReturn(c.Request.Context(), c.Param("disputeID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ConfirmReturn godoc
+//
+//	@Summary		Confirm a return arrived
+//	@Description	Lets the seller confirm the returned item arrived. The buyer is refunded in full and the order is cancelled.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string			true	"Dispute ID"
+//	@Success		200			{object}	models.Dispute	"Dispute"
+//	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H			"Forbidden - not the seller"
+//	@Failure		404			{object}	gin.H			"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H			"Conflict - the return has not shipped"
+//	@Failure		502			{object}	gin.H			"Bad Gateway - payment provider refused the refund"
+//	@Router			/disputes/{disputeID}/return-received [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) ConfirmReturn(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.ConfirmReturn(c.Request.Context(), c.Param("disputeID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// Escalate godoc
+//
+//	@Summary		Escalate a dispute
+//	@Description	Lets the buyer or the seller ask an admin to arbitrate. Disputes are also escalated when a deadline passes.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		string			true	"Dispute ID"
+//	@Success		200			{object}	models.Dispute	"Dispute"
+//	@Failure		401			{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H			"Forbidden - not the buyer or the seller"
+//	@Failure		404			{object}	gin.H			"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H			"Conflict - dispute is resolved or already under review"
+//	@Router			/disputes/{disputeID}/escalate [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) Escalate(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.Escalate(c.Request.Context(), c.Param("disputeID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// AdminGetDisputes godoc
+//
+//	@Summary		List disputes
+//	@Description	Lists disputes, newest first. Defaults to disputes under review; pass status=all for every dispute.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			status	query		string							false	"Dispute status"	Enums(open, under_review, awaiting_return, return_shipped, refunded, rejected, all)	default(under_review)
+//	@Param			limit	query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.Dispute]	"Page of disputes"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid status, limit or cursor"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H							"Forbidden - admin only"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve disputes"
+//	@Router			/admin/disputes [get]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) AdminGetDisputes(c *gin.Context) {
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	status := c.DefaultQuery("status", models.DisputeUnderReview)
+	if status == "all" {
+		status = ""
+	}
+
+	disputes, err := d.service.AdminGetDisputes(c.Request.Context(), status, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disputes)
+}
+
+// AdminResolveDispute godoc
+//
+//	@Summary		Resolve a dispute
+//	@Description	An admin's ruling on an unresolved dispute: refund the buyer in full or in part, have the item returned for a full refund, or reject the dispute. A partial refund or a rejection completes the order and releases the seller's funds. Both parties are notified.
+//	@Tags			Disputes
+//	@Accept			json
+//	@Produce		json
+//	@Param			disputeID	path		string						true	"Dispute ID"
+//	@Param			payload		body		models.ResolveDisputeRequest	true	"Ruling"
+//	@Success		200			{object}	models.Dispute				"Dispute"
+//	@Failure		400			{object}	gin.H						"Bad Request - invalid input or refund exceeds the payment"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H						"Forbidden - admin only"
+//	@Failure		404			{object}	gin.H						"Not Found - dispute not found"
+//	@Failure		409			{object}	gin.H						"Conflict - dispute is resolved"
+//	@Failure		502			{object}	gin.H						"Bad Gateway - payment provider refused the refund"
+//	@Router			/admin/disputes/{disputeID}/resolve [post]
+//
+//	@Security		jwtCookieAuth
+func (d *DisputeHandler) AdminResolveDispute(c *gin.Context) {
+
+	var payload models.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dispute, err := d.service.ResolveDispute(c.Request.Context(), c.Param("disputeID"), authUser, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
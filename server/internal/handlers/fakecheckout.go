@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/internal/payments"
+)
+
+// FakeCheckoutHandler serves the hosted checkout pages of the fake payment
+// provider. It is only routed when PAYMENT_PROVIDER=fake.
+type FakeCheckoutHandler struct {
+	provider *payments.FakePayment
+}
+
+func NewFakeCheckoutHandler(provider *payments.FakePayment) *FakeCheckoutHandler {
+	return &FakeCheckoutHandler{
+		provider: provider,
+	}
+}
+
+var fakeCheckoutPage = template.Must(template.New("checkout").Funcs(template.FuncMap{
+	"money": func(cents int64) float64 { return payments.FromCents(cents) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Fake checkout</title></head>
+<body>
+<h1>Fake checkout</h1>
+<p>{{with index .Session.Metadata "hold_id"}}Card hold{{else}}Order {{index .Session.Metadata "order_id"}}{{end}} &middot; status: {{.Session.Status}}</p>
+<table>
+{{range .Items}}<tr><td>{{.Name}}</td><td>{{printf "%.2f" (money .Amount)}}</td></tr>
+{{end}}<tr><th>Total</th><th>{{printf "%.2f" (money .Session.AmountTotal)}} {{.Session.Currency}}</th></tr>
+</table>
+{{if eq .Session.Status "open"}}
+<form method="post" action="{{.Session.ID}}/pay"><button type="submit">Pay</button></form>
+<form method="post" action="{{.Session.ID}}/decline"><button type="submit">Decline card</button></form>
+<a href="{{.CancelURL}}">Cancel</a>
+{{end}}
+</body>
+</html>`))
+
+// CheckoutPage godoc
+//
+//	@Summary		Fake checkout page
+//	@Description	Renders the hosted checkout page of the fake payment provider (PAYMENT_PROVIDER=fake only)
+//	@Tags			Payments
+//	@Produce		html
+//	@Param			sessionID	path	string	true	"Checkout session ID"
+//	@Success		200			{string}	string	"checkout page"
+//	@Failure		404			{object}	gin.H	"Not Found - session not found"
+//	@Router			/fake-checkout/{sessionID} [get]
+func (f *FakeCheckoutHandler) CheckoutPage(c *gin.Context) {
+
+	session, items, cancelURL, err := f.provider.CheckoutPage(c.Param("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	if err := fakeCheckoutPage.Execute(c.Writer, gin.H{
+		"Session":   session,
+		"Items":     items,
+		"CancelURL": cancelURL,
+	}); err != nil {
+		log.Printf("failed to render fake checkout page: %v", err)
+	}
+}
+
+// Pay godoc
+//
+//	@Summary		Pay a fake checkout session
+//	@Description	Completes a fake checkout session, delivers the signed payment webhooks and redirects to the success URL
+//	@Tags			Payments
+//	@Param			sessionID	path	string	true	"Checkout session ID"
+//	@Success		303
+//	@Failure		404	{object}	gin.H	"Not Found - session not found"
+//	@Failure		409	{object}	gin.H	"Conflict - session is not open"
+//	@Failure		502	{object}	gin.H	"Bad Gateway - webhook delivery failed"
+//	@Router			/fake-checkout/{sessionID}/pay [post]
+func (f *FakeCheckoutHandler) Pay(c *gin.Context) {
+
+	redirect, err := f.provider.Pay(c.Request.Context(), c.Param("sessionID"))
+	if err != nil {
+		fakeCheckoutError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, redirect)
+}
+
+// Decline godoc
+//
+//	@Summary		Decline a fake checkout session
+//	@Description	Simulates a declined card, delivers a signed payment_intent.payment_failed webhook and redirects to the cancel URL
+//	@Tags			Payments
+//	@Param			sessionID	path	string	true	"Checkout session ID"
+//	@Success		303
+//	@Failure		404	{object}	gin.H	"Not Found - session not found"
+//	@Failure		409	{object}	gin.H	"Conflict - session is not open"
+//	@Failure		502	{object}	gin.H	"Bad Gateway - webhook delivery failed"
+//	@Router			/fake-checkout/{sessionID}/decline [post]
+func (f *FakeCheckoutHandler) Decline(c *gin.Context) {
+
+	redirect, err := f.provider.Decline(c.Request.Context(), c.Param("sessionID"))
+	if err != nil {
+		fakeCheckoutError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, redirect)
+}
+
+func fakeCheckoutError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, payments.ErrFakeSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, payments.ErrFakeSessionNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("fake checkout failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	}
+}
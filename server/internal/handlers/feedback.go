@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type FeedbackHandler struct {
+	service services.FeedbackServiceInterface
+}
+
+func NewFeedbackHandler(service services.FeedbackServiceInterface) *FeedbackHandler {
+	return &FeedbackHandler{
+		service: service,
+	}
+}
+
+// LeaveFeedback godoc
+//
+//	@Summary		Leave feedback
+//	@Description	Lets the buyer rate the seller of a completed order, or the seller rate the buyer. Each side rates once; cancelled and unfinished orders can't be rated.
+//	@Tags			Feedback
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderID	path		string					true	"Order ID"
+//	@Param			payload	body		models.FeedbackRequest	true	"Rating and comment"
+//	@Success		201		{object}	models.Feedback			"Feedback"
+//	@Failure		400		{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H					"Forbidden - order is not completed"
+//	@Failure		404		{object}	gin.H					"Not Found - order not found"
+//	@Failure		409		{object}	gin.H					"Conflict - feedback already left"
+//	@Router			/orders/{orderID}/feedback [post]
+//
+//	@Security		jwtCookieAuth
+func (f *FeedbackHandler) LeaveFeedback(c *gin.Context) {
+
+	var payload models.FeedbackRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	feedback, err := f.service.LeaveFeedback(c.Request.Context(), c.Param("orderID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// UpdateFeedback godoc
+//
+//	@Summary		Edit feedback
+//	@Description	Lets the author change the rating and comment of a feedback within FEEDBACK_EDIT_WINDOW of leaving it
+//	@Tags			Feedback
+//	@Accept			json
+//	@Produce		json
+//	@Param			feedbackID	path		string					true	"Feedback ID"
+//	@Param			payload		body		models.FeedbackRequest	true	"Rating and comment"
+//	@Success		200			{object}	models.Feedback			"Feedback"
+//	@Failure		400			{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H					"Forbidden - not the author"
+//	@Failure		404			{object}	gin.H					"Not Found - feedback not found"
+//	@Failure		409			{object}	gin.H					"Conflict - edit window closed"
+//	@Router			/feedback/{feedbackID} [put]
+//
+//	@Security		jwtCookieAuth
+func (f *FeedbackHandler) UpdateFeedback(c *gin.Context) {
+
+	var payload models.FeedbackRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	feedback, err := f.service.UpdateFeedback(c.Request.Context(), c.Param("feedbackID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// ReplyToFeedback godoc
+//
+//	@Summary		Reply to feedback
+//	@Description	Lets a seller post one public reply to a rating they received as the seller
+//	@Tags			Feedback
+//	@Accept			json
+//	@Produce		json
+//	@Param			feedbackID	path		string						true	"Feedback ID"
+//	@Param			payload		body		models.FeedbackReplyRequest	true	"Reply"
+//	@Success		200			{object}	models.Feedback				"Feedback"
+//	@Failure		400			{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H						"Forbidden - not the rated seller"
+//	@Failure		404			{object}	gin.H						"Not Found - feedback not found"
+//	@Failure		409			{object}	gin.H						"Conflict - already replied"
+//	@Router			/feedback/{feedbackID}/reply [post]
+//
+//	@Security		jwtCookieAuth
+func (f *FeedbackHandler) ReplyToFeedback(c *gin.Context) {
+
+	var payload models.FeedbackReplyRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	feedback, err := f.service.ReplyToFeedback(c.Request.Context(), c.Param("feedbackID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetUserFeedback godoc
+//
+//	@Summary		Get a user's feedback
+//	@Description	Lists the feedback a user received as a seller or as a buyer, newest first. The user's totals are part of their profile.
+//	@Tags			Feedback
+//	@Produce		json
+//	@Param			username	path		string								true	"Username"
+//	@Param			role		query		string								false	"Role the user was rated in"	Enums(seller, buyer)	default(seller)
+//	@Param			limit		query		int									false	"Page size, capped at 100"	default(10)
+//	@Param			cursor		query		string								false	"Cursor from the previous page's next_cursor"
+//	@Success		200			{object}	pagination.Page[models.Feedback]	"Page of feedback"
+//	@Failure		400			{object}	gin.H								"Bad Request - invalid role, limit or cursor"
+//	@Failure		401			{object}	gin.H								"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H								"Not Found - user not found"
+//	@Failure		500			{object}	gin.H								"Internal Server Error - failed to retrieve feedback"
+//	@Router			/{username}/feedback [get]
+//
+//	@Security		jwtCookieAuth
+func (f *FeedbackHandler) GetUserFeedback(c *gin.Context) {
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	feedback, err := f.service.GetUserFeedback(c.Request.Context(), c.Param("username"), c.DefaultQuery("role", models.FeedbackRoleSeller), page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
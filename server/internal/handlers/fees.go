@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type FeeHandler struct {
+	service services.FeeServiceInterface
+}
+
+func NewFeeHandler(service services.FeeServiceInterface) *FeeHandler {
+	return &FeeHandler{
+		service: service,
+	}
+}
+
+// PreviewFees godoc
+//
+//	@Summary		Preview the fees of a sale
+//	@Description	Shows what an auction in the category closing at the given price would cost the buyer (buyer's premium, before tax) and what the authenticated seller would net after the tiered seller fee
+//	@Tags			Payouts
+//	@Produce		json
+//	@Param			price		query		number				true	"Final price"
+//	@Param			category	query		string				true	"Auction category"	Enums(mobile, pc, accessories)
+//	@Success		200			{object}	models.FeePreview	"Fee preview"
+//	@Failure		400			{object}	gin.H				"Bad Request - invalid price or category"
+//	@Failure		401			{object}	gin.H				"Unauthorized - user not authenticated"
+//	@Router			/fees/preview [get]
+//
+//	@Security		jwtCookieAuth
+func (f *FeeHandler) PreviewFees(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.FeePreviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, f.service.PreviewFees(authUser.ID, &req))
+}
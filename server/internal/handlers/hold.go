@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type HoldHandler struct {
+	service services.HoldServiceInterface
+}
+
+func NewHoldHandler(service services.HoldServiceInterface) *HoldHandler {
+	return &HoldHandler{
+		service: service,
+	}
+}
+
+// CreateHold godoc
+//
+//	@Summary		Authorize a bid hold
+//	@Description	Starts a card pre-authorization on an auction with a hold threshold; bids at or above the threshold are rejected without one. Open authorize_url to authorize the card. Nothing is charged unless the bidder wins, in which case the hold is captured as a deposit deducted at checkout; other holds are released when the auction closes. An existing pending or valid hold is returned instead of a new one.
+//	@Tags			Auctions
+//	@Produce		json
+//	@Param			auctionID	path		string					true	"Auction ID"
+//	@Success		201			{object}	models.BidHoldResponse	"Bid hold"
+//	@Failure		400			{object}	gin.H					"Bad Request - auction needs no hold, is not open, or is the caller's own"
+//	@Failure		401			{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H					"Not Found - auction not found"
+//	@Failure		502			{object}	gin.H					"Bad Gateway - payment provider failed"
+//	@Router			/auctions/{auctionID}/hold [post]
+//
+//	@Security		jwtCookieAuth
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	existingAuction, err := contexts.GetAuctionFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auction not found"})
+		return
+	}
+
+	res, err := h.service.CreateHold(c.Request.Context(), existingAuction.ID, authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// GetHold godoc
+//
+//	@Summary		Get my bid hold
+//	@Description	Returns the caller's current hold on an auction with its status refreshed from the payment provider
+//	@Tags			Auctions
+//	@Produce		json
+//	@Param			auctionID	path		string					true	"Auction ID"
+//	@Success		200			{object}	models.BidHoldResponse	"Bid hold"
+//	@Failure		401			{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H					"Not Found - no hold on this auction"
+//	@Failure		500			{object}	gin.H					"Internal Server Error - failed to get hold"
+//	@Router			/auctions/{auctionID}/hold [get]
+//
+//	@Security		jwtCookieAuth
+func (h *HoldHandler) GetHold(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	res, err := h.service.GetHold(c.Request.Context(), c.Param("auctionID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type InvoiceHandler struct {
+	service services.InvoiceServiceInterface
+}
+
+func NewInvoiceHandler(service services.InvoiceServiceInterface) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+	}
+}
+
+// invoicePage is laid out to be printed or saved as PDF from the browser
+var invoicePage = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"upper": strings.ToUpper,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { padding: 0.4em; text-align: left; }
+td.amount, th.amount { text-align: right; }
+tfoot th { border-top: 1px solid #000; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Issued {{.IssuedAt.Format "2006-01-02"}} &middot; Order {{.OrderID}} &middot; Payment reference {{.PaymentReference}}</p>
+<table>
+<tr>
+<td><strong>Seller</strong><br>{{.SellerName}}<br>{{.SellerEmail}}</td>
+<td><strong>Buyer</strong><br>{{.BuyerName}}<br>{{.BuyerEmail}}</td>
+</tr>
+</table>
+<h2>{{.AuctionTitle}}</h2>
+<table>
+<thead><tr><th>Description</th><th class="amount">Amount ({{upper .Currency}})</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{printf "%.2f" .Amount}}</td></tr>
+{{end}}</tbody>
+<tfoot>
+<tr><td>Subtotal</td><td class="amount">{{printf "%.2f" .Subtotal}}</td></tr>
+<tr><td>Fees</td><td class="amount">{{printf "%.2f" .Fees}}</td></tr>
+{{if .Shipping}}<tr><td>Shipping</td><td class="amount">{{printf "%.2f" .Shipping}}</td></tr>
+{{end}}<tr><td>Tax</td><td class="amount">{{printf "%.2f" .Tax}}</td></tr>
+<tr><th>Total</th><th class="amount">{{printf "%.2f" .Total}}</th></tr>
+</tfoot>
+</table>
+</body>
+</html>`))
+
+// GetInvoice godoc
+//
+//	@Summary		Get the invoice of a payment
+//	@Description	Returns the numbered invoice of a completed payment, as JSON or as a printable HTML page (format=html) that can be saved as PDF. Only the buyer, the seller of the auction or an admin can get it.
+//	@Tags			Payments
+//	@Produce		json,html
+//	@Param			orderID	path		string			true	"Order ID"
+//	@Param			format	query		string			false	"Response format"	Enums(json, html)	default(json)
+//	@Success		200		{object}	models.Invoice	"Invoice"
+//	@Failure		400		{object}	gin.H			"Bad Request - invalid format"
+//	@Failure		401		{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		404		{object}	gin.H			"Not Found - no such invoice of the user"
+//	@Failure		409		{object}	gin.H			"Conflict - payment is not completed"
+//	@Failure		500		{object}	gin.H			"Internal Server Error - failed to issue invoice"
+//	@Router			/payments/{orderID}/invoice [get]
+//
+//	@Security		jwtCookieAuth
+func (i *InvoiceHandler) GetInvoice(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "html" {
+		errs.MapServiceErrors(c, errs.ErrInvalidInvoiceFormat)
+		return
+	}
+
+	invoice, err := i.service.GetInvoice(c.Request.Context(), c.Param("orderID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, invoice)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="`+invoice.Number+`.html"`)
+	c.Status(http.StatusOK)
+
+	if err := invoicePage.Execute(c.Writer, invoice); err != nil {
+		log.Printf("failed to render invoice %s: %v", invoice.Number, err)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type LedgerHandler struct {
+	service services.LedgerServiceInterface
+}
+
+func NewLedgerHandler(service services.LedgerServiceInterface) *LedgerHandler {
+	return &LedgerHandler{
+		service: service,
+	}
+}
+
+// GetBalance godoc
+//
+//	@Summary		Get my seller balance
+//	@Description	Returns what the authenticated seller is owed: funds held until the order completes (or, for payments without an order, until the hold period passes), funds available for payout and the total paid out so far
+//	@Tags			Payouts
+//	@Produce		json
+//	@Success		200	{object}	models.Balance
+//	@Failure		401	{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		500	{object}	gin.H	"Internal Server Error - failed to get balance"
+//	@Router			/me/balance [get]
+//
+//	@Security		jwtCookieAuth
+func (l *LedgerHandler) GetBalance(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	balance, err := l.service.GetBalance(c.Request.Context(), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetPayouts godoc
+//
+//	@Summary		Get my payout history
+//	@Description	Lists the authenticated seller's payouts, newest first
+//	@Tags			Payouts
+//	@Produce		json
+//	@Param			limit	query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.Payout]	"Page of payouts"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve payouts"
+//	@Router			/me/payouts [get]
+//
+//	@Security		jwtCookieAuth
+func (l *LedgerHandler) GetPayouts(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	payouts, err := l.service.GetPayouts(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payouts)
+}
+
+// RequestPayout godoc
+//
+//	@Summary		Pay out my available balance
+//	@Description	Pays out the authenticated seller's whole available balance
+//	@Tags			Payouts
+//	@Produce		json
+//	@Success		201	{object}	models.Payout	"Payout sent"
+//	@Failure		401	{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		409	{object}	gin.H			"Conflict - no available balance"
+//	@Failure		502	{object}	gin.H			"Bad Gateway - payout provider failed"
+//	@Router			/me/payouts [post]
+//
+//	@Security		jwtCookieAuth
+func (l *LedgerHandler) RequestPayout(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payout, err := l.service.RequestPayout(c.Request.Context(), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payout)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type OrderHandler struct {
+	service services.OrderServiceInterface
+}
+
+func NewOrderHandler(service services.OrderServiceInterface) *OrderHandler {
+	return &OrderHandler{
+		service: service,
+	}
+}
+
+// GetOrders godoc
+//
+//	@Summary		Get my orders
+//	@Description	Retrieves the orders the authenticated user bought or sold, newest first. An order is opened when an auction is paid.
+//	@Tags			Orders
+//	@Produce		json
+//	@Param			limit	query		int								false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string							false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.Order]	"Page of orders"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve orders"
+//	@Router			/orders [get]
+//
+//	@Security		jwtCookieAuth
+func (o *OrderHandler) GetOrders(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	orders, err := o.service.GetOrders(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetOrder godoc
+//
+//	@Summary		Get an order
+//	@Description	Returns an order to its buyer, its seller or an admin
+//	@Tags			Orders
+//	@Produce		json
+//	@Param			orderID	path		string			true	"Order ID"
+//	@Success		200		{object}	models.Order	"Order"
+//	@Failure		401		{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		404		{object}	gin.H			"Not Found - order not found"
+//	@Router			/orders/{orderID} [get]
+//
+//	@Security		jwtCookieAuth
+func (o *OrderHandler) GetOrder(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	order, err := o.service.GetOrder(c.Request.Context(), c.Param("orderID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ShipOrder godoc
+//
+//	@Summary		Mark an order shipped
+//	@Description	Lets the seller record the carrier and tracking number of an order awaiting shipment. The buyer is notified. Pickup orders are marked delivered instead.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderID	path		string					true	"Order ID"
+//	@Param			payload	body		models.ShipOrderRequest	true	"Shipment details"
+//	@Success		200		{object}	models.Order			"Order"
+//	@Failure		400		{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H					"Forbidden - not the seller"
+//	@Failure		404		{object}	gin.H					"Not Found - order not found"
+//	@Failure		409		{object}	gin.H					"Conflict - order is not awaiting shipment"
+//	@Router			/orders/{orderID}/ship [post]
+//
+//	@Security		jwtCookieAuth
+func (o *OrderHandler) ShipOrder(c *gin.Context) {
+
+	var payload models.ShipOrderRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	order, err := o.service.ShipOrder(c.Request.Context(), c.Param("orderID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// DeliverOrder godoc
+//
+//	@Summary		Mark an order delivered
+//	@Description	Records that a shipped order arrived, by the buyer or the seller. The seller also uses it to hand over a pickup order. The other party is notified.
+//	@Tags			Orders
+//	@Produce		json
+//	@Param			orderID	path		string			true	"Order ID"
+//	@Success		200		{object}	models.Order	"Order"
+//	@Failure		401		{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H			"Forbidden - not the buyer or the seller"
+//	@Failure		404		{object}	gin.H			"Not Found - order not found"
+//	@Failure		409		{object}	gin.H			"Conflict - order can't be delivered from its status"
+//	@Router			/orders/{orderID}/deliver [post]
+//
+//	@Security		jwtCookieAuth
+func (o *OrderHandler) DeliverOrder(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	order, err := o.service.DeliverOrder(c.Request.Context(), c.Param("orderID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CompleteOrder godoc
+//
+//	@Summary		Complete an order
+//	@Description	Lets the buyer confirm a shipped or delivered order arrived as described. The seller's funds are released and the seller is notified.
+//	@Tags			Orders
+//	@Produce		json
+//	@Param			orderID	path		string			true	"Order ID"
+//	@Success		200		{object}	models.Order	"Order"
+//	@Failure		401		{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H			"Forbidden - not the buyer"
+//	@Failure		404		{object}	gin.H			"Not Found - order not found"
+//	@Failure		409		{object}	gin.H			"Conflict - order is not shipped or delivered"
+//	@Router			/orders/{orderID}/complete [post]
+//
+//	@Security		jwtCookieAuth
+func (o *OrderHandler) CompleteOrder(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	order, err := o.service.CompleteOrder(c.Request.Context(), c.Param("orderID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type PasswordResetHandler struct {
+	service services.PasswordResetServiceInterface
+}
+
+func NewPasswordResetHandler(service services.PasswordResetServiceInterface) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		service: service,
+	}
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Forgot password
+//	@Description	Mails a password reset link to the address if an account uses it. The response is the same whether or not one does.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.ForgotPasswordRequest	true	"Account email"
+//	@Success		202		{object}	gin.H							"Reset link sent if the account exists"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid email"
+//	@Failure		429		{object}	gin.H							"Too Many Requests - rate limit exceeded"
+//	@Router			/forgot-password [post]
+func (p *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+
+	var payload models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := p.service.ForgotPassword(c.Request.Context(), payload.Email); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if an account uses this email, a link to reset its password is on its way"})
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	Sets a new password with the token from a reset link. A token works once, for PASSWORD_RESET_TTL. Resetting signs the user out of every session.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.ResetPasswordRequest	true	"Token and new password"
+//	@Success		200		{object}	gin.H						"Password reset"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid or expired token, or invalid password"
+//	@Failure		429		{object}	gin.H						"Too Many Requests - rate limit exceeded"
+//	@Failure		500		{object}	gin.H						"Internal Server Error - failed to reset password"
+//	@Router			/reset-password [post]
+func (p *PasswordResetHandler) ResetPassword(c *gin.Context) {
+
+	var payload models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := p.service.ResetPassword(c.Request.Context(), &payload); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset, log in with your new password"})
+}
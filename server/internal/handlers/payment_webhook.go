@@ -5,19 +5,14 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/puremike/online_auction_api/contexts"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/services"
 	"github.com/puremike/online_auction_api/internal/store"
-	"github.com/stripe/stripe-go/v82"
-	"github.com/stripe/stripe-go/v82/checkout/session"
-	"github.com/stripe/stripe-go/v82/webhook"
 )
 
 type WebHookHandler struct {
@@ -32,27 +27,29 @@ func NewWebHookHander(service services.PaymentServiceInterface, auctionRepo stor
 	}
 }
 
-// StripeWebHookHandler handles Stripe webhook events.
+// PaymentWebHookHandler handles webhook events from the configured payment provider.
 //
-// This handler reads and verifies incoming Stripe webhook requests, ensuring
-// the request body is correctly read and the Stripe signature is valid.
-// It processes different event types such as "checkout.session.completed",
-// "payment_intent.succeeded", and "payment_intent.payment_failed". Each event
-// type is handled by invoking appropriate service methods to update payment
-// statuses or handle session completions. If the event type is unrecognized,
-// it returns an error response indicating the event type is unhandled.
+// This handler reads the raw request body and hands it, together with the
+// request headers, to the payment service which verifies the provider's
+// signature and decodes the event. Every event is recorded in the webhook
+// event log and applied at most once: duplicate deliveries, unrecognized event
+// types and events that would make an illegal payment status transition are
+// acknowledged without changing anything. Processing failures return an error
+// so the provider retries them.
 //
-//	@Summary		Handle Stripe Webhook Events
-//	@Description	Processes Stripe webhook events for payment and checkout session updates.
+//	@Summary		Handle Payment Provider Webhook Events
+//	@Description	Processes signed webhook events from the configured payment provider (stripe or fake) for payment and checkout session updates.
 //	@Tags			Webhook
 //	@Accept			json
 //	@Produce		json
-//	@Param			Stripe-Signature	header		string	true	"Stripe Signature Header"
+//	@Param			provider			path		string	true	"Payment provider name"	Enums(stripe, fake)
+//	@Param			Stripe-Signature	header		string	false	"Stripe Signature Header"
+//	@Param			Fake-Signature		header		string	false	"Fake Provider Signature Header"
 //	@Success		200					{object}	gin.H	"success"
-//	@Failure		400					{object}	gin.H	"Bad Request - invalid input or unhandled event type"
+//	@Failure		400					{object}	gin.H	"Bad Request - invalid signature or payload"
 //	@Failure		500					{object}	gin.H	"Internal Server Error - failed to process event"
-//	@Router			/webhook/stripe [post]
-func (w *WebHookHandler) StripeWebHookHandler(c *gin.Context) {
+//	@Router			/webhook/{provider} [post]
+func (w *WebHookHandler) PaymentWebHookHandler(c *gin.Context) {
 
 	// read the body request
 	body, err := io.ReadAll(c.Request.Body)
@@ -62,109 +59,35 @@ func (w *WebHookHandler) StripeWebHookHandler(c *gin.Context) {
 		return
 	}
 
-	// verify the signature header
-	signatureHeader := c.GetHeader("Stripe-Signature")
-	if signatureHeader == "" {
-		log.Println("missing Stripe-Signature header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing Stripe-Signature header"})
-		return
-	}
-
-	// verify the webhook secret
-	stripeWebhookSecret, exists := os.LookupEnv("STRIPE_WEBHOOK_SECRET")
-	if !exists {
-		log.Println("missing STRIPE_WEBHOOK_SECRET environment variable")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing STRIPE_WEBHOOK_SECRET environment variable"})
-		return
-	}
-
-	event, err := webhook.ConstructEvent(body, signatureHeader, stripeWebhookSecret)
+	event, err := w.service.ParseWebhook(body, c.Request.Header)
 	if err != nil {
-		log.Printf("error verifying webhook signature: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "error verifying webhook signature"})
+		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	switch event.Type {
-	case "checkout.session.completed":
-		var session stripe.CheckoutSession
-
-		if err := w.service.HandleCheckoutSessionCompleted(c, &event, &session); err != nil {
-			log.Printf("failed to handle checkout.session.completed event: %v", err)
-			errs.MapServiceErrors(c, err)
-			return
-		}
-
-	case "payment_intent.succeeded":
-		var pi stripe.PaymentIntent
-
-		if err := w.service.HandlePaymentIntentSucceeded(c, &event, &pi); err != nil {
-			log.Printf("failed to handle payment_intent.succeeded event: %v", err)
-			errs.MapServiceErrors(c, err)
-			return
-		}
-
-	case "payment_intent.payment_failed":
-		var pi stripe.PaymentIntent
-
-		if err := w.service.HandlePaymentIntentFailed(c, &event, &pi); err != nil {
-			log.Printf("failed to handle payment_intent.payment_failed event: %v", err)
-			errs.MapServiceErrors(c, err)
-			return
-		}
-
-	default:
-		log.Printf("unhandled event type: %s", event.Type)
-		c.JSON(http.StatusOK, gin.H{"error": "unhandled event type"})
+	if err := w.service.ProcessWebhook(c.Request.Context(), event); err != nil {
+		log.Printf("failed to process %s event %s: %v", event.Type, event.ID, err)
+		errs.MapServiceErrors(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "success"})
 }
 
-// used to retrieve the payment session and return it to the frontend - successURL
-func (w *WebHookHandler) GetPaymentSession(c *gin.Context) {
-
-	// authUser, err := contexts.GetAuctionFromContext(c)
-	// if err != nil && authUser == nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-	// }
-
-	sessionID := c.Param("sessionID")
-
-	stripeSession, err := session.Get(sessionID, nil)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Stripe session"})
-		return
-	}
-
-	// if stripeSession.Metadata["buyer_id"] != authUser.ID {
-	// 	c.JSON(403, gin.H{"error": "Access denied"})
-	// 	return
-	// }
-
-	c.JSON(http.StatusOK, gin.H{
-		"id":             stripeSession.ID,
-		"payment_status": stripeSession.PaymentStatus,
-		"amount_total":   stripeSession.AmountTotal,
-		"currency":       stripeSession.Currency,
-		"customer_email": stripeSession.CustomerEmail,
-		"metadata":       stripeSession.Metadata,
-	})
-}
-
 // CreateCheckoutSessionHandler godoc
 //
 //	@Summary		Create Stripe Checkout Session for an auction
-//	@Description	Create a Stripe Checkout Session for an auction, using the current price of the auction and the authenticated user's ID.
+//	@Description	Create (or resume) a Stripe Checkout Session for a closed, unpaid auction won by the authenticated user. The amount is the auction's final price plus buyer fees and the chosen shipping, computed server-side.
 //	@Tags			Payments
 //	@Accept			json
 //	@Produce		json
 //	@Param			auctionID	path		string							true	"ID of the auction to create a checkout session for"
-//	@Success		201			{object}	models.CreatePaymentResponse	"Stripe Checkout Session created successfully"
-//	@Failure		400			{object}	gin.H							"Bad Request - invalid input"
+//	@Success		200			{object}	models.CreatePaymentResponse	"Stripe Checkout Session created or resumed"
+//	@Failure		400			{object}	gin.H							"Bad Request - auction is not closed"
 //	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H							"Forbidden - user did not win the auction"
 //	@Failure		404			{object}	gin.H							"Not Found - auction not found"
+//	@Failure		409			{object}	gin.H							"Conflict - auction already paid, or shipping not chosen yet"
 //	@Failure		500			{object}	gin.H							"Internal Server Error - failed to create Stripe Checkout Session"
 //	@Router			/auctions/{auctionID}/stripe/create-checkout-session [post]
 //
@@ -186,26 +109,17 @@ func (w *WebHookHandler) CreateCheckoutSessionHandler(c *gin.Context) {
 		return
 	}
 
-	if authUser.ID != auction.WinnerID {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "you're not allowed to proceed"})
-		return
-	}
-
-	orderID := uuid.New().String()
-
 	// Call the service layer to create the Stripe Checkout Session
-	stripeSession, err := w.service.CreatePaymentCheckout(c.Request.Context(), int64(auction.CurrentPrice), orderID, authUser.ID, auction.ID)
+	res, err := w.service.CreatePaymentCheckout(c.Request.Context(), auction.ID, authUser.ID)
 	if err != nil {
-		log.Printf("failed to create payment intent in service: %v", err)
+		log.Printf("failed to create checkout session in service: %v", err)
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, models.CreatePaymentResponse{
-		CheckoutURL: stripeSession.URL,
-	})
+	c.JSON(http.StatusOK, res)
 
-	log.Printf("successfully created Stripe Checkout Session for Order %s, Buyer %s. URL: %s", orderID, authUser.ID, stripeSession.URL)
+	log.Printf("checkout session ready for Order %s, Buyer %s. URL: %s", res.OrderID, authUser.ID, res.CheckoutURL)
 }
 
 // GetPayment godoc
@@ -244,16 +158,238 @@ func (w *WebHookHandler) GetPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, payment)
 }
 
-func (w *WebHookHandler) UpdateAuctionPayment(c *gin.Context) {
-	auctionID := c.Param("auctionID")
+// GetPaymentStatus godoc
+//
+//	@Summary		Get the status of a payment
+//	@Description	Returns the status of a payment and whether its auction is marked paid. Only the buyer, the seller of the auction or an admin can see it.
+//	@Tags			Payments
+//	@Produce		json
+//	@Param			orderID	path		string							true	"Order ID"
+//	@Success		200		{object}	models.PaymentStatusResponse	"Payment status"
+//	@Failure		401		{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		404		{object}	gin.H							"Not Found - no such payment of the user"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to retrieve payment"
+//	@Router			/payments/{orderID}/status [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) GetPaymentStatus(c *gin.Context) {
 
-	paymentStatus, _ := strconv.ParseBool(c.Query("status"))
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
 
-	if err := w.service.UpdateAuctionPayment(c.Request.Context(), paymentStatus, auctionID); err != nil {
-		log.Printf("failed to get payment: %v", err)
+	status, err := w.service.GetPaymentStatus(c.Request.Context(), c.Param("orderID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetPaymentStatusBySession godoc
+//
+//	@Summary		Get the status of a payment by checkout session
+//	@Description	Returns the status of the payment of a checkout session, for the page the payment provider redirects the buyer to. Only the buyer, the seller of the auction or an admin can see it.
+//	@Tags			Payments
+//	@Produce		json
+//	@Param			sessionID	path		string							true	"Checkout session ID"
+//	@Success		200			{object}	models.PaymentStatusResponse	"Payment status"
+//	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H							"Not Found - no such payment of the user"
+//	@Failure		500			{object}	gin.H							"Internal Server Error - failed to retrieve payment"
+//	@Router			/payments/session/{sessionID}/status [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) GetPaymentStatusBySession(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := w.service.GetPaymentStatusBySession(c.Request.Context(), c.Param("sessionID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// RefundPayment godoc
+//
+//	@Summary		Refund a payment
+//	@Description	Refunds a completed payment in full or in part, the deposit captured from a bid hold included. Omit amount to refund everything not yet refunded. The checkout charge is refunded before the deposit, each as its own refund. Only an admin or the seller of the auction can refund.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderID	path		string						true	"Order ID"
+//	@Param			payload	body		models.CreateRefundRequest	true	"Refund amount and reason"
+//	@Success		201		{array}		models.PaymentRefund		"Refunds issued"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid amount, missing reason or amount exceeds what is left to refund"
+//	@Failure		401		{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H						"Forbidden - not an admin or the seller"
+//	@Failure		404		{object}	gin.H						"Not Found - payment not found"
+//	@Failure		409		{object}	gin.H						"Conflict - payment is not completed"
+//	@Failure		502		{object}	gin.H						"Bad Gateway - payment provider refused the refund"
+//	@Router			/payments/{orderID}/refunds [post]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) RefundPayment(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload models.CreateRefundRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refunds, err := w.service.RefundPayment(c.Request.Context(), c.Param("orderID"), authUser, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refunds)
+}
+
+// GetRefunds godoc
+//
+//	@Summary		List the refunds of a payment
+//	@Description	Lists the refunds of a payment, newest first. Available to an admin, the seller and the buyer.
+//	@Tags			Payments
+//	@Produce		json
+//	@Param			orderID	path		string					true	"Order ID"
+//	@Success		200		{array}		models.PaymentRefund	"Refunds"
+//	@Failure		401		{object}	gin.H					"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H					"Forbidden - not an admin, the seller or the buyer"
+//	@Failure		404		{object}	gin.H					"Not Found - payment not found"
+//	@Failure		500		{object}	gin.H					"Internal Server Error - failed to retrieve refunds"
+//	@Router			/payments/{orderID}/refunds [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) GetRefunds(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	refunds, err := w.service.GetRefunds(c.Request.Context(), c.Param("orderID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+// AdminGetWebhookEvents godoc
+//
+//	@Summary		List webhook events
+//	@Description	Lists recorded payment provider webhook events, newest first. Defaults to failed events; pass status=all for every event.
+//	@Tags			Webhook
+//	@Produce		json
+//	@Param			status	query		string										false	"Event status"	Enums(failed, processing, processed, ignored, all)	default(failed)
+//	@Param			limit	query		int											false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string										false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.WebhookEvent]	"Page of webhook events"
+//	@Failure		400		{object}	gin.H										"Bad Request - invalid status, limit or cursor"
+//	@Failure		401		{object}	gin.H										"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H										"Forbidden - admin only"
+//	@Failure		500		{object}	gin.H										"Internal Server Error - failed to retrieve webhook events"
+//	@Router			/admin/webhook-events [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) AdminGetWebhookEvents(c *gin.Context) {
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	status := c.DefaultQuery("status", models.WebhookEventFailed)
+	if status == "all" {
+		status = ""
+	}
+
+	events, err := w.service.GetWebhookEvents(c.Request.Context(), status, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// AdminReplayWebhookEvent godoc
+//
+//	@Summary		Replay a failed webhook event
+//	@Description	Processes a failed webhook event again from its recorded payload and returns the event with the outcome
+//	@Tags			Webhook
+//	@Produce		json
+//	@Param			eventID	path		string				true	"Webhook event ID"
+//	@Success		200		{object}	models.WebhookEvent	"Replayed event"
+//	@Failure		401		{object}	gin.H				"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H				"Forbidden - admin only"
+//	@Failure		404		{object}	gin.H				"Not Found - webhook event not found"
+//	@Failure		409		{object}	gin.H				"Conflict - event is not in failed status"
+//	@Failure		500		{object}	gin.H				"Internal Server Error - failed to replay event"
+//	@Router			/admin/webhook-events/{eventID}/replay [post]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) AdminReplayWebhookEvent(c *gin.Context) {
+
+	event, err := w.service.ReplayWebhookEvent(c.Request.Context(), c.Param("eventID"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// AdminGetPaymentMismatches godoc
+//
+//	@Summary		List payment mismatches
+//	@Description	Lists the differences the reconciliation job found between local payments and the payment provider, and how each was resolved, newest first
+//	@Tags			Webhook
+//	@Produce		json
+//	@Param			action	query		string										false	"Only mismatches resolved by this action"	Enums(completed, failed, expired, is_paid_fixed, unresolved)
+//	@Param			limit	query		int											false	"Page size, capped at 100"					default(10)
+//	@Param			cursor	query		string										false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.PaymentMismatch]	"Page of payment mismatches"
+//	@Failure		400		{object}	gin.H										"Bad Request - invalid action, limit or cursor"
+//	@Failure		401		{object}	gin.H										"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H										"Forbidden - admin only"
+//	@Failure		500		{object}	gin.H										"Internal Server Error - failed to retrieve payment mismatches"
+//	@Router			/admin/payments/mismatches [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WebHookHandler) AdminGetPaymentMismatches(c *gin.Context) {
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	mismatches, err := w.service.GetPaymentMismatches(c.Request.Context(), c.Query("action"), page)
+	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, "updated")
+	c.JSON(http.StatusOK, mismatches)
 }
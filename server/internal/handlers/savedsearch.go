@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type SavedSearchHandler struct {
+	service services.SavedSearchServiceInterface
+}
+
+func NewSavedSearchHandler(service services.SavedSearchServiceInterface) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		service: service,
+	}
+}
+
+func savedSearchFromRequest(payload *models.SavedSearchRequest, userID string) *models.SavedSearch {
+	notify := true
+	if payload.NotifyNewListings != nil {
+		notify = *payload.NotifyNewListings
+	}
+
+	return &models.SavedSearch{
+		UserID: userID,
+		Name:   payload.Name,
+		Filter: models.AuctionFilter{
+			Type:     payload.Type,
+			Category: payload.Category,
+			Status:   payload.Status,
+			MinPrice: payload.MinPrice,
+			MaxPrice: payload.MaxPrice,
+			Keyword:  payload.Keyword,
+		},
+		NotifyNewListings: notify,
+	}
+}
+
+// CreateSavedSearch godoc
+//
+//	@Summary		Create Saved Search
+//	@Description	Saves an auction filter under a name. New auctions matching it trigger a notification unless alerts are turned off.
+//	@Tags			Saved Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.SavedSearchRequest	true	"Saved search payload"
+//	@Success		201		{object}	models.SavedSearch			"Created saved search"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H						"Internal Server Error - failed to save search"
+//	@Router			/saved-searches [post]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+
+	var payload models.SavedSearchRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := s.service.CreateSavedSearch(c.Request.Context(), savedSearchFromRequest(&payload, authUser.ID))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// GetSavedSearches godoc
+//
+//	@Summary		Get My Saved Searches
+//	@Description	Retrieves the saved searches of the authenticated user.
+//	@Tags			Saved Searches
+//	@Produce		json
+//	@Param			limit	query		int										false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string									false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.SavedSearch]	"Page of saved searches"
+//	@Failure		400		{object}	gin.H									"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H									"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H									"Internal Server Error - failed to retrieve saved searches"
+//	@Router			/saved-searches [get]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) GetSavedSearches(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	searches, err := s.service.GetSavedSearches(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// GetSavedSearch godoc
+//
+//	@Summary		Get Saved Search
+//	@Description	Retrieves one of the authenticated user's saved searches.
+//	@Tags			Saved Searches
+//	@Produce		json
+//	@Param			searchID	path		string				true	"ID of the saved search"
+//	@Success		200			{object}	models.SavedSearch	"Saved search"
+//	@Failure		401			{object}	gin.H				"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H				"NotFound - saved search not found"
+//	@Router			/saved-searches/{searchID} [get]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) GetSavedSearch(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := s.service.GetSavedSearch(c.Request.Context(), c.Param("searchID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// UpdateSavedSearch godoc
+//
+//	@Summary		Update Saved Search
+//	@Description	Replaces the name, filter and alert preference of a saved search.
+//	@Tags			Saved Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			searchID	path		string						true	"ID of the saved search"
+//	@Param			payload		body		models.SavedSearchRequest	true	"Saved search payload"
+//	@Success		200			{object}	models.SavedSearch			"Updated saved search"
+//	@Failure		400			{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H						"NotFound - saved search not found"
+//	@Router			/saved-searches/{searchID} [put]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+
+	var payload models.SavedSearchRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req := savedSearchFromRequest(&payload, authUser.ID)
+	req.ID = c.Param("searchID")
+
+	search, err := s.service.UpdateSavedSearch(c.Request.Context(), req)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// SetSavedSearchAlerts godoc
+//
+//	@Summary		Toggle Saved Search Alerts
+//	@Description	Opts in or out of new-listing alerts for a single saved search.
+//	@Tags			Saved Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			searchID	path		string							true	"ID of the saved search"
+//	@Param			payload		body		models.SavedSearchAlertsRequest	true	"Alert preference"
+//	@Success		200			{object}	models.SavedSearch				"Updated saved search"
+//	@Failure		400			{object}	gin.H							"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H							"NotFound - saved search not found"
+//	@Router			/saved-searches/{searchID}/alerts [put]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) SetSavedSearchAlerts(c *gin.Context) {
+
+	var payload models.SavedSearchAlertsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := s.service.SetSavedSearchAlerts(c.Request.Context(), c.Param("searchID"), authUser.ID, *payload.Enabled)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch godoc
+//
+//	@Summary		Delete Saved Search
+//	@Description	Deletes one of the authenticated user's saved searches.
+//	@Tags			Saved Searches
+//	@Produce		json
+//	@Param			searchID	path		string	true	"ID of the saved search"
+//	@Success		200			{object}	string	"saved search deleted successfully"
+//	@Failure		401			{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H	"NotFound - saved search not found"
+//	@Router			/saved-searches/{searchID} [delete]
+//
+//	@Security		jwtCookieAuth
+func (s *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	msg, err := s.service.DeleteSavedSearch(c.Request.Context(), c.Param("searchID"), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
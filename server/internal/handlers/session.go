@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type SessionHandler struct {
+	service services.SessionServiceInterface
+	app     *config.Application
+}
+
+func NewSessionHandler(service services.SessionServiceInterface, app *config.Application) *SessionHandler {
+	return &SessionHandler{
+		service: service,
+		app:     app,
+	}
+}
+
+// GetSessions godoc
+//
+//	@Summary		List my sessions
+//	@Description	Lists the devices the authenticated user is logged in on, most recently used first, with the user agent and IP address of each one's latest login or refresh. The session of the request's refresh token is marked current.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Success		200	{array}		models.Session	"Active sessions"
+//	@Failure		401	{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		500	{object}	gin.H			"Internal Server Error - failed to retrieve sessions"
+//	@Router			/me/sessions [get]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) GetSessions(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	refreshToken, _ := c.Cookie("refresh_token")
+
+	sessions, err := s.service.GetSessions(c.Request.Context(), authUser.ID, refreshToken)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+//
+//	@Summary		End one of my sessions
+//	@Description	Logs the authenticated user out of one device. Its refresh token stops working; an access token already issued to it lasts until it expires.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			sessionID	path		string	true	"Session ID"
+//	@Success		200			{object}	gin.H	"Session revoked"
+//	@Failure		401			{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H	"Not Found - session not found"
+//	@Failure		500			{object}	gin.H	"Internal Server Error - failed to revoke session"
+//	@Router			/me/sessions/{sessionID} [delete]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) RevokeSession(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.service.RevokeSession(c.Request.Context(), authUser.ID, c.Param("sessionID")); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeAllSessions godoc
+//
+//	@Summary		Log out everywhere
+//	@Description	Ends every session of the authenticated user, this one included, and clears its cookies
+//	@Tags			Sessions
+//	@Produce		json
+//	@Success		200	{object}	gin.H	"Number of sessions revoked"
+//	@Failure		401	{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		500	{object}	gin.H	"Internal Server Error - failed to revoke sessions"
+//	@Router			/me/sessions [delete]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) RevokeAllSessions(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	revoked, err := s.service.RevokeAllSessions(c.Request.Context(), authUser.ID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	clearAuthCookies(c, s.app)
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out everywhere", "revoked": revoked})
+}
+
+// AdminGetSessions godoc
+//
+//	@Summary		List a user's sessions
+//	@Description	Lists the active sessions of any user, for incident response
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			userID	path		string			true	"User ID"
+//	@Success		200		{array}		models.Session	"Active sessions"
+//	@Failure		401		{object}	gin.H			"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H			"Forbidden - not an admin"
+//	@Failure		404		{object}	gin.H			"Not Found - user not found"
+//	@Failure		500		{object}	gin.H			"Internal Server Error - failed to retrieve sessions"
+//	@Router			/admin/users/{userID}/sessions [get]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) AdminGetSessions(c *gin.Context) {
+
+	sessions, err := s.service.AdminGetSessions(c.Request.Context(), c.Param("userID"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// AdminRevokeSession godoc
+//
+//	@Summary		End a user's session
+//	@Description	Logs any user out of one device, for incident response
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			userID		path		string	true	"User ID"
+//	@Param			sessionID	path		string	true	"Session ID"
+//	@Success		200			{object}	gin.H	"Session revoked"
+//	@Failure		401			{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H	"Forbidden - not an admin"
+//	@Failure		404			{object}	gin.H	"Not Found - session not found"
+//	@Failure		500			{object}	gin.H	"Internal Server Error - failed to revoke session"
+//	@Router			/admin/users/{userID}/sessions/{sessionID} [delete]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) AdminRevokeSession(c *gin.Context) {
+
+	if err := s.service.RevokeSession(c.Request.Context(), c.Param("userID"), c.Param("sessionID")); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// AdminRevokeAllSessions godoc
+//
+//	@Summary		Log a user out everywhere
+//	@Description	Ends every session of any user, for incident response
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Success		200		{object}	gin.H	"Number of sessions revoked"
+//	@Failure		401		{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		403		{object}	gin.H	"Forbidden - not an admin"
+//	@Failure		500		{object}	gin.H	"Internal Server Error - failed to revoke sessions"
+//	@Router			/admin/users/{userID}/sessions [delete]
+//
+//	@Security		jwtCookieAuth
+func (s *SessionHandler) AdminRevokeAllSessions(c *gin.Context) {
+
+	revoked, err := s.service.RevokeAllSessions(c.Request.Context(), c.Param("userID"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user logged out everywhere", "revoked": revoked})
+}
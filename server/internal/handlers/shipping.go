@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type ShippingHandler struct {
+	service services.ShippingServiceInterface
+}
+
+func NewShippingHandler(service services.ShippingServiceInterface) *ShippingHandler {
+	return &ShippingHandler{
+		service: service,
+	}
+}
+
+// SelectShipping godoc
+//
+//	@Summary		Choose shipping
+//	@Description	Lets the winner of a closed auction choose one of the seller's shipping options and an address from their address book (not needed for pickup). Checkout adds the shipping as its own line item. The choice can be changed until the auction is paid.
+//	@Tags			Auctions
+//	@Accept			json
+//	@Produce		json
+//	@Param			auctionID	path		string							true	"Auction ID"
+//	@Param			payload		body		models.ShippingSelectionRequest	true	"Shipping choice"
+//	@Success		200			{object}	models.ShippingSelection		"Shipping selection"
+//	@Failure		400			{object}	gin.H							"Bad Request - method not offered or address missing"
+//	@Failure		401			{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H							"Forbidden - not the winner of a closed auction"
+//	@Failure		404			{object}	gin.H							"Not Found - auction or address not found"
+//	@Failure		409			{object}	gin.H							"Conflict - auction already paid"
+//	@Failure		500			{object}	gin.H							"Internal Server Error - failed to save shipping selection"
+//	@Router			/auctions/{auctionID}/shipping [put]
+//
+//	@Security		jwtCookieAuth
+func (s *ShippingHandler) SelectShipping(c *gin.Context) {
+
+	var payload models.ShippingSelectionRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	selection, err := s.service.SelectShipping(c.Request.Context(), c.Param("auctionID"), authUser.ID, &payload)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
+
+// GetShipping godoc
+//
+//	@Summary		Get shipping
+//	@Description	Returns the shipping chosen for an auction to its buyer, its seller or an admin. The seller only sees the ship-to address once the auction is paid.
+//	@Tags			Auctions
+//	@Produce		json
+//	@Param			auctionID	path		string						true	"Auction ID"
+//	@Success		200			{object}	models.ShippingSelection	"Shipping selection"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		403			{object}	gin.H						"Forbidden - not the buyer, the seller or an admin"
+//	@Failure		404			{object}	gin.H						"Not Found - auction not found or no shipping chosen"
+//	@Failure		500			{object}	gin.H						"Internal Server Error - failed to retrieve shipping selection"
+//	@Router			/auctions/{auctionID}/shipping [get]
+//
+//	@Security		jwtCookieAuth
+func (s *ShippingHandler) GetShipping(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	selection, err := s.service.GetShipping(c.Request.Context(), c.Param("auctionID"), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, selection)
+}
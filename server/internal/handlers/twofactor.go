@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type TwoFactorHandler struct {
+	service services.TwoFactorServiceInterface
+}
+
+func NewTwoFactorHandler(service services.TwoFactorServiceInterface) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service: service,
+	}
+}
+
+// SetupTwoFactor godoc
+//
+//	@Summary		Start two-factor setup
+//	@Description	Generates a TOTP secret for the authenticated user to add to an authenticator app, as the otpauth URI (usually shown as a QR code) or by typing in the secret. Two-factor stays off until it is confirmed with a code; starting again replaces an unconfirmed secret.
+//	@Tags			Two-Factor
+//	@Produce		json
+//	@Success		200	{object}	models.TwoFactorSetupResponse	"Secret and otpauth URI"
+//	@Failure		401	{object}	gin.H							"Unauthorized - user not authenticated"
+//	@Failure		409	{object}	gin.H							"Conflict - two-factor already enabled"
+//	@Failure		500	{object}	gin.H							"Internal Server Error - failed to set up two-factor"
+//	@Router			/me/2fa/setup [post]
+//
+//	@Security		jwtCookieAuth
+func (t *TwoFactorHandler) SetupTwoFactor(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	setup, err := t.service.Setup(c.Request.Context(), authUser)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// ConfirmTwoFactor godoc
+//
+//	@Summary		Confirm two-factor setup
+//	@Description	Turns two-factor on with a first code from the authenticator app and returns ten single-use recovery codes. They are shown this once.
+//	@Tags			Two-Factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorCodeRequest		true	"Code from the authenticator app"
+//	@Success		200		{object}	models.RecoveryCodesResponse	"Recovery codes"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H							"Unauthorized - not authenticated or invalid code"
+//	@Failure		409		{object}	gin.H							"Conflict - setup not started or two-factor already enabled"
+//	@Failure		500		{object}	gin.H							"Internal Server Error - failed to set up two-factor"
+//	@Router			/me/2fa/confirm [post]
+//
+//	@Security		jwtCookieAuth
+func (t *TwoFactorHandler) ConfirmTwoFactor(c *gin.Context) {
+
+	var payload models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	codes, err := t.service.Confirm(c.Request.Context(), authUser.ID, payload.Code)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableTwoFactor godoc
+//
+//	@Summary		Turn two-factor off
+//	@Description	Turns two-factor off and drops the recovery codes, given a code from the authenticator app or a recovery code
+//	@Tags			Two-Factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorCodeRequest	true	"Code from the authenticator app or a recovery code"
+//	@Success		200		{object}	gin.H						"Two-factor disabled"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H						"Unauthorized - not authenticated or invalid code"
+//	@Failure		409		{object}	gin.H						"Conflict - two-factor not enabled"
+//	@Failure		500		{object}	gin.H						"Internal Server Error"
+//	@Router			/me/2fa/disable [post]
+//
+//	@Security		jwtCookieAuth
+func (t *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+
+	var payload models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := t.service.Disable(c.Request.Context(), authUser.ID, payload.Code); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replaces the authenticated user's recovery codes with ten new ones, given a code from the authenticator app. The old codes stop working.
+//	@Tags			Two-Factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorCodeRequest		true	"Code from the authenticator app"
+//	@Success		200		{object}	models.RecoveryCodesResponse	"Recovery codes"
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H							"Unauthorized - not authenticated or invalid code"
+//	@Failure		409		{object}	gin.H							"Conflict - two-factor not enabled"
+//	@Failure		500		{object}	gin.H							"Internal Server Error"
+//	@Router			/me/2fa/recovery-codes [post]
+//
+//	@Security		jwtCookieAuth
+func (t *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+
+	var payload models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	codes, err := t.service.RegenerateRecoveryCodes(c.Request.Context(), authUser.ID, payload.Code)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
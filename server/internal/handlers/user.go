@@ -4,31 +4,35 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/puremike/online_auction_api/contexts"
 	"github.com/puremike/online_auction_api/internal/config"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/services"
 )
 
 type UserHandler struct {
-	service services.UserServiceInterface
-	app     *config.Application
+	service      services.UserServiceInterface
+	verification services.VerificationServiceInterface
+	app          *config.Application
 }
 
-func NewUserHandler(service services.UserServiceInterface, app *config.Application) *UserHandler {
+func NewUserHandler(service services.UserServiceInterface, verification services.VerificationServiceInterface, app *config.Application) *UserHandler {
 	return &UserHandler{
-		service: service,
-		app:     app,
+		service:      service,
+		verification: verification,
+		app:          app,
 	}
 }
 
 // CreateUser godoc
 //
 //	@Summary		Create user
-//	@Description	Create a new user
+//	@Description	Create a new user and email them a link to verify their address
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
@@ -66,6 +70,11 @@ func (u *UserHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
+	// the account exists either way; the user can ask for another link
+	if err := u.verification.SendVerification(c.Request.Context(), createdUser.ID, createdUser.Email); err != nil {
+		log.Printf("failed to send verification email to new user %s: %v", createdUser.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, models.UserResponse{
 		ID:        createdUser.ID,
 		Username:  createdUser.Username,
@@ -83,15 +92,18 @@ func (u *UserHandler) RegisterUser(c *gin.Context) {
 //	@Description	Upon successful authentication, a short-lived **JWT (access token)** is set as an `HttpOnly` cookie named `jwt`.
 //	@Description	A long-lived **refresh token** is also set as an `HttpOnly` cookie named `refresh_token`.
 //	@Description	Both cookies are crucial for maintaining user session and subsequent authenticated requests.
+//	@Description	Users with two-factor authentication get no cookies; the response carries `two_factor_required` and a `challenge_token` to send to `/login/2fa` with a code. With TWO_FACTOR_REQUIRED_FOR_ADMINS set, admins without it are refused.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
-//	@Param			payload	body		models.LoginRequest	true	"Login credentials"
-//	@Success		200		{object}	string				"login successful"
-//	@Success		200		{header}	string				Set-Cookie	"Two HttpOnly cookies are set: 'jwt' (access token) and 'refresh_token' (refresh token)."
-//	@Failure		400		{object}	gin.H				"Bad Request - invalid input"
-//	@Failure		401		{object}	gin.H				"Unauthorized - invalid credentials"
-//	@Failure		500		{object}	gin.H				"Internal Server Error"
+//	@Param			payload	body		models.LoginRequest		true	"Login credentials"
+//	@Success		200		{object}	string					"login successful"
+//	@Success		200		{object}	models.LoginResponse	"two-factor challenge"
+//	@Success		200		{header}	string					Set-Cookie	"Two HttpOnly cookies are set: 'jwt' (access token) and 'refresh_token' (refresh token)."
+//	@Failure		400		{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H					"Unauthorized - invalid credentials"
+//	@Failure		403		{object}	gin.H					"Forbidden - two-factor authentication required"
+//	@Failure		500		{object}	gin.H					"Internal Server Error"
 //	@Router			/login [post]
 func (u *UserHandler) Login(c *gin.Context) {
 
@@ -101,7 +113,7 @@ func (u *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := u.service.Login(c.Request.Context(), &payload)
+	user, err := u.service.Login(c.Request.Context(), &payload, clientOf(c))
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
@@ -111,9 +123,7 @@ func (u *UserHandler) Login(c *gin.Context) {
 	// c.SetCookie("refresh_token", user.RefreshToken, int(u.app.AppConfig.AuthConfig.RefreshTokenExp.Seconds()), "/", "", false, true)
 	// c.SetSameSite(http.SameSiteStrictMode)
 
-	u.setJwtCookie(c, user)
-
-	c.JSON(http.StatusOK, "login successful")
+	u.completeLogin(c, user)
 }
 
 // AdminLogin godoc
@@ -123,15 +133,18 @@ func (u *UserHandler) Login(c *gin.Context) {
 //	@Description	Upon successful authentication, a short-lived **JWT (access token)** is set as an `HttpOnly` cookie named `jwt`.
 //	@Description	A long-lived **refresh token** is also set as an `HttpOnly` cookie named `refresh_token`.
 //	@Description	Both cookies are crucial for maintaining user session and subsequent authenticated requests.
+//	@Description	Admins with two-factor authentication get a `challenge_token` to send to `/login/2fa` instead. With TWO_FACTOR_REQUIRED_FOR_ADMINS set, admins without it are refused.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
-//	@Param			payload	body		models.LoginRequest	true	"Login credentials"
-//	@Success		200		{object}	string				"login successful"
-//	@Success		200		{header}	string				Set-Cookie	"Two HttpOnly cookies are set: 'jwt' (access token) and 'refresh_token' (refresh token)."
-//	@Failure		400		{object}	gin.H				"Bad Request - invalid input"
-//	@Failure		401		{object}	gin.H				"Unauthorized - invalid credentials"
-//	@Failure		500		{object}	gin.H				"Internal Server Error"
+//	@Param			payload	body		models.LoginRequest		true	"Login credentials"
+//	@Success		200		{object}	string					"login successful"
+//	@Success		200		{object}	models.LoginResponse	"two-factor challenge"
+//	@Success		200		{header}	string					Set-Cookie	"Two HttpOnly cookies are set: 'jwt' (access token) and 'refresh_token' (refresh token)."
+//	@Failure		400		{object}	gin.H					"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H					"Unauthorized - invalid credentials or not an admin"
+//	@Failure		403		{object}	gin.H					"Forbidden - two-factor authentication required"
+//	@Failure		500		{object}	gin.H					"Internal Server Error"
 //	@Router			/admin/login [post]
 func (u *UserHandler) AdminLogin(c *gin.Context) {
 
@@ -141,20 +154,38 @@ func (u *UserHandler) AdminLogin(c *gin.Context) {
 		return
 	}
 
-	existingUser, err := u.app.Store.Users.GetUserByEmail(c.Request.Context(), payload.Email)
+	user, err := u.service.AdminLogin(c.Request.Context(), &payload, clientOf(c))
 	if err != nil {
-		if errors.Is(err, errs.ErrUserNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
-			return
-		}
+		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	if !existingUser.IsAdmin {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not an admin"})
+	u.completeLogin(c, user)
+}
+
+// LoginTwoFactor godoc
+//
+//	@Summary		Complete a two-factor login
+//	@Description	Exchanges the challenge token a login returned, and a code from the authenticator app or a recovery code, for the `jwt` and `refresh_token` cookies. A challenge expires after TWO_FACTOR_CHALLENGE_TTL and is used up by TWO_FACTOR_MAX_ATTEMPTS wrong codes.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorLoginRequest	true	"Challenge token and code"
+//	@Success		200		{object}	string							"login successful"
+//	@Success		200		{header}	string							Set-Cookie	"Two HttpOnly cookies are set: 'jwt' (access token) and 'refresh_token' (refresh token)."
+//	@Failure		400		{object}	gin.H							"Bad Request - invalid input"
+//	@Failure		401		{object}	gin.H							"Unauthorized - invalid code or challenge"
+//	@Failure		500		{object}	gin.H							"Internal Server Error"
+//	@Router			/login/2fa [post]
+func (u *UserHandler) LoginTwoFactor(c *gin.Context) {
+
+	var payload models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	user, err := u.service.Login(c.Request.Context(), &payload)
+
+	user, err := u.service.CompleteTwoFactorLogin(c.Request.Context(), &payload, clientOf(c))
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
@@ -165,6 +196,20 @@ func (u *UserHandler) AdminLogin(c *gin.Context) {
 	c.JSON(http.StatusOK, "login successful")
 }
 
+// completeLogin sets the session cookies, or hands a two-factor challenge back
+// for the client to complete
+func (u *UserHandler) completeLogin(c *gin.Context, user *models.LoginResponse) {
+
+	if user.TwoFactorRequired {
+		c.JSON(http.StatusOK, user)
+		return
+	}
+
+	u.setJwtCookie(c, user)
+
+	c.JSON(http.StatusOK, "login successful")
+}
+
 func (u *UserHandler) setJwtCookie(c *gin.Context, user *models.LoginResponse) {
 
 	isTrue := true
@@ -196,20 +241,12 @@ func (u *UserHandler) setJwtCookie(c *gin.Context, user *models.LoginResponse) {
 	})
 }
 
-// Logout godoc
-//
-//	@Summary		Logout User
-//	@Description	Clears the user's authentication cookies, effectively logging them out.
-//	@Tags			Users
-//	@Success		200	{object}	gin.H	"Logout successful"
-//	@Router			/logout [post]
-//
-//	@Security		jwtCookieAuth
-func (u *UserHandler) Logout(c *gin.Context) {
+// clearAuthCookies removes the jwt and refresh_token cookies
+func clearAuthCookies(c *gin.Context, app *config.Application) {
 	isSecure := true
 	sameSite := http.SameSiteNoneMode
 
-	if u.app.AppConfig.Env == "development" {
+	if app.AppConfig.Env == "development" {
 		isSecure = false
 		sameSite = http.SameSiteLaxMode
 	}
@@ -235,6 +272,39 @@ func (u *UserHandler) Logout(c *gin.Context) {
 		Secure:   isSecure,
 		SameSite: sameSite,
 	})
+}
+
+// clientOf identifies the device a request comes from, for the session it
+// starts or uses
+func clientOf(c *gin.Context) *models.Client {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	return &models.Client{UserAgent: userAgent, IPAddress: c.ClientIP()}
+}
+
+// Logout godoc
+//
+//	@Summary		Logout User
+//	@Description	Revokes the session the user logged in with and clears the user's authentication cookies, effectively logging them out.
+//	@Tags			Users
+//	@Success		200	{object}	gin.H	"Logout successful"
+//	@Failure		500	{object}	gin.H	"Internal Server Error - failed to revoke the session"
+//	@Router			/logout [post]
+//
+//	@Security		jwtCookieAuth
+func (u *UserHandler) Logout(c *gin.Context) {
+
+	// without a refresh token cookie there is nothing to revoke
+	refreshToken, _ := c.Cookie("refresh_token")
+	if err := u.service.Logout(c.Request.Context(), refreshToken); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	clearAuthCookies(c, u.app)
 
 	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
 }
@@ -243,7 +313,8 @@ func (u *UserHandler) Logout(c *gin.Context) {
 //
 //	@Summary		Refresh JWT Token
 //	@Description	Refreshes the JWT access token using a valid refresh token.
-//	@Description	If the refresh token is valid, a new JWT is generated and set as an `HttpOnly` cookie.
+//	@Description	If the refresh token is valid, a new JWT and a new refresh token are generated and set as `HttpOnly` cookies. The old refresh token stops working.
+//	@Description	Presenting a refresh token that was already used revokes every token issued since the login it came from.
 //	@Description	A valid refresh token must be provided as an `HttpOnly` cookie named `refresh_token`.
 //	@Tags			Users
 //	@Accept			json
@@ -260,14 +331,13 @@ func (u *UserHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newToken, err := u.service.Refresh(c.Request.Context(), refreshToken)
+	tokens, err := u.service.Refresh(c.Request.Context(), refreshToken, clientOf(c))
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	c.SetCookie("jwt", newToken, int(u.app.AppConfig.AuthConfig.TokenExp.Seconds()), "/", "", false, true)
-	c.SetSameSite(http.SameSiteStrictMode)
+	u.setJwtCookie(c, tokens)
 
 	c.JSON(http.StatusOK, gin.H{"message": "token refreshed successfully"})
 }
@@ -308,12 +378,14 @@ func (u *UserHandler) UserProfile(c *gin.Context) {
 	}
 
 	res := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		Location:  user.Location,
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		Location:      user.Location,
+		CreatedAt:     user.CreatedAt,
+		EmailVerified: user.EmailVerified,
+		Feedback:      user.Feedback,
 	}
 
 	c.JSON(http.StatusOK, res)
@@ -349,12 +421,13 @@ func (u *UserHandler) MeProfile(c *gin.Context) {
 
 	// Respond with the UserResponse model
 	res := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		Location:  user.Location,
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		Location:      user.Location,
+		CreatedAt:     user.CreatedAt,
+		EmailVerified: user.EmailVerified,
 	}
 
 	c.JSON(http.StatusOK, res)
@@ -363,7 +436,7 @@ func (u *UserHandler) MeProfile(c *gin.Context) {
 // UpdateProfile godoc
 //
 //	@Summary		Update user profile
-//	@Description	Allows an authenticated user to update their profile details such as username, email, full name, and location.
+//	@Description	Allows an authenticated user to update their profile details such as username, email, full name, and location. A changed email address has to be verified again.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
@@ -424,13 +497,19 @@ func (u *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	if email := strings.ToLower(payload.Email); email != authUser.Email {
+		if err := u.verification.SendVerification(c.Request.Context(), authUser.ID, email); err != nil {
+			log.Printf("failed to send verification email to user %s after an email change: %v", authUser.ID, err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, msg)
 }
 
 // ChangePassword godoc
 //
 //	@Summary		Change User Password
-//	@Description	Allows an authenticated user to change their password.
+//	@Description	Allows an authenticated user to change their password. Every other login is signed out; this one gets new `jwt` and `refresh_token` cookies.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
@@ -478,13 +557,16 @@ func (u *UserHandler) ChangePassword(c *gin.Context) {
 	// 	return
 	// }
 
-	msg, err := u.service.ChangePassword(c.Request.Context(), &payload, authUser.ID)
+	tokens, err := u.service.ChangePassword(c.Request.Context(), &payload, authUser.ID, clientOf(c))
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, msg)
+	// every login was revoked, this one included; carry on with a fresh one
+	u.setJwtCookie(c, tokens)
+
+	c.JSON(http.StatusCreated, "password changed successfully")
 }
 
 // AdminGetUsers godoc
@@ -494,9 +576,12 @@ func (u *UserHandler) ChangePassword(c *gin.Context) {
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	[]models.UserResponse
-//	@Failure		401	{object}	gin.H	"Unauthorized - user not authenticated"
-//	@Failure		500	{object}	gin.H	"Internal Server Error - failed to retrieve users"
+//	@Param			limit	query		int										false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string									false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.UserResponse]	"Page of users"
+//	@Failure		400		{object}	gin.H									"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H									"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H									"Internal Server Error - failed to retrieve users"
 //	@Router			/admin/users [get]
 //
 //	@Security		jwtCookieAuth
@@ -508,25 +593,19 @@ func (u *UserHandler) AdminGetUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := u.service.GetUsers(c.Request.Context())
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
 	if err != nil {
 		errs.MapServiceErrors(c, err)
 		return
 	}
 
-	res := &[]models.UserResponse{}
-	for _, user := range *users {
-		*res = append(*res, models.UserResponse{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			FullName:  user.FullName,
-			Location:  user.Location,
-			CreatedAt: user.CreatedAt,
-		})
+	users, err := u.service.GetUsers(c.Request.Context(), page)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, users)
 }
 
 func (u *UserHandler) DeleteUser(c *gin.Context) {
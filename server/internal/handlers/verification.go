@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type VerificationHandler struct {
+	service services.VerificationServiceInterface
+}
+
+func NewVerificationHandler(service services.VerificationServiceInterface) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+	}
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify email address
+//	@Description	Confirms the user owns their email address with the token from the link mailed to them. A token works once, for EMAIL_VERIFICATION_TTL, and only for the address it was sent to.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.VerifyEmailRequest	true	"Token from the verification link"
+//	@Success		200		{object}	gin.H						"Email verified"
+//	@Failure		400		{object}	gin.H						"Bad Request - invalid or expired token"
+//	@Failure		429		{object}	gin.H						"Too Many Requests - rate limit exceeded"
+//	@Failure		500		{object}	gin.H						"Internal Server Error - failed to verify email"
+//	@Router			/verify-email [post]
+func (v *VerificationHandler) VerifyEmail(c *gin.Context) {
+
+	var payload models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := v.service.VerifyEmail(c.Request.Context(), payload.Token); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// ResendVerification godoc
+//
+//	@Summary		Resend verification email
+//	@Description	Mails the authenticated user a new verification link, which replaces the ones sent before. Links can be asked for once per EMAIL_VERIFICATION_RESEND_COOLDOWN.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{object}	gin.H	"Verification email sent"
+//	@Failure		401	{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		409	{object}	gin.H	"Conflict - email already verified"
+//	@Failure		429	{object}	gin.H	"Too Many Requests - a link was sent recently"
+//	@Failure		500	{object}	gin.H	"Internal Server Error - failed to send verification email"
+//	@Router			/verify-email/resend [post]
+//
+//	@Security		jwtCookieAuth
+func (v *VerificationHandler) ResendVerification(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := v.service.ResendVerification(c.Request.Context(), authUser); err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/puremike/online_auction_api/contexts"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+type WatchlistHandler struct {
+	service services.WatchlistServiceInterface
+}
+
+func NewWatchlistHandler(service services.WatchlistServiceInterface) *WatchlistHandler {
+	return &WatchlistHandler{
+		service: service,
+	}
+}
+
+// WatchAuction godoc
+//
+//	@Summary		Watch Auction
+//	@Description	Adds an auction to the user's watchlist. Watchers are reminded before the auction ends and, if they opt in, notified on price changes.
+//	@Tags			Watchlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			auctionID	path		string						true	"ID of the auction to watch"
+//	@Param			payload		body		models.WatchAuctionRequest	false	"Watch preferences"
+//	@Success		201			{object}	models.WatchResponse		"Auction watched"
+//	@Failure		400			{object}	gin.H						"Bad Request - invalid input"
+//	@Failure		401			{object}	gin.H						"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H						"NotFound - auction not found"
+//	@Failure		500			{object}	gin.H						"Internal Server Error - failed to watch auction"
+//	@Router			/auctions/{auctionID}/watch [post]
+//
+//	@Security		jwtCookieAuth
+func (w *WatchlistHandler) WatchAuction(c *gin.Context) {
+
+	var payload models.WatchAuctionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	existingAuction, err := contexts.GetAuctionFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auction not found"})
+		return
+	}
+
+	watch := &models.Watch{
+		UserID:            authUser.ID,
+		AuctionID:         existingAuction.ID,
+		NotifyPriceChange: payload.NotifyPriceChange,
+	}
+
+	res, err := w.service.WatchAuction(c.Request.Context(), watch, existingAuction.SellerID)
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// UnwatchAuction godoc
+//
+//	@Summary		Unwatch Auction
+//	@Description	Removes an auction from the user's watchlist.
+//	@Tags			Watchlist
+//	@Produce		json
+//	@Param			auctionID	path		string	true	"ID of the auction to unwatch"
+//	@Success		200			{object}	string	"auction removed from watchlist"
+//	@Failure		401			{object}	gin.H	"Unauthorized - user not authenticated"
+//	@Failure		404			{object}	gin.H	"NotFound - auction not on watchlist"
+//	@Failure		500			{object}	gin.H	"Internal Server Error - failed to unwatch auction"
+//	@Router			/auctions/{auctionID}/watch [delete]
+//
+//	@Security		jwtCookieAuth
+func (w *WatchlistHandler) UnwatchAuction(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	msg, err := w.service.UnwatchAuction(c.Request.Context(), authUser.ID, c.Param("auctionID"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
+
+// GetWatchedAuctions godoc
+//
+//	@Summary		Get My Watched Auctions
+//	@Description	Retrieves the auctions on the user's watchlist, ending soonest first.
+//	@Tags			Watchlist
+//	@Produce		json
+//	@Param			limit	query		int												false	"Page size, capped at 100"	default(10)
+//	@Param			cursor	query		string											false	"Cursor from the previous page's next_cursor"
+//	@Success		200		{object}	pagination.Page[models.CreateAuctionResponse]	"Page of auctions"
+//	@Failure		400		{object}	gin.H											"Bad Request - invalid limit or cursor"
+//	@Failure		401		{object}	gin.H											"Unauthorized - user not authenticated"
+//	@Failure		500		{object}	gin.H											"Internal Server Error - failed to retrieve auctions"
+//	@Router			/auctions/watched [get]
+//
+//	@Security		jwtCookieAuth
+func (w *WatchlistHandler) GetWatchedAuctions(c *gin.Context) {
+
+	authUser, err := contexts.GetUserFromContext(c)
+	if authUser == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		errs.MapServiceErrors(c, err)
+		return
+	}
+
+	auctions, err := w.service.GetWatchedAuctions(c.Request.Context(), authUser.ID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load watched auctions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, auctions)
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var _ Mailer = (*ConsoleMailer)(nil)
+
+// ConsoleMailer writes messages to stdout, or appends them to a file when a
+// path is given, so links can be followed during local development without a
+// mail server
+type ConsoleMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewConsoleMailer(path string) *ConsoleMailer {
+	return &ConsoleMailer{
+		path: path,
+	}
+}
+
+func (c *ConsoleMailer) Name() string {
+	return "console"
+}
+
+func (c *ConsoleMailer) Send(ctx context.Context, msg *Message) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var w io.Writer = os.Stdout
+	if c.path != "" {
+		f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open mail file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	_, err := fmt.Fprintf(w, "----- %s -----\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
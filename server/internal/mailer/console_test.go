@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleMailerAppendsToFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := NewConsoleMailer(path)
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: "a@example.com", Subject: "first", Body: "link one"}))
+	require.NoError(t, m.Send(context.Background(), &Message{To: "b@example.com", Subject: "second", Body: "link two"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "To: a@example.com\nSubject: first\n\nlink one")
+	assert.Contains(t, string(data), "To: b@example.com\nSubject: second\n\nlink two")
+}
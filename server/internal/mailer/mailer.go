@@ -0,0 +1,18 @@
+package mailer
+
+import "context"
+
+// Mailer delivers transactional email such as verification links. SMTPMailer
+// sends through a mail server; ConsoleMailer prints messages for local
+// development.
+type Mailer interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
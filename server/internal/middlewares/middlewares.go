@@ -145,22 +145,63 @@ func handleUserFetchError(c *gin.Context, err error) {
 	c.Abort()
 }
 
-// Grant administrator access to the user
-func AuthorizeRoles(allowedRole bool) gin.HandlerFunc {
+// Grant administrator access to the user. When TWO_FACTOR_REQUIRED_FOR_ADMINS
+// is on, admins without confirmed two-factor are refused too, whatever session
+// they came with.
+func (m *Middleware) AuthorizeRoles(allowedRole bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		user, err := contexts.GetUserFromContext(c)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
 
-		if user.IsAdmin && allowedRole {
+		if !user.IsAdmin || !allowedRole {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient role"})
+			return
+		}
+
+		if m.app.AppConfig.TwoFactorConf.RequiredForAdmins {
+			tf, err := m.app.Store.TwoFactor.GetTwoFactor(c.Request.Context(), user.ID)
+			if err != nil && !errors.Is(err, errs.ErrTwoFactorNotEnabled) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to verify two-factor authentication"})
+				return
+			}
+			if tf == nil || tf.EnabledAt == nil {
+				errs.MapServiceErrors(c, errs.ErrTwoFactorRequired)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks users who haven't verified their email address,
+// when EMAIL_VERIFICATION_REQUIRED is on
+func (m *Middleware) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		if !m.app.AppConfig.VerifyConf.Required {
 			c.Next()
 			return
 		}
 
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient role"})
+		user, err := contexts.GetUserFromContext(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if user.EmailVerifiedAt == nil {
+			errs.MapServiceErrors(c, errs.ErrEmailNotVerified)
+			c.Abort()
+			return
+		}
+
+		c.Next()
 	}
 }
 
@@ -17,9 +17,25 @@ type Auction struct {
 	SellerID      string    `json:"seller_id"`
 	WinnerID      string    `json:"winner_id"`
 	IsPaid        bool      `json:"is_paid"`
-	Category      string    `json:"category"` // "mobile", "pc" "accessories"
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	Category      string    `json:"category"`       // "mobile", "pc" "accessories"
+	HoldThreshold float64   `json:"hold_threshold"` // bids at or above it need a card hold, 0 = never
+	WeightKg      float64   `json:"weight_kg"`      // prices weight-based shipping
+
+	ShippingOptions []ShippingOption `json:"shipping_options"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// only set when the auction was found through full-text search
+	Rank      float64          `json:"rank,omitempty"`
+	Highlight *SearchHighlight `json:"highlight,omitempty"`
+}
+
+// SearchHighlight holds HTML-escaped title and description snippets with
+// matched terms wrapped in <mark> tags
+type SearchHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
 }
 
 type CreateAuctionRequest struct {
@@ -31,6 +47,10 @@ type CreateAuctionRequest struct {
 	StartTime     string  `json:"start_time" binding:"required"`
 	EndTime       string  `json:"end_time" binding:"required"`
 	ImagePath     string  `json:"image_path"`
+	HoldThreshold float64 `json:"hold_threshold" binding:"gte=0"`
+	WeightKg      float64 `json:"weight_kg" binding:"gte=0"`
+
+	ShippingOptions []ShippingOption `json:"shipping_options" binding:"max=4,dive"`
 }
 
 type CreateAuctionResponse struct {
@@ -48,6 +68,16 @@ type CreateAuctionResponse struct {
 	ImagePath     string    `json:"image_path"`
 	Category      string    `json:"category"`
 	IsPaid        bool      `json:"is_paid"`
+	WatcherCount  int       `json:"watcher_count"`
+	HoldThreshold float64   `json:"hold_threshold,omitempty"`
+	WeightKg      float64   `json:"weight_kg,omitempty"`
+
+	ShippingOptions []ShippingOption `json:"shipping_options,omitempty"`
+
+	SellerReputation *FeedbackScore `json:"seller_reputation,omitempty"`
+
+	Rank      float64          `json:"rank,omitempty"`
+	Highlight *SearchHighlight `json:"highlight,omitempty"`
 }
 
 type UpdateAuctionRequest struct {
@@ -58,6 +88,10 @@ type UpdateAuctionRequest struct {
 	StartTime     string  `json:"start_time" binding:"required"`
 	EndTime       string  `json:"end_time" binding:"required"`
 	ImagePath     string  `json:"image_path"`
+	HoldThreshold float64 `json:"hold_threshold" binding:"gte=0"`
+	WeightKg      float64 `json:"weight_kg" binding:"gte=0"`
+
+	ShippingOptions []ShippingOption `json:"shipping_options" binding:"max=4,dive"`
 }
 
 type AuctionFilter struct {
@@ -65,4 +99,37 @@ type AuctionFilter struct {
 	Category      string  `json:"category"`
 	Status        string  `json:"status"`
 	StartingPrice float64 `json:"starting_price"`
+	MinPrice      float64 `json:"min_price"`
+	MaxPrice      float64 `json:"max_price"`
+	Keyword       string  `json:"keyword"`
+	Query         string  `json:"q,omitempty"` // full-text search over title and description
+
+	SellerID          string `json:"seller_id,omitempty"`
+	EndingWithinHours int    `json:"ending_within_hours,omitempty"`
+	HasBids           *bool  `json:"has_bids,omitempty"`
+	IsPaid            *bool  `json:"is_paid,omitempty"`
+	Sort              string `json:"sort,omitempty"` // one of the AuctionSort* keys
+}
+
+// Sort keys accepted by GET /auctions
+const (
+	AuctionSortEndingSoon = "ending_soon"
+	AuctionSortNewest     = "newest"
+	AuctionSortPriceAsc   = "price_asc"
+	AuctionSortPriceDesc  = "price_desc"
+	AuctionSortMostBids   = "most_bids"
+)
+
+// OrderedByCreation reports whether results come newest first, the only
+// ordering that pages by keyset rather than offset
+func (f *AuctionFilter) OrderedByCreation() bool {
+	return f.Sort == AuctionSortNewest || (f.Sort == "" && f.Query == "")
+}
+
+func IsValidAuctionSort(sort string) bool {
+	switch sort {
+	case "", AuctionSortEndingSoon, AuctionSortNewest, AuctionSortPriceAsc, AuctionSortPriceDesc, AuctionSortMostBids:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// Dispute is a buyer's claim that something went wrong with an order. While it
+// is open the order is disputed and the seller's funds stay held.
+//
+// Deadline is when the party whose turn it is has to act: the seller while the
+// dispute is open, the buyer while a return is awaited and the seller again
+// once the return has shipped. A missed deadline sends the dispute to an admin.
+type Dispute struct {
+	ID                   string     `json:"id"`
+	OrderID              string     `json:"order_id"`
+	AuctionID            string     `json:"auction_id"`
+	BuyerID              string     `json:"buyer_id"`
+	SellerID             string     `json:"seller_id"`
+	Reason               string     `json:"reason"`
+	Description          string     `json:"description"`
+	Status               string     `json:"status"` // open, under_review, awaiting_return, return_shipped, refunded, rejected
+	Deadline             *time.Time `json:"deadline,omitempty"`
+	ReturnCarrier        string     `json:"return_carrier,omitempty"`
+	ReturnTrackingNumber string     `json:"return_tracking_number,omitempty"`
+	RefundAmount         float64    `json:"refund_amount,omitempty"`
+	ResolutionNote       string     `json:"resolution_note,omitempty"`
+	ResolvedBy           *string    `json:"resolved_by,omitempty"`
+	ResolvedAt           *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+const (
+	DisputeNotReceived    = "not_received"
+	DisputeNotAsDescribed = "not_as_described"
+	DisputeDamaged        = "damaged"
+	DisputeCounterfeit    = "counterfeit"
+	DisputeOther          = "other"
+)
+
+const (
+	DisputeOpen           = "open"
+	DisputeUnderReview    = "under_review"
+	DisputeAwaitingReturn = "awaiting_return"
+	DisputeReturnShipped  = "return_shipped"
+	DisputeRefunded       = "refunded"
+	DisputeRejected       = "rejected"
+)
+
+func IsValidDisputeStatus(status string) bool {
+	switch status {
+	case DisputeOpen, DisputeUnderReview, DisputeAwaitingReturn, DisputeReturnShipped, DisputeRefunded, DisputeRejected:
+		return true
+	}
+	return false
+}
+
+// DisputeMessage is a message, a piece of evidence or a status change in the
+// thread of a dispute. System messages have no sender.
+type DisputeMessage struct {
+	ID         string    `json:"id"`
+	DisputeID  string    `json:"dispute_id"`
+	SenderID   *string   `json:"sender_id,omitempty"`
+	SenderRole string    `json:"sender_role"` // buyer, seller, admin, system
+	Body       string    `json:"body,omitempty"`
+	ImagePath  string    `json:"image_path,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const (
+	DisputeSenderBuyer  = "buyer"
+	DisputeSenderSeller = "seller"
+	DisputeSenderAdmin  = "admin"
+	DisputeSenderSystem = "system"
+)
+
+type OpenDisputeRequest struct {
+	Reason      string `json:"reason" binding:"required,oneof=not_received not_as_described damaged counterfeit other"`
+	Description string `json:"description" binding:"required,max=2000"`
+}
+
+type DisputeMessageRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+type ReturnShipmentRequest struct {
+	Carrier        string `json:"carrier" binding:"required,max=64"`
+	TrackingNumber string `json:"tracking_number" binding:"required,max=64"`
+}
+
+const (
+	DisputeOutcomeRefund = "refund"
+	DisputeOutcomeReturn = "return"
+	DisputeOutcomeReject = "reject"
+)
+
+// ResolveDisputeRequest is an admin's ruling on a dispute. A refund repays
+// amount, or everything not yet refunded when amount is omitted; a return has
+// the buyer send the item back to be refunded in full.
+type ResolveDisputeRequest struct {
+	Outcome string  `json:"outcome" binding:"required,oneof=refund return reject"`
+	Amount  float64 `json:"amount,omitempty" binding:"min=0"`
+	Note    string  `json:"note" binding:"required,max=2000"`
+}
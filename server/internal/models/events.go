@@ -28,10 +28,17 @@ type AuctionUpdateEvent struct {
 type NotificationUpdateType string
 
 const (
-	NotificationOutBid       NotificationUpdateType = "OUTBID"
-	NotificationWon          NotificationUpdateType = "AUCTION_WON"
-	NotificationReminder     NotificationUpdateType = "REMINDER"
-	NotificationAuctionEnded NotificationUpdateType = "AUCTION_ENDED"
+	NotificationOutBid         NotificationUpdateType = "OUTBID"
+	NotificationWon            NotificationUpdateType = "AUCTION_WON"
+	NotificationReminder       NotificationUpdateType = "REMINDER"
+	NotificationAuctionEnded   NotificationUpdateType = "AUCTION_ENDED"
+	NotificationPriceChange    NotificationUpdateType = "PRICE_CHANGE"
+	NotificationNewListing     NotificationUpdateType = "NEW_LISTING"
+	NotificationRefund         NotificationUpdateType = "REFUND"
+	NotificationFundsAvailable NotificationUpdateType = "FUNDS_AVAILABLE"
+	NotificationOrderUpdate    NotificationUpdateType = "ORDER_UPDATE"
+	NotificationDisputeUpdate  NotificationUpdateType = "DISPUTE_UPDATE"
+	NotificationFeedback       NotificationUpdateType = "FEEDBACK"
 )
 
 type NotificationEvent struct {
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Feedback is the rating one party of a completed order leaves the other.
+// Role is the part the rated user played in the order.
+type Feedback struct {
+	ID         string     `json:"id"`
+	OrderID    string     `json:"order_id"`
+	AuctionID  string     `json:"auction_id"`
+	FromUserID string     `json:"from_user_id"`
+	ToUserID   string     `json:"to_user_id"`
+	Role       string     `json:"role"`   // buyer, seller
+	Rating     string     `json:"rating"` // positive, neutral, negative
+	Comment    string     `json:"comment,omitempty"`
+	Reply      string     `json:"reply,omitempty"` // the seller's public reply
+	RepliedAt  *time.Time `json:"replied_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+const (
+	FeedbackPositive = "positive"
+	FeedbackNeutral  = "neutral"
+	FeedbackNegative = "negative"
+)
+
+const (
+	FeedbackRoleBuyer  = "buyer"
+	FeedbackRoleSeller = "seller"
+)
+
+type FeedbackRequest struct {
+	Rating  string `json:"rating" binding:"required,oneof=positive neutral negative"`
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+type FeedbackReplyRequest struct {
+	Reply string `json:"reply" binding:"required,max=1000"`
+}
+
+// FeedbackScore tallies the ratings a user received in one role. Score is
+// positive minus negative ratings; PositivePercent leaves neutral ratings out.
+type FeedbackScore struct {
+	Positive        int     `json:"positive"`
+	Neutral         int     `json:"neutral"`
+	Negative        int     `json:"negative"`
+	Score           int     `json:"score"`
+	PositivePercent float64 `json:"positive_percent"`
+}
+
+// UserFeedback is a user's reputation as a seller and as a buyer
+type UserFeedback struct {
+	AsSeller FeedbackScore `json:"as_seller"`
+	AsBuyer  FeedbackScore `json:"as_buyer"`
+}
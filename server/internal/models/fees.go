@@ -0,0 +1,27 @@
+package models
+
+type FeePreviewRequest struct {
+	Price    float64 `form:"price" binding:"required,gt=0"`
+	Category string  `form:"category" binding:"required,oneof=mobile pc accessories"`
+}
+
+// FeePreview is what a sale at a given price costs the buyer and earns the
+// seller, before any tax charged on the buyer's side
+type FeePreview struct {
+	Price          float64         `json:"price"`
+	Category       string          `json:"category"`
+	Currency       string          `json:"currency"`
+	BuyerPremium   float64         `json:"buyer_premium"`
+	BuyerPays      float64         `json:"buyer_pays"`
+	SellerFee      float64         `json:"seller_fee"`
+	SellerFeeTiers []FeeTierCharge `json:"seller_fee_tiers"`
+	SellerFeeFixed float64         `json:"seller_fee_fixed"`
+	NetProceeds    float64         `json:"net_proceeds"`
+}
+
+// FeeTierCharge is the seller fee charged on one price band
+type FeeTierCharge struct {
+	UpTo    float64 `json:"up_to,omitempty"` // omitted for the open-ended top tier
+	Percent float64 `json:"percent"`
+	Amount  float64 `json:"amount"`
+}
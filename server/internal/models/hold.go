@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// BidHold is a card pre-authorization a bidder needs before bidding at or
+// above an auction's HoldThreshold
+type BidHold struct {
+	ID             string     `json:"id"`
+	AuctionID      string     `json:"auction_id"`
+	BidderID       string     `json:"bidder_id"`
+	Amount         float64    `json:"amount"`
+	Provider       string     `json:"provider"`
+	ProviderHoldID string     `json:"-"`
+	Status         string     `json:"status"` // pending, authorized, captured, released
+	AuthorizedAt   *time.Time `json:"authorized_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+const (
+	HoldPending    = "pending"
+	HoldAuthorized = "authorized"
+	HoldCaptured   = "captured"
+	HoldReleased   = "released"
+)
+
+// BidHoldResponse is a hold with the page where the bidder authorizes it
+type BidHoldResponse struct {
+	*BidHold
+	AuthorizeURL string     `json:"authorize_url,omitempty"` // only while pending
+	ValidUntil   *time.Time `json:"valid_until,omitempty"`   // only while authorized
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Invoice is the numbered invoice of a completed payment. Numbers are gapless
+// per year (INV-2025-000001, INV-2025-000002, ...).
+type Invoice struct {
+	ID               string        `json:"id"`
+	Number           string        `json:"number"`
+	PaymentID        string        `json:"payment_id"`
+	AuctionID        string        `json:"auction_id"`
+	OrderID          string        `json:"order_id"`
+	BuyerID          string        `json:"buyer_id"`
+	BuyerName        string        `json:"buyer_name"`
+	BuyerEmail       string        `json:"buyer_email"`
+	SellerID         string        `json:"seller_id"`
+	SellerName       string        `json:"seller_name"`
+	SellerEmail      string        `json:"seller_email"`
+	AuctionTitle     string        `json:"auction_title"`
+	Currency         string        `json:"currency"`
+	Lines            []InvoiceLine `json:"lines"`
+	Subtotal         float64       `json:"subtotal"`
+	Fees             float64       `json:"fees"`
+	Shipping         float64       `json:"shipping"`
+	Tax              float64       `json:"tax"`
+	Total            float64       `json:"total"`
+	PaymentReference string        `json:"payment_reference"`
+	IssuedAt         time.Time     `json:"issued_at"`
+}
+
+type InvoiceLine struct {
+	Kind        string  `json:"kind"` // item, fee, shipping, tax
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+const (
+	InvoiceLineItem     = "item"
+	InvoiceLineFee      = "fee"
+	InvoiceLineShipping = "shipping"
+	InvoiceLineTax      = "tax"
+)
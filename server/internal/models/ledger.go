@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// LedgerEntry is a line in a seller's ledger. Amounts are signed: sales credit
+// the seller, refunds and payouts debit them.
+type LedgerEntry struct {
+	ID          string    `json:"id"`
+	SellerID    string    `json:"seller_id"`
+	Kind        string    `json:"kind"` // sale, refund, payout, payout_reversal
+	Amount      float64   `json:"amount"`
+	Gross       float64   `json:"gross,omitempty"`
+	PlatformFee float64   `json:"platform_fee,omitempty"`
+	Status      string    `json:"status"` // held, available
+	PaymentID   *string   `json:"payment_id,omitempty"`
+	AuctionID   *string   `json:"auction_id,omitempty"`
+	AvailableAt time.Time `json:"available_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+const (
+	LedgerSale           = "sale"
+	LedgerRefund         = "refund"
+	LedgerPayout         = "payout"
+	LedgerPayoutReversal = "payout_reversal"
+
+	LedgerHeld      = "held"
+	LedgerAvailable = "available"
+)
+
+// Balance is what a seller is owed: held funds wait for the buyer to confirm
+// receipt or for the hold period to pass, available funds can be paid out
+type Balance struct {
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+	PaidOut   float64 `json:"paid_out"`
+	Currency  string  `json:"currency"`
+}
+
+type Payout struct {
+	ID               string     `json:"id"`
+	SellerID         string     `json:"seller_id"`
+	Amount           float64    `json:"amount"`
+	Status           string     `json:"status"` // pending, paid, failed
+	Provider         string     `json:"provider"`
+	ProviderPayoutID string     `json:"provider_payout_id,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+}
+
+const (
+	PayoutPending = "pending"
+	PayoutPaid    = "paid"
+	PayoutFailed  = "failed"
+)
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Order tracks the fulfilment of a paid auction. Its ID is the order ID of
+// the checkout that paid for it.
+type Order struct {
+	ID             string     `json:"id"`
+	PaymentID      string     `json:"payment_id"`
+	AuctionID      string     `json:"auction_id"`
+	BuyerID        string     `json:"buyer_id"`
+	SellerID       string     `json:"seller_id"`
+	Status         string     `json:"status"` // awaiting_shipment, shipped, delivered, completed, disputed, cancelled
+	ShippingMethod string     `json:"shipping_method,omitempty"`
+	Carrier        string     `json:"carrier,omitempty"`
+	TrackingNumber string     `json:"tracking_number,omitempty"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	DisputedAt     *time.Time `json:"disputed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+const (
+	OrderAwaitingShipment = "awaiting_shipment"
+	OrderShipped          = "shipped"
+	OrderDelivered        = "delivered"
+	OrderCompleted        = "completed"
+	OrderDisputed         = "disputed"
+	OrderCancelled        = "cancelled" // refunded in full through a dispute
+)
+
+type ShipOrderRequest struct {
+	Carrier        string `json:"carrier" binding:"required,max=64"`
+	TrackingNumber string `json:"tracking_number" binding:"required,max=64"`
+}
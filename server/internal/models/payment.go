@@ -7,17 +7,77 @@ type Payment struct {
 	AuctionID string    `json:"auction_id"`
 	BuyerID   string    `json:"buyer_id"`
 	OrderID   string    `json:"order_id"`
-	Amount    float64   `json:"amount"`
-	Status    string    `json:"status"` // pending, completed, failed
+	Amount    float64   `json:"amount"` // total charged, fee included
+	Fee       float64   `json:"fee"`
+	Status    string    `json:"status"` // pending, completed, failed, refunded, partially_refunded
 	SessionID string    `json:"session_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	RefundedAmount  float64   `json:"refunded_amount"`
+	PaymentIntentID string    `json:"-"`
+	Tax             float64   `json:"tax"`
+	TaxLines        []TaxLine `json:"tax_lines"`
+	Deposit         float64   `json:"deposit"` // captured bid hold deducted from the amount charged
+	Shipping        float64   `json:"shipping"`
+	ShippingMethod  string    `json:"shipping_method,omitempty"`
+}
+
+// TaxLine is the tax of one tax rule charged on a payment
+type TaxLine struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"` // percentage
+	Amount float64 `json:"amount"`
+}
+
+// PaymentStatusResponse is the status of a payment as shown to its buyer, the
+// seller of the auction or an admin
+type PaymentStatusResponse struct {
+	OrderID        string    `json:"order_id"`
+	AuctionID      string    `json:"auction_id"`
+	Status         string    `json:"status"`
+	Amount         float64   `json:"amount"`
+	Fee            float64   `json:"fee"`
+	Tax            float64   `json:"tax"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	IsPaid         bool      `json:"is_paid"`                   // the auction's paid flag
+	CheckoutStatus string    `json:"checkout_status,omitempty"` // the provider's session status while the payment is pending
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PaymentRefund is a full or partial refund of a payment
+type PaymentRefund struct {
+	ID               string    `json:"id"`
+	PaymentID        string    `json:"payment_id"`
+	ProviderRefundID string    `json:"provider_refund_id,omitempty"`
+	Amount           float64   `json:"amount"`
+	Reason           string    `json:"reason"`
+	Status           string    `json:"status"`                 // pending, succeeded, failed
+	RequestedBy      *string   `json:"requested_by,omitempty"` // nil when refunded outside the API, e.g. in the provider dashboard
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
-type CreatePaymentRequest struct {
-	Amount int64 `json:"amount" binding:"required"`
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// CreateRefundRequest refunds amount of a payment, or everything not yet refunded when amount is omitted
+type CreateRefundRequest struct {
+	Amount float64 `json:"amount,omitempty"`
+	Reason string  `json:"reason" binding:"required"`
 }
 
+// CreatePaymentResponse carries the checkout link and the server-computed amount
 type CreatePaymentResponse struct {
-	CheckoutURL string `json:"checkout_url"`
+	CheckoutURL string  `json:"checkout_url"`
+	OrderID     string  `json:"order_id"`
+	Price       float64 `json:"price"`
+	Fee         float64 `json:"fee"`
+	Tax         float64 `json:"tax"`
+	Deposit     float64 `json:"deposit,omitempty"` // captured from the winner's bid hold and deducted from the total
+	Shipping    float64 `json:"shipping,omitempty"`
+	Total       float64 `json:"total"`
 }
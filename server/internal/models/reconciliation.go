@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// PaymentMismatch is a difference the reconciliation worker found between a
+// local payment (or its auction's is_paid flag) and the payment provider, and
+// the action taken to resolve it
+type PaymentMismatch struct {
+	ID             string    `json:"id"`
+	PaymentID      *string   `json:"payment_id,omitempty"`
+	AuctionID      string    `json:"auction_id"`
+	SessionID      string    `json:"session_id,omitempty"`
+	LocalStatus    string    `json:"local_status"`
+	ProviderStatus string    `json:"provider_status,omitempty"`
+	Action         string    `json:"action"` // completed, failed, expired, is_paid_fixed, unresolved
+	Detail         string    `json:"detail,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+const (
+	MismatchCompleted   = "completed"     // paid at the provider, the payment was completed
+	MismatchFailed      = "failed"        // the session expired at the provider, the payment was failed
+	MismatchExpired     = "expired"       // the checkout was abandoned, its session was expired and the payment failed
+	MismatchIsPaidFixed = "is_paid_fixed" // the auction's is_paid flag disagreed with its payments
+	MismatchUnresolved  = "unresolved"    // the provider could not be asked or the fix failed
+)
+
+func IsValidMismatchAction(action string) bool {
+	switch action {
+	case MismatchCompleted, MismatchFailed, MismatchExpired, MismatchIsPaidFixed, MismatchUnresolved:
+		return true
+	}
+	return false
+}
+
+// ReconciliationReport summarizes a reconciliation run
+type ReconciliationReport struct {
+	Checked    int                `json:"checked"`
+	Mismatches []*PaymentMismatch `json:"mismatches"`
+}
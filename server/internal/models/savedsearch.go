@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// SavedSearch is a named AuctionFilter a user gets alerted about when new auctions match it
+type SavedSearch struct {
+	ID                string        `json:"id"`
+	UserID            string        `json:"user_id"` // FK to User.ID
+	Name              string        `json:"name"`
+	Filter            AuctionFilter `json:"filter"`
+	NotifyNewListings bool          `json:"notify_new_listings"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+type SavedSearchRequest struct {
+	Name              string  `json:"name" binding:"required,min=1,max=64"`
+	Type              string  `json:"type" binding:"omitempty,oneof=english dutch sealed"`
+	Category          string  `json:"category" binding:"omitempty,oneof=mobile pc accessories"`
+	Status            string  `json:"status" binding:"omitempty,oneof=open closed"`
+	MinPrice          float64 `json:"min_price" binding:"gte=0"`
+	MaxPrice          float64 `json:"max_price" binding:"gte=0"`
+	Keyword           string  `json:"keyword" binding:"max=100"`
+	NotifyNewListings *bool   `json:"notify_new_listings"`
+}
+
+type SavedSearchAlertsRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
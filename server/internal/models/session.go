@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Session is a login. Its refresh tokens form a family: each refresh uses the
+// current token up and issues the next one, and revoking the session ends them
+// all. UserAgent and IPAddress are from the latest login or refresh.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Current    bool       `json:"current"` // the session of the refresh token cookie sent with the request
+}
+
+// RefreshToken is one link in a session's chain of refresh tokens
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string // the session's ID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time // when it was exchanged for the next token
+	CreatedAt time.Time
+}
+
+// Client identifies where a login or refresh came from
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
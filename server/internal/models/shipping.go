@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Address is an entry in a user's address book
+type Address struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Label      string    `json:"label"` // e.g. "Home", "Work"
+	FullName   string    `json:"full_name"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type AddressRequest struct {
+	Label      string `json:"label" binding:"max=32"`
+	FullName   string `json:"full_name" binding:"required,max=100"`
+	Line1      string `json:"line1" binding:"required,max=200"`
+	Line2      string `json:"line2" binding:"max=200"`
+	City       string `json:"city" binding:"required,max=100"`
+	Region     string `json:"region" binding:"max=100"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"required,max=56"`
+	Phone      string `json:"phone" binding:"max=32"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+// Shipping methods a seller can offer on an auction
+const (
+	ShippingFlat   = "flat"
+	ShippingFree   = "free"
+	ShippingPickup = "pickup"
+	ShippingWeight = "weight"
+)
+
+// ShippingOption is one way a seller offers to get an item to the winner
+type ShippingOption struct {
+	Method string       `json:"method" binding:"required,oneof=flat free pickup weight"`
+	Price  float64      `json:"price,omitempty" binding:"gte=0"`  // flat rate only
+	Rates  []WeightRate `json:"rates,omitempty" binding:"dive"`   // weight-based only
+	Note   string       `json:"note,omitempty" binding:"max=200"` // e.g. where to pick up
+}
+
+// WeightRate prices parcels up to a weight; the last rate may leave UpToKg at 0
+// to cover anything heavier
+type WeightRate struct {
+	UpToKg float64 `json:"up_to_kg" binding:"gte=0"`
+	Price  float64 `json:"price" binding:"gte=0"`
+}
+
+// ShippingSelection is how the winner of an auction wants it delivered. The
+// address is copied when chosen so later address book edits don't change
+// where the item goes.
+type ShippingSelection struct {
+	AuctionID string    `json:"auction_id"`
+	BuyerID   string    `json:"buyer_id"`
+	Method    string    `json:"method"`
+	Cost      float64   `json:"cost"`
+	Address   *Address  `json:"address,omitempty"` // nil for pickup, and hidden from the seller until the auction is paid
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ShippingSelectionRequest struct {
+	Method    string `json:"method" binding:"required,oneof=flat free pickup weight"`
+	AddressID string `json:"address_id"` // required unless picking up
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TwoFactor is a user's TOTP enrollment. Secret is sealed; the enrollment
+// only counts once it is confirmed with a code and EnabledAt is set.
+type TwoFactor struct {
+	UserID    string
+	Secret    string
+	EnabledAt *time.Time
+	LastStep  int64 // the last TOTP time step a code was accepted for
+	CreatedAt time.Time
+}
+
+// TwoFactorSetupResponse is what an authenticator app is enrolled with. The
+// URI is usually shown as a QR code and the secret typed in by hand.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse lists recovery codes; they are shown this once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorCodeRequest carries a code from the authenticator app or, where
+// allowed, a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge token
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
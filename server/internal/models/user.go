@@ -13,6 +13,8 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	IsAdmin   bool      `json:"is_admin"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -31,6 +33,9 @@ type UserResponse struct {
 	FullName  string    `json:"full_name"`
 	Location  string    `json:"location"`
 	CreatedAt time.Time `json:"created_at"`
+
+	EmailVerified bool          `json:"email_verified"`
+	Feedback      *UserFeedback `json:"feedback,omitempty"`
 }
 
 type UserProfileUpdateRequest struct {
@@ -45,11 +50,16 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse carries the tokens of a new session. For users with two-factor
+// authentication it only carries a ChallengeToken, which POST /login/2fa
+// exchanges for the tokens along with a code.
 type LoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	ID           string `json:"id"`
-	Username     string `json:"username"`
+	Token             string `json:"token"`
+	RefreshToken      string `json:"refresh_token"`
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type PasswordUpdateRequest struct {
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Watch represents a user following an auction without bidding
+type Watch struct {
+	ID                string    `json:"id"`
+	UserID            string    `json:"user_id"`    // FK to User.ID
+	AuctionID         string    `json:"auction_id"` // FK to Auction.ID
+	NotifyPriceChange bool      `json:"notify_price_change"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type WatchAuctionRequest struct {
+	NotifyPriceChange bool `json:"notify_price_change"`
+}
+
+type WatchResponse struct {
+	AuctionID         string    `json:"auction_id"`
+	NotifyPriceChange bool      `json:"notify_price_change"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// WatchReminder is a watcher whose auction is about to end, with the lead
+// times they were already reminded for
+type WatchReminder struct {
+	WatchID       string
+	UserID        string
+	AuctionID     string
+	Title         string
+	EndTime       time.Time
+	SentLeadTimes []time.Duration
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is the log entry of a verified payment provider webhook delivery
+type WebhookEvent struct {
+	ID          string          `json:"id"`
+	Provider    string          `json:"provider"`
+	EventID     string          `json:"event_id"`
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status"` // processing, processed, ignored, failed
+	Error       string          `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	ReceivedAt  time.Time       `json:"received_at"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+const (
+	WebhookEventProcessing = "processing"
+	WebhookEventProcessed  = "processed"
+	WebhookEventIgnored    = "ignored"
+	WebhookEventFailed     = "failed"
+)
+
+func IsValidWebhookEventStatus(status string) bool {
+	switch status {
+	case WebhookEventProcessing, WebhookEventProcessed, WebhookEventIgnored, WebhookEventFailed:
+		return true
+	}
+	return false
+}
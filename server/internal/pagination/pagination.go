@@ -0,0 +1,129 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Cursor is the opaque position a client passes back to fetch the next page.
+// Lists ordered by a timestamp resume after (At, ID); orderings that cannot be
+// expressed as a keyset (rank, price, bid count) carry an Offset instead.
+type Cursor struct {
+	At     time.Time `json:"at,omitzero"`
+	ID     string    `json:"id,omitempty"`
+	Offset int       `json:"off,omitempty"`
+}
+
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.Offset < 0 || (c.ID == "") != c.At.IsZero() {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Params is a decoded page request
+type Params struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// Parse reads the limit and cursor query values. An empty limit falls back to
+// DefaultLimit and anything above MaxLimit is capped.
+func Parse(limit, cursor string) (*Params, error) {
+
+	p := &Params{Limit: DefaultLimit}
+
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return nil, errs.ErrInvalidPageSize
+		}
+		p.Limit = min(l, MaxLimit)
+	}
+
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		p.Cursor = c
+	}
+
+	return p, nil
+}
+
+// After returns the keyset position to resume from, if the cursor has one
+func (p *Params) After() (time.Time, string, bool) {
+	if p.Cursor == nil || p.Cursor.ID == "" {
+		return time.Time{}, "", false
+	}
+	return p.Cursor.At, p.Cursor.ID, true
+}
+
+func (p *Params) Offset() int {
+	if p.Cursor == nil {
+		return 0
+	}
+	return p.Cursor.Offset
+}
+
+// Fetch is the number of rows to query: one more than the page size so the
+// caller can tell whether a next page exists
+func (p *Params) Fetch() int {
+	return p.Limit + 1
+}
+
+// Keyset builds the cursor resuming after an item ordered by (at, id)
+func Keyset(at time.Time, id string) *Cursor {
+	return &Cursor{At: at, ID: id}
+}
+
+// NextOffset builds the cursor for the page after this one when paging by offset
+func (p *Params) NextOffset() *Cursor {
+	return &Cursor{Offset: p.Offset() + p.Limit}
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// NewPage drops the look-ahead row fetched by Params.Fetch and, when there was
+// one, sets NextCursor from the last item kept on the page
+func NewPage[T any](items []T, p *Params, total int, next func(last T) *Cursor) *Page[T] {
+
+	page := &Page[T]{Items: items, Total: total}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(page.Items) > p.Limit {
+		page.Items = page.Items[:p.Limit]
+		page.NextCursor = next(page.Items[p.Limit-1]).Encode()
+	}
+
+	return page
+}
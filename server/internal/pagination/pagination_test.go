@@ -0,0 +1,62 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultLimit, p.Limit)
+	assert.Nil(t, p.Cursor)
+
+	p, err = Parse("1000", "")
+	assert.NoError(t, err)
+	assert.Equal(t, MaxLimit, p.Limit)
+
+	_, err = Parse("0", "")
+	assert.ErrorIs(t, err, errs.ErrInvalidPageSize)
+
+	_, err = Parse("ten", "")
+	assert.ErrorIs(t, err, errs.ErrInvalidPageSize)
+
+	_, err = Parse("", "not-a-cursor!")
+	assert.ErrorIs(t, err, errs.ErrInvalidCursor)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 30, 0, 123456000, time.UTC)
+
+	p, err := Parse("5", Keyset(at, "a1").Encode())
+	assert.NoError(t, err)
+
+	gotAt, gotID, ok := p.After()
+	assert.True(t, ok)
+	assert.True(t, at.Equal(gotAt))
+	assert.Equal(t, "a1", gotID)
+	assert.Equal(t, 0, p.Offset())
+
+	p, err = Parse("5", p.NextOffset().Encode())
+	assert.NoError(t, err)
+	_, _, ok = p.After()
+	assert.False(t, ok)
+	assert.Equal(t, 5, p.Offset())
+}
+
+func TestNewPage(t *testing.T) {
+	p := &Params{Limit: 2}
+	next := func(last int) *Cursor { return &Cursor{Offset: last} }
+
+	page := NewPage([]int{1, 2, 3}, p, 3, next)
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.Equal(t, (&Cursor{Offset: 2}).Encode(), page.NextCursor)
+	assert.Equal(t, 3, page.Total)
+
+	page = NewPage[int](nil, p, 0, next)
+	assert.Equal(t, []int{}, page.Items)
+	assert.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,437 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ PaymentProvider = (*FakePayment)(nil)
+
+const (
+	FakeSignatureHeader = "Fake-Signature"
+	fakeSignatureMaxAge = 5 * time.Minute
+)
+
+var (
+	ErrFakeSessionNotFound  = errors.New("fake checkout session not found")
+	ErrFakeSessionNotOpen   = errors.New("fake checkout session is not open")
+	ErrFakeSessionNotPaid   = errors.New("fake checkout session has not been paid")
+	ErrFakeInvalidSignature = errors.New("invalid fake webhook signature")
+	ErrFakeRefundTooLarge   = errors.New("refund exceeds the amount left to refund")
+	ErrFakeHoldNotFound     = errors.New("fake hold not found")
+)
+
+// FakePayment is an in-memory provider for development and CI. Its checkout
+// URLs point at pages served by the API itself (/fake-checkout/{sessionID});
+// paying or declining there sends signed webhooks to /webhook/fake the same
+// way a real provider would, so the whole purchase flow runs without Stripe.
+// Sessions live in memory and are lost on restart.
+type FakePayment struct {
+	BaseURL       string // public base of the API, e.g. http://localhost:8080/api/v1
+	WebhookSecret string
+	CancelURL     string
+	SuccessURL    string
+
+	client   *http.Client
+	mu       sync.Mutex
+	sessions map[string]*fakeSession
+}
+
+type fakeSession struct {
+	session   CheckoutSession
+	lineItems []LineItem
+	refunded  int64
+
+	// set for bid holds, which authorize on the same pages without any webhooks
+	hold      HoldStatus
+	returnURL string
+}
+
+func NewFakePayment(baseURL, webhookSecret, cancelURL, successURL string) *FakePayment {
+	return &FakePayment{
+		BaseURL:       strings.TrimSuffix(baseURL, "/"),
+		WebhookSecret: webhookSecret,
+		CancelURL:     cancelURL,
+		SuccessURL:    successURL,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		sessions:      map[string]*fakeSession{},
+	}
+}
+
+func (f *FakePayment) Name() string {
+	return "fake"
+}
+
+func (f *FakePayment) CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error) {
+
+	id := fakeID("cs_fake")
+
+	var total int64
+	for _, item := range req.LineItems {
+		total += item.Amount
+	}
+
+	s := &fakeSession{
+		session: CheckoutSession{
+			ID:            id,
+			URL:           f.BaseURL + "/fake-checkout/" + id,
+			Status:        SessionOpen,
+			PaymentStatus: PaymentStatusUnpaid,
+			AmountTotal:   total,
+			Currency:      DefaultCurrency,
+			Metadata:      req.Metadata(),
+		},
+		lineItems: req.LineItems,
+	}
+
+	f.mu.Lock()
+	f.sessions[id] = s
+	f.mu.Unlock()
+
+	res := s.session
+	return &res, nil
+}
+
+func (f *FakePayment) GetSession(ctx context.Context, sessionID string) (*CheckoutSession, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, ErrFakeSessionNotFound
+	}
+
+	res := s.session
+	return &res, nil
+}
+
+// CheckoutPage returns what the fake checkout page shows for a session and
+// where its cancel link goes
+func (f *FakePayment) CheckoutPage(sessionID string) (*CheckoutSession, []LineItem, string, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, nil, "", ErrFakeSessionNotFound
+	}
+
+	cancelURL := f.CancelURL
+	if s.hold != "" {
+		cancelURL = s.returnURL
+	}
+
+	res := s.session
+	return &res, s.lineItems, cancelURL, nil
+}
+
+func (f *FakePayment) ExpireSession(ctx context.Context, sessionID string) error {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return ErrFakeSessionNotFound
+	}
+
+	if s.session.Status != SessionOpen {
+		return ErrFakeSessionNotOpen
+	}
+
+	s.session.Status = SessionExpired
+	return nil
+}
+
+// Pay completes an open session as if the buyer paid, delivers the
+// checkout.session.completed and payment_intent.succeeded webhooks and returns
+// the success page URL to send the buyer to
+func (f *FakePayment) Pay(ctx context.Context, sessionID string) (string, error) {
+
+	f.mu.Lock()
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		f.mu.Unlock()
+		return "", ErrFakeSessionNotFound
+	}
+	if s.session.Status != SessionOpen {
+		f.mu.Unlock()
+		return "", ErrFakeSessionNotOpen
+	}
+	s.session.Status = SessionComplete
+	s.session.PaymentIntentID = fakeID("pi_fake")
+
+	if s.hold != "" {
+		// only authorized, the bidder is charged if the hold is captured
+		s.hold = HoldAuthorized
+		f.mu.Unlock()
+		return s.returnURL, nil
+	}
+
+	s.session.PaymentStatus = PaymentStatusPaid
+	paid := s.session
+	f.mu.Unlock()
+
+	events := []*WebhookEvent{
+		{ID: fakeID("evt_fake"), Type: EventCheckoutCompleted, SessionID: paid.ID, PaymentIntentID: paid.PaymentIntentID, PaymentStatus: PaymentStatusPaid, Metadata: paid.Metadata},
+		{ID: fakeID("evt_fake"), Type: EventPaymentSucceeded, PaymentIntentID: paid.PaymentIntentID, Metadata: paid.Metadata},
+	}
+
+	for _, event := range events {
+		if err := f.deliver(ctx, event); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.ReplaceAll(f.SuccessURL, "{CHECKOUT_SESSION_ID}", paid.ID), nil
+}
+
+// Decline simulates a declined card: the session stays open and a
+// payment_intent.payment_failed webhook is delivered
+func (f *FakePayment) Decline(ctx context.Context, sessionID string) (string, error) {
+
+	f.mu.Lock()
+	held, ok := f.sessions[sessionID]
+	if !ok {
+		f.mu.Unlock()
+		return "", ErrFakeSessionNotFound
+	}
+	s, hold, returnURL := held.session, held.hold, held.returnURL
+	f.mu.Unlock()
+
+	if s.Status != SessionOpen {
+		return "", ErrFakeSessionNotOpen
+	}
+
+	if hold != "" {
+		return returnURL, nil
+	}
+
+	event := &WebhookEvent{ID: fakeID("evt_fake"), Type: EventPaymentFailed, PaymentIntentID: fakeID("pi_fake"), Metadata: s.Metadata}
+	if err := f.deliver(ctx, event); err != nil {
+		return "", err
+	}
+
+	return f.CancelURL, nil
+}
+
+func (f *FakePayment) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+
+	if err := f.verify(payload, header.Get(FakeSignatureHeader), time.Now()); err != nil {
+		return nil, err
+	}
+
+	event := &WebhookEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (f *FakePayment) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[req.SessionID]
+	if !ok {
+		return nil, ErrFakeSessionNotFound
+	}
+
+	if s.session.Status != SessionComplete || (s.hold != "" && s.hold != HoldCaptured) {
+		return nil, ErrFakeSessionNotPaid
+	}
+
+	if req.Amount <= 0 || s.refunded+req.Amount > s.session.AmountTotal {
+		return nil, ErrFakeRefundTooLarge
+	}
+
+	s.refunded += req.Amount
+
+	// like a real provider, the refund is also announced asynchronously
+	event := &WebhookEvent{ID: fakeID("evt_fake"), Type: EventChargeRefunded, PaymentIntentID: s.session.PaymentIntentID, AmountRefunded: s.refunded, Metadata: s.session.Metadata}
+	go func() {
+		if err := f.deliver(context.Background(), event); err != nil {
+			log.Printf("failed to deliver fake %s webhook: %v", event.Type, err)
+		}
+	}()
+
+	return &Refund{ID: fakeID("re_fake"), Amount: req.Amount, Status: "succeeded"}, nil
+}
+
+func (f *FakePayment) CreateHold(ctx context.Context, req *HoldRequest) (*Hold, error) {
+
+	id := fakeID("cs_fake")
+
+	s := &fakeSession{
+		session: CheckoutSession{
+			ID:            id,
+			URL:           f.BaseURL + "/fake-checkout/" + id,
+			Status:        SessionOpen,
+			PaymentStatus: PaymentStatusUnpaid,
+			AmountTotal:   req.Amount,
+			Currency:      DefaultCurrency,
+			Metadata:      req.Metadata(),
+		},
+		lineItems: []LineItem{{Name: req.Name, Amount: req.Amount}},
+		hold:      HoldPending,
+		returnURL: req.ReturnURL,
+	}
+
+	f.mu.Lock()
+	f.sessions[id] = s
+	f.mu.Unlock()
+
+	return s.toHold(), nil
+}
+
+func (f *FakePayment) GetHold(ctx context.Context, holdID string) (*Hold, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[holdID]
+	if !ok || s.hold == "" {
+		return nil, ErrFakeHoldNotFound
+	}
+
+	return s.toHold(), nil
+}
+
+func (f *FakePayment) CaptureHold(ctx context.Context, holdID string) (*Hold, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[holdID]
+	if !ok || s.hold == "" {
+		return nil, ErrFakeHoldNotFound
+	}
+
+	if s.hold == HoldAuthorized {
+		s.hold = HoldCaptured
+		s.session.PaymentStatus = PaymentStatusPaid
+	}
+
+	return s.toHold(), nil
+}
+
+func (f *FakePayment) ReleaseHold(ctx context.Context, holdID string) error {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[holdID]
+	if !ok || s.hold == "" {
+		return ErrFakeHoldNotFound
+	}
+
+	switch s.hold {
+	case HoldPending:
+		s.session.Status = SessionExpired
+		s.hold = HoldReleased
+	case HoldAuthorized:
+		s.hold = HoldReleased
+	}
+
+	return nil
+}
+
+func (s *fakeSession) toHold() *Hold {
+	return &Hold{
+		ID:              s.session.ID,
+		URL:             s.session.URL,
+		Status:          s.hold,
+		PaymentIntentID: s.session.PaymentIntentID,
+		Amount:          s.session.AmountTotal,
+	}
+}
+
+// deliver posts a signed event to the API's own webhook endpoint
+func (f *FakePayment) deliver(ctx context.Context, event *WebhookEvent) error {
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.BaseURL+"/webhook/"+f.Name(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(FakeSignatureHeader, f.sign(payload, time.Now()))
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("fake webhook %s rejected with status %d", event.Type, res.StatusCode)
+	}
+
+	return nil
+}
+
+// sign produces a Stripe style "t=<unix>,v1=<hex hmac>" header over "<unix>.<payload>"
+func (f *FakePayment) sign(payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(f.mac(ts, payload))
+}
+
+func (f *FakePayment) verify(payload []byte, header string, now time.Time) error {
+
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || now.Sub(time.Unix(unix, 0)).Abs() > fakeSignatureMaxAge {
+		return ErrFakeInvalidSignature
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, f.mac(ts, payload)) {
+		return ErrFakeInvalidSignature
+	}
+
+	return nil
+}
+
+func (f *FakePayment) mac(ts string, payload []byte) []byte {
+	m := hmac.New(sha256.New, []byte(f.WebhookSecret))
+	m.Write([]byte(ts + "."))
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+func fakeID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,108 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeWebhookSignature(t *testing.T) {
+	f := NewFakePayment("http://localhost:8080/api/v1", "whsec", "", "")
+
+	payload, _ := json.Marshal(&WebhookEvent{ID: "evt_1", Type: EventPaymentSucceeded, Metadata: map[string]string{"order_id": "o1"}})
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, f.sign(payload, time.Now()))
+
+	event, err := f.ParseWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, EventPaymentSucceeded, event.Type)
+	assert.Equal(t, "o1", event.OrderID())
+
+	_, err = f.ParseWebhook(append(payload, ' '), header)
+	assert.ErrorIs(t, err, ErrFakeInvalidSignature)
+
+	header.Set(FakeSignatureHeader, f.sign(payload, time.Now().Add(-time.Hour)))
+	_, err = f.ParseWebhook(payload, header)
+	assert.ErrorIs(t, err, ErrFakeInvalidSignature)
+
+	other := NewFakePayment("", "other", "", "")
+	header.Set(FakeSignatureHeader, other.sign(payload, time.Now()))
+	_, err = f.ParseWebhook(payload, header)
+	assert.ErrorIs(t, err, ErrFakeInvalidSignature)
+}
+
+func TestFakeCheckoutAndRefund(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakePayment("http://localhost:8080/api/v1/", "whsec", "", "")
+
+	s, err := f.CreateCheckout(ctx, &CheckoutRequest{OrderID: "o1", LineItems: []LineItem{{Name: "Lamp", Amount: 1000}, {Name: "Buyer fee", Amount: 50}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1050), s.AmountTotal)
+	assert.Equal(t, SessionOpen, s.Status)
+	assert.Equal(t, "http://localhost:8080/api/v1/fake-checkout/"+s.ID, s.URL)
+
+	_, err = f.Refund(ctx, &RefundRequest{SessionID: s.ID, Amount: 100})
+	assert.ErrorIs(t, err, ErrFakeSessionNotPaid)
+
+	// mark paid without delivering webhooks
+	f.sessions[s.ID].session.Status = SessionComplete
+
+	_, err = f.Refund(ctx, &RefundRequest{SessionID: s.ID, Amount: 1000})
+	assert.NoError(t, err)
+	_, err = f.Refund(ctx, &RefundRequest{SessionID: s.ID, Amount: 51})
+	assert.ErrorIs(t, err, ErrFakeRefundTooLarge)
+
+	assert.ErrorIs(t, f.ExpireSession(ctx, s.ID), ErrFakeSessionNotOpen)
+	assert.ErrorIs(t, f.ExpireSession(ctx, "missing"), ErrFakeSessionNotFound)
+}
+
+func TestFakeHolds(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakePayment("http://localhost:8080/api/v1", "whsec", "", "")
+
+	h, err := f.CreateHold(ctx, &HoldRequest{HoldID: "h1", AuctionID: "a1", BidderID: "b1", Name: "Bid hold: Lamp", Amount: 5000, ReturnURL: "http://localhost/auction"})
+	assert.NoError(t, err)
+	assert.Equal(t, HoldPending, h.Status)
+	assert.Equal(t, int64(5000), h.Amount)
+
+	// authorizing redirects back without delivering any webhook
+	redirect, err := f.Pay(ctx, h.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost/auction", redirect)
+
+	h, err = f.GetHold(ctx, h.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, HoldAuthorized, h.Status)
+
+	// only a captured hold has been charged and can be refunded
+	_, err = f.Refund(ctx, &RefundRequest{SessionID: h.ID, Amount: 1000})
+	assert.ErrorIs(t, err, ErrFakeSessionNotPaid)
+
+	h, err = f.CaptureHold(ctx, h.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, HoldCaptured, h.Status)
+
+	_, err = f.Refund(ctx, &RefundRequest{SessionID: h.ID, Amount: 5000})
+	assert.NoError(t, err)
+
+	// a captured hold is no longer released
+	assert.NoError(t, f.ReleaseHold(ctx, h.ID))
+	h, _ = f.GetHold(ctx, h.ID)
+	assert.Equal(t, HoldCaptured, h.Status)
+
+	other, err := f.CreateHold(ctx, &HoldRequest{HoldID: "h2", Amount: 5000})
+	assert.NoError(t, err)
+	assert.NoError(t, f.ReleaseHold(ctx, other.ID))
+	other, _ = f.GetHold(ctx, other.ID)
+	assert.Equal(t, HoldReleased, other.Status)
+
+	s, err := f.CreateCheckout(ctx, &CheckoutRequest{OrderID: "o1", LineItems: []LineItem{{Name: "Lamp", Amount: 1000}}})
+	assert.NoError(t, err)
+	_, err = f.GetHold(ctx, s.ID)
+	assert.ErrorIs(t, err, ErrFakeHoldNotFound)
+}
@@ -0,0 +1,41 @@
+package payments
+
+import "math"
+
+// Fees are a percentage plus a flat amount, e.g. the buyer fee charged on top
+// of the winning bid at checkout or the platform fee kept from a seller's sale
+type Fees struct {
+	Percent float64 `json:"percent"` // percentage of the final price, e.g. 5 for 5%
+	Fixed   float64 `json:"fixed"`   // flat amount per order
+}
+
+// Quote is a checkout amount broken down in the smallest currency unit (cents)
+type Quote struct {
+	Price int64
+	Fee   int64
+	Total int64
+}
+
+// Quote prices a checkout for an auction that closed at finalPrice
+func (f Fees) Quote(finalPrice float64) Quote {
+
+	price := ToCents(finalPrice)
+	fee := f.On(price)
+
+	return Quote{Price: price, Fee: fee, Total: price + fee}
+}
+
+// On is the fee charged on an amount in cents
+func (f Fees) On(cents int64) int64 {
+	return int64(math.Round(float64(cents)*f.Percent/100)) + ToCents(f.Fixed)
+}
+
+// ToCents converts a dollar amount to the smallest currency unit
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromCents converts an amount in the smallest currency unit back to dollars
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
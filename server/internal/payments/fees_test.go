@@ -0,0 +1,16 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeesQuote(t *testing.T) {
+	assert.Equal(t, Quote{Price: 90050, Fee: 0, Total: 90050}, Fees{}.Quote(900.50))
+
+	// 5% of 899.99 is 44.9995, rounded to the nearest cent, plus a 1.50 flat fee
+	assert.Equal(t, Quote{Price: 89999, Fee: 4500 + 150, Total: 89999 + 4650}, Fees{Percent: 5, Fixed: 1.50}.Quote(899.99))
+
+	assert.Equal(t, 0.1, FromCents(10))
+}
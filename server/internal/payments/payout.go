@@ -0,0 +1,43 @@
+package payments
+
+import (
+	"context"
+	"log"
+)
+
+// PayoutProvider sends money owed to a seller. A Stripe Connect implementation
+// would transfer to the seller's connected account; StubPayout stands in for
+// it until sellers can onboard.
+type PayoutProvider interface {
+	Name() string
+	Payout(ctx context.Context, req *PayoutRequest) (*PayoutResult, error)
+}
+
+type PayoutRequest struct {
+	PayoutID string // idempotency key
+	SellerID string
+	Amount   int64 // cents
+	Currency string
+}
+
+type PayoutResult struct {
+	ID string
+}
+
+var _ PayoutProvider = (*StubPayout)(nil)
+
+// StubPayout records payouts in the log and always succeeds
+type StubPayout struct{}
+
+func NewStubPayout() *StubPayout {
+	return &StubPayout{}
+}
+
+func (s *StubPayout) Name() string {
+	return "stub"
+}
+
+func (s *StubPayout) Payout(ctx context.Context, req *PayoutRequest) (*PayoutResult, error) {
+	log.Printf("stub payout %s: %d %s to seller %s", req.PayoutID, req.Amount, req.Currency, req.SellerID)
+	return &PayoutResult{ID: "po_stub_" + req.PayoutID}, nil
+}
@@ -0,0 +1,151 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+)
+
+// PaymentProvider is implemented by each payment processor the API can take
+// payments through. Amounts are always in the smallest currency unit (cents).
+type PaymentProvider interface {
+	// Name identifies the provider; its webhook endpoint is /webhook/{name}
+	Name() string
+	CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error)
+	GetSession(ctx context.Context, sessionID string) (*CheckoutSession, error)
+	ExpireSession(ctx context.Context, sessionID string) error
+	// ParseWebhook verifies the signature of a webhook delivery and decodes it
+	ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+	Refund(ctx context.Context, req *RefundRequest) (*Refund, error)
+
+	// CreateHold starts a card pre-authorization the bidder completes at the
+	// returned URL. Nothing is charged until the hold is captured.
+	CreateHold(ctx context.Context, req *HoldRequest) (*Hold, error)
+	GetHold(ctx context.Context, holdID string) (*Hold, error)
+	CaptureHold(ctx context.Context, holdID string) (*Hold, error)
+	// ReleaseHold cancels an authorization, or the pending hold page
+	ReleaseHold(ctx context.Context, holdID string) error
+}
+
+const DefaultCurrency = "usd"
+
+type LineItem struct {
+	Name   string
+	Amount int64
+}
+
+type CheckoutRequest struct {
+	OrderID   string
+	BuyerID   string
+	AuctionID string
+	LineItems []LineItem
+}
+
+// Metadata is attached to the checkout and echoed back on its webhook events
+func (r *CheckoutRequest) Metadata() map[string]string {
+	return map[string]string{
+		"order_id":   r.OrderID,
+		"buyer_id":   r.BuyerID,
+		"auction_id": r.AuctionID,
+	}
+}
+
+type SessionStatus string
+
+const (
+	SessionOpen     SessionStatus = "open"
+	SessionComplete SessionStatus = "complete"
+	SessionExpired  SessionStatus = "expired"
+)
+
+const (
+	PaymentStatusPaid   = "paid"
+	PaymentStatusUnpaid = "unpaid"
+)
+
+type CheckoutSession struct {
+	ID              string            `json:"id"`
+	URL             string            `json:"url,omitempty"`
+	Status          SessionStatus     `json:"status"`
+	PaymentStatus   string            `json:"payment_status"`
+	PaymentIntentID string            `json:"payment_intent,omitempty"`
+	AmountTotal     int64             `json:"amount_total"`
+	Currency        string            `json:"currency"`
+	CustomerEmail   string            `json:"customer_email,omitempty"`
+	Metadata        map[string]string `json:"metadata"`
+}
+
+type EventType string
+
+const (
+	EventCheckoutCompleted EventType = "checkout.session.completed"
+	EventPaymentSucceeded  EventType = "payment_intent.succeeded"
+	EventPaymentFailed     EventType = "payment_intent.payment_failed"
+	EventChargeRefunded    EventType = "charge.refunded"
+)
+
+// WebhookEvent is a verified provider notification reduced to what the payment
+// service acts on
+type WebhookEvent struct {
+	ID              string            `json:"id"`
+	Type            EventType         `json:"type"`
+	SessionID       string            `json:"session_id,omitempty"`
+	PaymentIntentID string            `json:"payment_intent,omitempty"`
+	PaymentStatus   string            `json:"payment_status,omitempty"`
+	AmountRefunded  int64             `json:"amount_refunded,omitempty"` // charge.refunded: total refunded so far, in cents
+	Metadata        map[string]string `json:"metadata"`
+}
+
+func (e *WebhookEvent) OrderID() string   { return e.Metadata["order_id"] }
+func (e *WebhookEvent) BuyerID() string   { return e.Metadata["buyer_id"] }
+func (e *WebhookEvent) AuctionID() string { return e.Metadata["auction_id"] }
+
+// IsHold reports whether the event belongs to a bid hold rather than a checkout
+func (e *WebhookEvent) IsHold() bool { return e.Metadata["hold_id"] != "" }
+
+type RefundRequest struct {
+	SessionID string
+	Amount    int64
+	Reason    string
+}
+
+type Refund struct {
+	ID     string
+	Amount int64
+	Status string
+}
+
+type HoldRequest struct {
+	HoldID    string
+	AuctionID string
+	BidderID  string
+	Name      string
+	Amount    int64
+	ReturnURL string // where the bidder is sent after authorizing or cancelling
+}
+
+// Metadata is attached to the hold so its webhook events can be told apart
+// from checkout payments
+func (r *HoldRequest) Metadata() map[string]string {
+	return map[string]string{
+		"hold_id":    r.HoldID,
+		"auction_id": r.AuctionID,
+		"bidder_id":  r.BidderID,
+	}
+}
+
+type HoldStatus string
+
+const (
+	HoldPending    HoldStatus = "pending"    // waiting for the bidder to authorize
+	HoldAuthorized HoldStatus = "authorized" // funds reserved on the card
+	HoldCaptured   HoldStatus = "captured"
+	HoldReleased   HoldStatus = "released" // cancelled, or never authorized
+)
+
+type Hold struct {
+	ID              string
+	URL             string
+	Status          HoldStatus
+	PaymentIntentID string
+	Amount          int64
+}
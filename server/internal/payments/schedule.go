@@ -0,0 +1,175 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Tier charges Percent on the part of the final price up to UpTo dollars that
+// the tiers before it didn't cover. The last tier has no UpTo.
+type Tier struct {
+	UpTo    float64 `json:"up_to,omitempty"`
+	Percent float64 `json:"percent"`
+}
+
+// TieredFee is a final-value fee charged per price band, plus a flat amount
+type TieredFee struct {
+	Tiers []Tier  `json:"tiers"`
+	Fixed float64 `json:"fixed"`
+}
+
+// TierCharge is what one tier of a TieredFee charged, in cents
+type TierCharge struct {
+	Tier   Tier
+	Amount int64
+}
+
+// Breakdown charges each tier on its band of the price in cents
+func (t TieredFee) Breakdown(cents int64) []TierCharge {
+	charges := []TierCharge{}
+
+	var from int64
+	for _, tier := range t.Tiers {
+		if from >= cents {
+			break
+		}
+
+		to := cents
+		if tier.UpTo > 0 {
+			to = min(ToCents(tier.UpTo), cents)
+		}
+		if to <= from {
+			continue
+		}
+
+		charges = append(charges, TierCharge{Tier: tier, Amount: int64(math.Round(float64(to-from) * tier.Percent / 100))})
+		from = to
+	}
+
+	return charges
+}
+
+// On is the fee charged on a price in cents
+func (t TieredFee) On(cents int64) int64 {
+	fee := ToCents(t.Fixed)
+	for _, charge := range t.Breakdown(cents) {
+		fee += charge.Amount
+	}
+	return fee
+}
+
+// FeeSchedule is the buyer's premium added at checkout and the final-value fee
+// kept from the seller's proceeds. Either may be left out of an override.
+type FeeSchedule struct {
+	BuyerPremium *Fees      `json:"buyer_premium,omitempty"`
+	SellerFee    *TieredFee `json:"seller_fee,omitempty"`
+}
+
+// FeeSchedules resolve the fees of a sale: a seller's override wins over its
+// category's, which wins over the default
+type FeeSchedules struct {
+	Default    FeeSchedule            `json:"default"`
+	Categories map[string]FeeSchedule `json:"categories"` // by auction category
+	Sellers    map[string]FeeSchedule `json:"sellers"`    // by seller ID
+}
+
+// For returns the buyer's premium and seller fee of a sale in the category by
+// the seller. Nil schedules charge no fees.
+func (s *FeeSchedules) For(category, sellerID string) (Fees, TieredFee) {
+	var premium Fees
+	var sellerFee TieredFee
+
+	if s == nil {
+		return premium, sellerFee
+	}
+
+	for _, schedule := range []FeeSchedule{s.Default, s.Categories[strings.ToLower(category)], s.Sellers[sellerID]} {
+		if schedule.BuyerPremium != nil {
+			premium = *schedule.BuyerPremium
+		}
+		if schedule.SellerFee != nil {
+			sellerFee = *schedule.SellerFee
+		}
+	}
+
+	return premium, sellerFee
+}
+
+func (s *FeeSchedules) validate() error {
+	check := func(name string, schedule FeeSchedule) error {
+		if p := schedule.BuyerPremium; p != nil && (p.Percent < 0 || p.Percent > 100 || p.Fixed < 0) {
+			return fmt.Errorf("%s: invalid buyer premium", name)
+		}
+		if f := schedule.SellerFee; f != nil {
+			if f.Fixed < 0 {
+				return fmt.Errorf("%s: invalid seller fee", name)
+			}
+			var upTo float64
+			for i, tier := range f.Tiers {
+				if tier.Percent < 0 || tier.Percent > 100 {
+					return fmt.Errorf("%s: seller fee tier %d has invalid percent", name, i)
+				}
+				if i < len(f.Tiers)-1 && tier.UpTo <= upTo {
+					return fmt.Errorf("%s: seller fee tiers must have increasing up_to and only the last may be open", name)
+				}
+				upTo = tier.UpTo
+			}
+		}
+		return nil
+	}
+
+	if err := check("default", s.Default); err != nil {
+		return err
+	}
+	for category, schedule := range s.Categories {
+		if err := check("category "+category, schedule); err != nil {
+			return err
+		}
+	}
+	for seller, schedule := range s.Sellers {
+		if err := check("seller "+seller, schedule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFeeSchedules reads fee schedules from a JSON file. Whatever the file
+// leaves out of its default schedule is taken from defaults; without a file
+// the defaults apply to every sale.
+func LoadFeeSchedules(path string, defaults FeeSchedule) (*FeeSchedules, error) {
+	schedules := &FeeSchedules{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fee schedules: %w", err)
+		}
+		if err := json.Unmarshal(data, schedules); err != nil {
+			return nil, fmt.Errorf("failed to parse fee schedules %s: %w", path, err)
+		}
+	}
+
+	if schedules.Default.BuyerPremium == nil {
+		schedules.Default.BuyerPremium = defaults.BuyerPremium
+	}
+	if schedules.Default.SellerFee == nil {
+		schedules.Default.SellerFee = defaults.SellerFee
+	}
+
+	categories := make(map[string]FeeSchedule, len(schedules.Categories))
+	for category, schedule := range schedules.Categories {
+		categories[strings.ToLower(category)] = schedule
+	}
+	schedules.Categories = categories
+
+	if err := schedules.validate(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
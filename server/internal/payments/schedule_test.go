@@ -0,0 +1,52 @@
+package payments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredFee(t *testing.T) {
+	fee := TieredFee{Tiers: []Tier{{UpTo: 100, Percent: 10}, {UpTo: 1000, Percent: 5}, {Percent: 2}}, Fixed: 0.30}
+
+	assert.Equal(t, int64(5_00+30), fee.On(50_00), "10% of $50 plus $0.30")
+	assert.Equal(t, int64(10_00+45_00+30), fee.On(1000_00), "$10 on the first $100, $45 on the next $900")
+	assert.Equal(t, int64(10_00+45_00+20_00+30), fee.On(2000_00), "2% above $1000")
+
+	charges := fee.Breakdown(150_00)
+	require.Len(t, charges, 2)
+	assert.Equal(t, int64(10_00), charges[0].Amount)
+	assert.Equal(t, int64(2_50), charges[1].Amount)
+}
+
+func TestFeeSchedulesFor(t *testing.T) {
+	defaults := FeeSchedule{BuyerPremium: &Fees{Percent: 5}, SellerFee: &TieredFee{Tiers: []Tier{{Percent: 10}}}}
+
+	path := filepath.Join(t.TempDir(), "fees.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"categories": {"PC": {"seller_fee": {"tiers": [{"up_to": 500, "percent": 8}, {"percent": 4}]}}},
+		"sellers": {"seller-1": {"buyer_premium": {"percent": 0}}}
+	}`), 0o600))
+
+	schedules, err := LoadFeeSchedules(path, defaults)
+	require.NoError(t, err)
+
+	premium, sellerFee := schedules.For("mobile", "seller-2")
+	assert.Equal(t, Fees{Percent: 5}, premium, "default buyer premium")
+	assert.Equal(t, int64(100_00), sellerFee.On(1000_00), "default seller fee")
+
+	premium, sellerFee = schedules.For("pc", "seller-1")
+	assert.Equal(t, Fees{Percent: 0}, premium, "seller override")
+	assert.Equal(t, int64(40_00+20_00), sellerFee.On(1000_00), "category seller fee")
+}
+
+func TestLoadFeeSchedulesRejectsBadTiers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fees.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"default": {"seller_fee": {"tiers": [{"percent": 10}, {"up_to": 100, "percent": 5}]}}}`), 0o600))
+
+	_, err := LoadFeeSchedules(path, FeeSchedule{})
+	assert.Error(t, err, "an open tier must be last")
+}
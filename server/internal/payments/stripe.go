@@ -1,26 +1,326 @@
 package payments
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/checkout/session"
+	"github.com/stripe/stripe-go/v82/paymentintent"
+	"github.com/stripe/stripe-go/v82/refund"
+	"github.com/stripe/stripe-go/v82/webhook"
 )
 
+var _ PaymentProvider = (*StripePayment)(nil)
+
 type StripePayment struct {
 	StripeSecretKey string
+	WebhookSecret   string
 	CancelURL       string
 	SuccessURL      string
 }
 
-func NewStripePayment(stripeSecretKey, cancelURL, successURL string) *StripePayment {
+func NewStripePayment(stripeSecretKey, webhookSecret, cancelURL, successURL string) *StripePayment {
 	stripe.Key = stripeSecretKey
 	stripe.SetHTTPClient(&http.Client{
 		Timeout: 10 * time.Second,
 	})
 	return &StripePayment{
 		StripeSecretKey: stripeSecretKey,
+		WebhookSecret:   webhookSecret,
 		CancelURL:       cancelURL,
 		SuccessURL:      successURL,
 	}
 }
+
+func (s *StripePayment) Name() string {
+	return "stripe"
+}
+
+func (s *StripePayment) CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error) {
+
+	lineItems := make([]*stripe.CheckoutSessionLineItemParams, 0, len(req.LineItems))
+	for _, item := range req.LineItems {
+		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(DefaultCurrency),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(item.Name),
+				},
+				UnitAmount: stripe.Int64(item.Amount),
+			},
+			Quantity: stripe.Int64(1),
+		})
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		LineItems: lineItems,
+
+		Mode:       stripe.String(stripe.CheckoutSessionModePayment),
+		SuccessURL: stripe.String(s.SuccessURL),
+		CancelURL:  stripe.String(s.CancelURL),
+
+		PaymentMethodTypes: stripe.StringSlice([]string{
+			string(stripe.PaymentMethodTypeCard),
+		}),
+
+		// the payment intent carries the same metadata so payment_intent.* events can be matched
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: req.Metadata(),
+		},
+	}
+	params.Context = ctx
+
+	for k, v := range req.Metadata() {
+		params.AddMetadata(k, v)
+	}
+
+	cs, err := session.New(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return stripeSession(cs), nil
+}
+
+func (s *StripePayment) GetSession(ctx context.Context, sessionID string) (*CheckoutSession, error) {
+
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = ctx
+
+	cs, err := session.Get(sessionID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return stripeSession(cs), nil
+}
+
+func (s *StripePayment) ExpireSession(ctx context.Context, sessionID string) error {
+
+	params := &stripe.CheckoutSessionExpireParams{}
+	params.Context = ctx
+
+	_, err := session.Expire(sessionID, params)
+	return err
+}
+
+func (s *StripePayment) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+
+	event, err := webhook.ConstructEvent(payload, header.Get("Stripe-Signature"), s.WebhookSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &WebhookEvent{ID: event.ID, Type: EventType(event.Type)}
+
+	switch res.Type {
+	case EventCheckoutCompleted:
+		var cs stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &cs); err != nil {
+			return nil, err
+		}
+		res.SessionID = cs.ID
+		res.PaymentStatus = string(cs.PaymentStatus)
+		res.Metadata = cs.Metadata
+		if cs.PaymentIntent != nil {
+			res.PaymentIntentID = cs.PaymentIntent.ID
+		}
+
+	case EventPaymentSucceeded, EventPaymentFailed:
+		var pi stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &pi); err != nil {
+			return nil, err
+		}
+		res.PaymentIntentID = pi.ID
+		res.Metadata = pi.Metadata
+
+	case EventChargeRefunded:
+		var ch stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &ch); err != nil {
+			return nil, err
+		}
+		res.AmountRefunded = ch.AmountRefunded
+		res.Metadata = ch.Metadata
+		if ch.PaymentIntent != nil {
+			res.PaymentIntentID = ch.PaymentIntent.ID
+		}
+	}
+
+	return res, nil
+}
+
+func (s *StripePayment) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+
+	cs, err := s.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(cs.PaymentIntentID),
+		Amount:        stripe.Int64(req.Amount),
+	}
+	params.Context = ctx
+	if req.Reason != "" {
+		params.AddMetadata("reason", req.Reason)
+	}
+
+	r, err := refund.New(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Refund{ID: r.ID, Amount: r.Amount, Status: string(r.Status)}, nil
+}
+
+// CreateHold uses a checkout session whose payment intent is only authorized
+// (manual capture), so the bidder enters their card on Stripe's hosted page
+func (s *StripePayment) CreateHold(ctx context.Context, req *HoldRequest) (*Hold, error) {
+
+	params := &stripe.CheckoutSessionParams{
+		LineItems: []*stripe.CheckoutSessionLineItemParams{{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(DefaultCurrency),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(req.Name),
+				},
+				UnitAmount: stripe.Int64(req.Amount),
+			},
+			Quantity: stripe.Int64(1),
+		}},
+
+		Mode:       stripe.String(stripe.CheckoutSessionModePayment),
+		SuccessURL: stripe.String(req.ReturnURL),
+		CancelURL:  stripe.String(req.ReturnURL),
+
+		PaymentMethodTypes: stripe.StringSlice([]string{
+			string(stripe.PaymentMethodTypeCard),
+		}),
+
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			CaptureMethod: stripe.String(string(stripe.PaymentIntentCaptureMethodManual)),
+			Metadata:      req.Metadata(),
+		},
+	}
+	params.Context = ctx
+
+	for k, v := range req.Metadata() {
+		params.AddMetadata(k, v)
+	}
+
+	cs, err := session.New(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return stripeHold(cs), nil
+}
+
+func (s *StripePayment) GetHold(ctx context.Context, holdID string) (*Hold, error) {
+
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = ctx
+	params.AddExpand("payment_intent")
+
+	cs, err := session.Get(holdID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return stripeHold(cs), nil
+}
+
+func (s *StripePayment) CaptureHold(ctx context.Context, holdID string) (*Hold, error) {
+
+	hold, err := s.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status != HoldAuthorized {
+		return hold, nil
+	}
+
+	params := &stripe.PaymentIntentCaptureParams{}
+	params.Context = ctx
+
+	if _, err := paymentintent.Capture(hold.PaymentIntentID, params); err != nil {
+		return nil, err
+	}
+
+	hold.Status = HoldCaptured
+	return hold, nil
+}
+
+func (s *StripePayment) ReleaseHold(ctx context.Context, holdID string) error {
+
+	hold, err := s.GetHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+
+	switch hold.Status {
+	case HoldPending:
+		return s.ExpireSession(ctx, holdID)
+	case HoldAuthorized:
+		params := &stripe.PaymentIntentCancelParams{
+			CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonAbandoned)),
+		}
+		params.Context = ctx
+		_, err := paymentintent.Cancel(hold.PaymentIntentID, params)
+		return err
+	}
+
+	return nil
+}
+
+func stripeHold(cs *stripe.CheckoutSession) *Hold {
+
+	res := &Hold{
+		ID:     cs.ID,
+		URL:    cs.URL,
+		Status: HoldPending,
+		Amount: cs.AmountTotal,
+	}
+
+	if cs.Status == stripe.CheckoutSessionStatusExpired {
+		res.Status = HoldReleased
+	}
+
+	if pi := cs.PaymentIntent; pi != nil {
+		res.PaymentIntentID = pi.ID
+		switch pi.Status {
+		case stripe.PaymentIntentStatusRequiresCapture:
+			res.Status = HoldAuthorized
+		case stripe.PaymentIntentStatusSucceeded:
+			res.Status = HoldCaptured
+		case stripe.PaymentIntentStatusCanceled:
+			res.Status = HoldReleased
+		}
+	}
+
+	return res
+}
+
+func stripeSession(cs *stripe.CheckoutSession) *CheckoutSession {
+
+	res := &CheckoutSession{
+		ID:            cs.ID,
+		URL:           cs.URL,
+		Status:        SessionStatus(cs.Status),
+		PaymentStatus: string(cs.PaymentStatus),
+		AmountTotal:   cs.AmountTotal,
+		Currency:      string(cs.Currency),
+		CustomerEmail: cs.CustomerEmail,
+		Metadata:      cs.Metadata,
+	}
+
+	if cs.PaymentIntent != nil {
+		res.PaymentIntentID = cs.PaymentIntent.ID
+	}
+
+	return res
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/puremike/online_auction_api/internal/handlers"
 	"github.com/puremike/online_auction_api/internal/imagesuploader"
 	"github.com/puremike/online_auction_api/internal/middlewares"
+	"github.com/puremike/online_auction_api/internal/payments"
 	"github.com/puremike/online_auction_api/internal/services"
 	"github.com/puremike/online_auction_api/internal/ws"
 	"github.com/puremike/online_auction_api/pkg"
@@ -19,7 +20,9 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Routes(app *config.Application) http.Handler {
+// Routes builds the API. The payment services are passed in rather than built
+// here so the handlers share them with the background workers.
+func Routes(app *config.Application, paymentServices *services.PaymentServices) http.Handler {
 
 	g := gin.Default()
 
@@ -40,12 +43,24 @@ func Routes(app *config.Application) http.Handler {
 
 	cachedService := cached.NewCached(app)
 
-	userService := services.NewUserService(app.Store.Users, app, cachedService.User)
-	userHandler := handlers.NewUserHandler(userService, app)
+	twoFactorService := services.NewTwoFactorService(app.Store.TwoFactor, app.Store.Tokens, app.Sealer, app.AppConfig.TwoFactorConf.Issuer, app.AppConfig.TwoFactorConf.ChallengeTTL, app.AppConfig.TwoFactorConf.MaxAttempts)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	userService := services.NewUserService(app.Store.Users, app.Store.Sessions, app.Store.Feedback, app, cachedService.User, twoFactorService)
+	verificationService := services.NewVerificationService(app.Store.Tokens, app.Mailer, cachedService.User, app.AppConfig.VerifyConf.TokenTTL, app.AppConfig.VerifyConf.ResendCooldown, app.AppConfig.VerifyConf.URL)
+	verificationHandler := handlers.NewVerificationHandler(verificationService)
+	userHandler := handlers.NewUserHandler(userService, verificationService, app)
+	sessionHandler := handlers.NewSessionHandler(services.NewSessionService(app.Store.Sessions, app.Store.Users), app)
+	passwordResetHandler := handlers.NewPasswordResetHandler(services.NewPasswordResetService(app.Store.Tokens, app.Store.Users, app.Mailer, cachedService.User, app.AppConfig.ResetConf.TokenTTL, app.AppConfig.ResetConf.URL))
 
-	auctionService := services.NewAuctionService(app.Store.Auctions, app.Store.Bids, app.Store.Notifications, app.WsHub.AuctionUpdates, app.WsHub.NotificationUpdates, cachedService.Auction)
+	auctionService := services.NewAuctionService(app.Store.Auctions, app.Store.Bids, app.Store.Notifications, app.Store.Watchlist, app.Store.Feedback, app.Store.SavedSearches, app.WsHub.AuctionUpdates, app.WsHub.NotificationUpdates, cachedService.Auction, paymentServices.Holds)
 	auctionHandler := handlers.NewAuctionHandler(auctionService, app)
 
+	watchlistService := services.NewWatchlistService(app.Store.Watchlist)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+
+	savedSearchService := services.NewSavedSearchService(app.Store.SavedSearches)
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
+
 	middleware := middlewares.NewMiddleware(app)
 
 	csService := services.NewCSService(app.Store.CS)
@@ -53,12 +68,22 @@ func Routes(app *config.Application) http.Handler {
 
 	wsHandler := ws.NewWSHandler(app.WsHub)
 
-	paymentService := services.NewPaymentService(app.Stripe, app.Store.Payments, app.Store.Auctions)
-	webHookHandler := handlers.NewWebHookHander(paymentService, app.Store.Auctions)
+	ledgerHandler := handlers.NewLedgerHandler(paymentServices.Ledger)
+	invoiceHandler := handlers.NewInvoiceHandler(paymentServices.Invoices)
+	feeHandler := handlers.NewFeeHandler(services.NewFeeService(app.Fees))
+	holdHandler := handlers.NewHoldHandler(paymentServices.Holds)
+	shippingHandler := handlers.NewShippingHandler(paymentServices.Shipping)
+	orderHandler := handlers.NewOrderHandler(paymentServices.Orders)
+	addressHandler := handlers.NewAddressHandler(services.NewAddressService(app.Store.Addresses))
+	feedbackHandler := handlers.NewFeedbackHandler(services.NewFeedbackService(app.Store.Feedback, app.Store.Orders, app.Store.Users, app.Store.Auctions, app.Store.Notifications, app.WsHub.NotificationUpdates, app.AppConfig.FeedbackConf.EditWindow))
+
+	webHookHandler := handlers.NewWebHookHander(paymentServices.Payments, app.Store.Auctions)
 
 	imageService := imagesuploader.NewImageService(app.AppConfig.S3Bucket)
 	imageHandler := handlers.NewImageHandler(imageService)
 
+	disputeHandler := handlers.NewDisputeHandler(paymentServices.Disputes, imageService)
+
 	api := g.Group("/api/v1")
 	api.Use(middleware.RateLimiterMiddleware(app.GeneralRateLimiter))
 	{
@@ -69,17 +94,27 @@ func Routes(app *config.Application) http.Handler {
 				"message": "checking",
 			})
 		})
-		api.POST("/webhook/stripe", webHookHandler.StripeWebHookHandler)
-		api.GET("/stripe/session/:sessionID", webHookHandler.GetPaymentSession)
-		api.GET("/paymentauction/:auctionID", webHookHandler.UpdateAuctionPayment)
+		api.POST("/webhook/"+app.Payments.Name(), webHookHandler.PaymentWebHookHandler)
+	}
+
+	if fake, ok := app.Payments.(*payments.FakePayment); ok {
+		fakeCheckoutHandler := handlers.NewFakeCheckoutHandler(fake)
+
+		api.GET("/fake-checkout/:sessionID", fakeCheckoutHandler.CheckoutPage)
+		api.POST("/fake-checkout/:sessionID/pay", fakeCheckoutHandler.Pay)
+		api.POST("/fake-checkout/:sessionID/decline", fakeCheckoutHandler.Decline)
 	}
 
 	user := api.Group("/")
 	{
 		user.POST("/signup", userHandler.RegisterUser)
 		user.POST("/login", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), userHandler.Login)
+		user.POST("/login/2fa", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), userHandler.LoginTwoFactor)
 		user.POST("/refresh", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), userHandler.RefreshToken)
 		user.POST("/admin/login", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), userHandler.AdminLogin)
+		user.POST("/verify-email", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), verificationHandler.VerifyEmail)
+		user.POST("/forgot-password", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), passwordResetHandler.ForgotPassword)
+		user.POST("/reset-password", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), passwordResetHandler.ResetPassword)
 	}
 
 	authGroup := api.Group("/")
@@ -88,31 +123,68 @@ func Routes(app *config.Application) http.Handler {
 		authGroup.POST("/logout", userHandler.Logout)
 		authGroup.POST("/admin/logout", userHandler.Logout)
 		authGroup.GET("/me", userHandler.MeProfile)
+		authGroup.POST("/verify-email/resend", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), verificationHandler.ResendVerification)
+		authGroup.GET("/me/sessions", sessionHandler.GetSessions)
+		authGroup.DELETE("/me/sessions", sessionHandler.RevokeAllSessions)
+		authGroup.DELETE("/me/sessions/:sessionID", sessionHandler.RevokeSession)
+		authGroup.POST("/me/2fa/setup", twoFactorHandler.SetupTwoFactor)
+		authGroup.POST("/me/2fa/confirm", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), twoFactorHandler.ConfirmTwoFactor)
+		authGroup.POST("/me/2fa/disable", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), twoFactorHandler.DisableTwoFactor)
+		authGroup.POST("/me/2fa/recovery-codes", middleware.RateLimiterMiddleware(app.SensitiveRateLimiter), twoFactorHandler.RegenerateRecoveryCodes)
+		authGroup.GET("/me/balance", ledgerHandler.GetBalance)
+		authGroup.GET("/me/payouts", ledgerHandler.GetPayouts)
+		authGroup.POST("/me/payouts", ledgerHandler.RequestPayout)
+		authGroup.GET("/me/addresses", addressHandler.GetAddresses)
+		authGroup.POST("/me/addresses", addressHandler.CreateAddress)
+		authGroup.PUT("/me/addresses/:addressID", addressHandler.UpdateAddress)
+		authGroup.DELETE("/me/addresses/:addressID", addressHandler.DeleteAddress)
 		authGroup.PUT("/change-password", userHandler.ChangePassword)
 		authGroup.GET("/:username", userHandler.UserProfile)
 		authGroup.PUT("/:username/update-profile", userHandler.UpdateProfile)
+		authGroup.GET("/:username/feedback", feedbackHandler.GetUserFeedback)
 
 		authGroup.DELETE("/users", userHandler.DeleteUser)
 
-		authGroup.GET("/admin/users", middlewares.AuthorizeRoles(true), userHandler.AdminGetUsers)
-		authGroup.DELETE("/admin/users/:userID", middlewares.AuthorizeRoles(true), userHandler.AdminDeleteUser)
+		authGroup.GET("/admin/users", middleware.AuthorizeRoles(true), userHandler.AdminGetUsers)
+		authGroup.DELETE("/admin/users/:userID", middleware.AuthorizeRoles(true), userHandler.AdminDeleteUser)
+		authGroup.GET("/admin/users/:userID/sessions", middleware.AuthorizeRoles(true), sessionHandler.AdminGetSessions)
+		authGroup.DELETE("/admin/users/:userID/sessions", middleware.AuthorizeRoles(true), sessionHandler.AdminRevokeAllSessions)
+		authGroup.DELETE("/admin/users/:userID/sessions/:sessionID", middleware.AuthorizeRoles(true), sessionHandler.AdminRevokeSession)
 		authGroup.GET("/auctions", auctionHandler.GetAuctions)
-		authGroup.DELETE("/admin/auctions/:auctionID", middleware.AuctionMiddleware(), middlewares.AuthorizeRoles(true), auctionHandler.AdminDeleteAuction)
+		authGroup.DELETE("/admin/auctions/:auctionID", middleware.AuthorizeRoles(true), middleware.AuctionMiddleware(), auctionHandler.AdminDeleteAuction)
+		authGroup.GET("/admin/webhook-events", middleware.AuthorizeRoles(true), webHookHandler.AdminGetWebhookEvents)
+		authGroup.POST("/admin/webhook-events/:eventID/replay", middleware.AuthorizeRoles(true), webHookHandler.AdminReplayWebhookEvent)
+		authGroup.GET("/admin/payments/mismatches", middleware.AuthorizeRoles(true), webHookHandler.AdminGetPaymentMismatches)
 
 		authGroup.GET("/auctions/won", auctionHandler.GetMyWonAuctions)
 		authGroup.GET("/auctions/bidded", auctionHandler.GetBiddedAuctions)
 		authGroup.GET("/auctions/created-auctions", auctionHandler.GetAuctionsBySellerID)
+		authGroup.GET("/auctions/watched", watchlistHandler.GetWatchedAuctions)
 
-		authGroup.POST("/auctions", auctionHandler.CreateAuction)
+		authGroup.POST("/auctions", middleware.RequireVerifiedEmail(), auctionHandler.CreateAuction)
+		authGroup.GET("/fees/preview", feeHandler.PreviewFees)
 		authGroup.GET("/auctions/:auctionID", middleware.AuctionMiddleware(), auctionHandler.GetAuctionById)
 		authGroup.PUT("/auctions/:auctionID", middleware.AuctionMiddleware(), auctionHandler.UpdateAuction)
 		authGroup.DELETE("/auctions/:auctionID", middleware.AuctionMiddleware(), auctionHandler.DeleteAuction)
 
-		authGroup.POST("/auctions/:auctionID/bids", middleware.AuctionMiddleware(), auctionHandler.PlaceBids)
+		authGroup.POST("/auctions/:auctionID/bids", middleware.RequireVerifiedEmail(), middleware.AuctionMiddleware(), auctionHandler.PlaceBids)
+		authGroup.POST("/auctions/:auctionID/hold", middleware.RequireVerifiedEmail(), middleware.AuctionMiddleware(), holdHandler.CreateHold)
+		authGroup.GET("/auctions/:auctionID/hold", holdHandler.GetHold)
+		authGroup.PUT("/auctions/:auctionID/shipping", shippingHandler.SelectShipping)
+		authGroup.GET("/auctions/:auctionID/shipping", shippingHandler.GetShipping)
 		authGroup.POST("/auctions/:auctionID/close", middleware.AuctionMiddleware(), auctionHandler.CloseAuction)
+		authGroup.POST("/auctions/:auctionID/watch", middleware.AuctionMiddleware(), watchlistHandler.WatchAuction)
+		authGroup.DELETE("/auctions/:auctionID/watch", watchlistHandler.UnwatchAuction)
 
 		authGroup.POST("/contact-support", csHandler.ContactSupport)
 
+		authGroup.POST("/saved-searches", savedSearchHandler.CreateSavedSearch)
+		authGroup.GET("/saved-searches", savedSearchHandler.GetSavedSearches)
+		authGroup.GET("/saved-searches/:searchID", savedSearchHandler.GetSavedSearch)
+		authGroup.PUT("/saved-searches/:searchID", savedSearchHandler.UpdateSavedSearch)
+		authGroup.PUT("/saved-searches/:searchID/alerts", savedSearchHandler.SetSavedSearchAlerts)
+		authGroup.DELETE("/saved-searches/:searchID", savedSearchHandler.DeleteSavedSearch)
+
 		authGroup.GET("/ws", wsHandler.ServeWs)
 
 		authGroup.POST("/auctions/:auctionID/stripe/create-checkout-session", middleware.AuctionMiddleware(), webHookHandler.CreateCheckoutSessionHandler)
@@ -120,6 +192,34 @@ func Routes(app *config.Application) http.Handler {
 		authGroup.POST("/auctions/image_upload", imageHandler.UploadImage)
 
 		authGroup.GET("/payments/:orderID", webHookHandler.GetPayment)
+		authGroup.GET("/payments/:orderID/status", webHookHandler.GetPaymentStatus)
+		authGroup.GET("/payments/session/:sessionID/status", webHookHandler.GetPaymentStatusBySession)
+		authGroup.POST("/payments/:orderID/refunds", webHookHandler.RefundPayment)
+		authGroup.GET("/payments/:orderID/refunds", webHookHandler.GetRefunds)
+		authGroup.GET("/payments/:orderID/invoice", invoiceHandler.GetInvoice)
+
+		authGroup.GET("/orders", orderHandler.GetOrders)
+		authGroup.GET("/orders/:orderID", orderHandler.GetOrder)
+		authGroup.POST("/orders/:orderID/ship", orderHandler.ShipOrder)
+		authGroup.POST("/orders/:orderID/deliver", orderHandler.DeliverOrder)
+		authGroup.POST("/orders/:orderID/complete", orderHandler.CompleteOrder)
+		authGroup.POST("/orders/:orderID/dispute", disputeHandler.OpenDispute)
+		authGroup.POST("/orders/:orderID/feedback", feedbackHandler.LeaveFeedback)
+		authGroup.PUT("/feedback/:feedbackID", feedbackHandler.UpdateFeedback)
+		authGroup.POST("/feedback/:feedbackID/reply", feedbackHandler.ReplyToFeedback)
+
+		authGroup.GET("/disputes", disputeHandler.GetDisputes)
+		authGroup.GET("/disputes/:disputeID", disputeHandler.GetDispute)
+		authGroup.GET("/disputes/:disputeID/messages", disputeHandler.GetMessages)
+		authGroup.POST("/disputes/:disputeID/messages", disputeHandler.PostMessage)
+		authGroup.POST("/disputes/:disputeID/evidence", disputeHandler.AddEvidence)
+		authGroup.POST("/disputes/:disputeID/accept", disputeHandler.AcceptDispute)
+		authGroup.POST("/disputes/:disputeID/request-return", disputeHandler.RequestReturn)
+		authGroup.POST("/disputes/:disputeID/return", disputeHandler.ShipReturn)
+		authGroup.POST("/disputes/:disputeID/return-received", disputeHandler.ConfirmReturn)
+		authGroup.POST("/disputes/:disputeID/escalate", disputeHandler.Escalate)
+		authGroup.GET("/admin/disputes", middleware.AuthorizeRoles(true), disputeHandler.AdminGetDisputes)
+		authGroup.POST("/admin/disputes/:disputeID/resolve", middleware.AuthorizeRoles(true), disputeHandler.AdminResolveDispute)
 	}
 
 	return g
@@ -0,0 +1,293 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/puremike/online_auction_api/internal/auth"
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/puremike/online_auction_api/internal/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type access int
+
+const (
+	public access = iota
+	user
+	admin
+)
+
+// routeAccess is the auth requirement of every route. A route missing here
+// fails TestRoutesAreClassified, so new routes must state who may call them.
+var routeAccess = map[string]access{
+	"GET /api/v1/swagger/*any":                      public,
+	"GET /api/v1/health":                            public,
+	"GET /api/v1/checking":                          public,
+	"POST /api/v1/webhook/fake":                     public,
+	"POST /api/v1/signup":                           public,
+	"POST /api/v1/login":                            public,
+	"POST /api/v1/login/2fa":                        public,
+	"POST /api/v1/refresh":                          public,
+	"POST /api/v1/admin/login":                      public,
+	"POST /api/v1/verify-email":                     public,
+	"POST /api/v1/forgot-password":                  public,
+	"POST /api/v1/reset-password":                   public,
+	"GET /api/v1/fake-checkout/:sessionID":          public,
+	"POST /api/v1/fake-checkout/:sessionID/pay":     public,
+	"POST /api/v1/fake-checkout/:sessionID/decline": public,
+
+	"POST /api/v1/logout":                                             user,
+	"POST /api/v1/admin/logout":                                       user,
+	"GET /api/v1/me":                                                  user,
+	"GET /api/v1/me/sessions":                                         user,
+	"DELETE /api/v1/me/sessions":                                      user,
+	"DELETE /api/v1/me/sessions/:sessionID":                           user,
+	"POST /api/v1/me/2fa/setup":                                       user,
+	"POST /api/v1/me/2fa/confirm":                                     user,
+	"POST /api/v1/me/2fa/disable":                                     user,
+	"POST /api/v1/me/2fa/recovery-codes":                              user,
+	"POST /api/v1/verify-email/resend":                                user,
+	"GET /api/v1/me/balance":                                          user,
+	"GET /api/v1/me/payouts":                                          user,
+	"POST /api/v1/me/payouts":                                         user,
+	"GET /api/v1/me/addresses":                                        user,
+	"POST /api/v1/me/addresses":                                       user,
+	"PUT /api/v1/me/addresses/:addressID":                             user,
+	"DELETE /api/v1/me/addresses/:addressID":                          user,
+	"PUT /api/v1/change-password":                                     user,
+	"GET /api/v1/:username":                                           user,
+	"PUT /api/v1/:username/update-profile":                            user,
+	"DELETE /api/v1/users":                                            user,
+	"GET /api/v1/auctions":                                            user,
+	"GET /api/v1/auctions/won":                                        user,
+	"GET /api/v1/auctions/bidded":                                     user,
+	"GET /api/v1/auctions/created-auctions":                           user,
+	"GET /api/v1/auctions/watched":                                    user,
+	"POST /api/v1/auctions":                                           user,
+	"POST /api/v1/auctions/:auctionID/hold":                           user,
+	"GET /api/v1/auctions/:auctionID/hold":                            user,
+	"PUT /api/v1/auctions/:auctionID/shipping":                        user,
+	"GET /api/v1/auctions/:auctionID/shipping":                        user,
+	"GET /api/v1/fees/preview":                                        user,
+	"GET /api/v1/auctions/:auctionID":                                 user,
+	"PUT /api/v1/auctions/:auctionID":                                 user,
+	"DELETE /api/v1/auctions/:auctionID":                              user,
+	"POST /api/v1/auctions/:auctionID/bids":                           user,
+	"POST /api/v1/auctions/:auctionID/close":                          user,
+	"POST /api/v1/auctions/:auctionID/watch":                          user,
+	"DELETE /api/v1/auctions/:auctionID/watch":                        user,
+	"POST /api/v1/contact-support":                                    user,
+	"POST /api/v1/saved-searches":                                     user,
+	"GET /api/v1/saved-searches":                                      user,
+	"GET /api/v1/saved-searches/:searchID":                            user,
+	"PUT /api/v1/saved-searches/:searchID":                            user,
+	"PUT /api/v1/saved-searches/:searchID/alerts":                     user,
+	"DELETE /api/v1/saved-searches/:searchID":                         user,
+	"GET /api/v1/ws":                                                  user,
+	"POST /api/v1/auctions/:auctionID/stripe/create-checkout-session": user,
+	"POST /api/v1/auctions/image_upload":                              user,
+	"GET /api/v1/payments/:orderID":                                   user,
+	"GET /api/v1/payments/:orderID/status":                            user,
+	"GET /api/v1/payments/session/:sessionID/status":                  user,
+	"POST /api/v1/payments/:orderID/refunds":                          user,
+	"GET /api/v1/payments/:orderID/refunds":                           user,
+	"GET /api/v1/payments/:orderID/invoice":                           user,
+	"GET /api/v1/orders":                                              user,
+	"GET /api/v1/orders/:orderID":                                     user,
+	"POST /api/v1/orders/:orderID/ship":                               user,
+	"POST /api/v1/orders/:orderID/deliver":                            user,
+	"POST /api/v1/orders/:orderID/complete":                           user,
+	"POST /api/v1/orders/:orderID/dispute":                            user,
+	"POST /api/v1/orders/:orderID/feedback":                           user,
+	"PUT /api/v1/feedback/:feedbackID":                                user,
+	"POST /api/v1/feedback/:feedbackID/reply":                         user,
+	"GET /api/v1/:username/feedback":                                  user,
+	"GET /api/v1/disputes":                                            user,
+	"GET /api/v1/disputes/:disputeID":                                 user,
+	"GET /api/v1/disputes/:disputeID/messages":                        user,
+	"POST /api/v1/disputes/:disputeID/messages":                       user,
+	"POST /api/v1/disputes/:disputeID/evidence":                       user,
+	"POST /api/v1/disputes/:disputeID/accept":                         user,
+	"POST /api/v1/disputes/:disputeID/request-return":                 user,
+	"POST /api/v1/disputes/:disputeID/return":                         user,
+	"POST /api/v1/disputes/:disputeID/return-received":                user,
+	"POST /api/v1/disputes/:disputeID/escalate":                       user,
+	"GET /api/v1/admin/disputes":                                      admin,
+	"POST /api/v1/admin/disputes/:disputeID/resolve":                  admin,
+
+	"GET /api/v1/admin/users":                                admin,
+	"DELETE /api/v1/admin/users/:userID":                     admin,
+	"GET /api/v1/admin/users/:userID/sessions":               admin,
+	"DELETE /api/v1/admin/users/:userID/sessions":            admin,
+	"DELETE /api/v1/admin/users/:userID/sessions/:sessionID": admin,
+	"DELETE /api/v1/admin/auctions/:auctionID":               admin,
+	"GET /api/v1/admin/webhook-events":                       admin,
+	"POST /api/v1/admin/webhook-events/:eventID/replay":      admin,
+	"GET /api/v1/admin/payments/mismatches":                  admin,
+}
+
+type allowAll struct{}
+
+func (allowAll) Allowed() bool { return true }
+
+func newTestApp(users store.UserRepository) *config.Application {
+	return &config.Application{
+		AppConfig:            &config.AppConfig{},
+		Logger:               zap.NewNop().Sugar(),
+		JwtAUth:              auth.NewJWTAuthenticator("test-secret", "test-iss", "test-aud"),
+		Store:                &store.Storage{Users: users},
+		WsHub:                ws.NewHub(),
+		GeneralRateLimiter:   allowAll{},
+		SensitiveRateLimiter: allowAll{},
+		HeavyOpsRateLimiter:  allowAll{},
+		Payments:             payments.NewFakePayment("http://localhost", "test-webhook-secret", "", ""),
+		Payouts:              payments.NewStubPayout(),
+	}
+}
+
+func testToken(t *testing.T, app *config.Application, userID string) string {
+	token, err := app.JwtAUth.GenerateToken(jwt.MapClaims{
+		"sub": userID,
+		"iss": "test-iss",
+		"aud": "test-aud",
+		"iat": time.Now().Unix(),
+		"nbf": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func testEngine(t *testing.T, app *config.Application) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine, ok := Routes(app, services.NewPaymentServices(app)).(*gin.Engine)
+	require.True(t, ok, "Routes should return a gin engine")
+	return engine
+}
+
+// samplePath fills in path parameters so the route can be requested
+func samplePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "sample-" + segment[1:]
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "index.html"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestRoutesAreClassified(t *testing.T) {
+	engine := testEngine(t, newTestApp(new(mock_store.MockUserStore)))
+
+	registered := map[string]bool{}
+	for _, route := range engine.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		assert.Contains(t, routeAccess, key, "route has no expected auth requirement")
+	}
+
+	for key := range routeAccess {
+		assert.True(t, registered[key], "expected route %s is not registered", key)
+	}
+}
+
+func TestPaymentStatusCannotBeChangedPublicly(t *testing.T) {
+	engine := testEngine(t, newTestApp(new(mock_store.MockUserStore)))
+
+	for _, route := range engine.Routes() {
+		if routeAccess[route.Method+" "+route.Path] != public {
+			continue
+		}
+		assert.NotContains(t, route.Path, "paymentauction", "paid flag must only change through webhooks and reconciliation")
+		assert.NotContains(t, route.Path, "/stripe/session", "checkout sessions must not be readable without auth")
+	}
+}
+
+func TestProtectedRoutesRequireAuth(t *testing.T) {
+	engine := testEngine(t, newTestApp(new(mock_store.MockUserStore)))
+
+	for key, level := range routeAccess {
+		if level == public {
+			continue
+		}
+
+		method, path, _ := strings.Cut(key, " ")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, samplePath(path), nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s without a token", key)
+	}
+}
+
+func TestAdminRoutesRejectNonAdmins(t *testing.T) {
+	users := new(mock_store.MockUserStore)
+	users.On("GetUserById", mock.Anything, "user-1").Return(&models.User{ID: "user-1", Username: "user1"}, nil)
+
+	app := newTestApp(users)
+	engine := testEngine(t, app)
+	token := testToken(t, app, "user-1")
+
+	for key, level := range routeAccess {
+		if level != admin {
+			continue
+		}
+
+		method, path, _ := strings.Cut(key, " ")
+		req := httptest.NewRequest(method, samplePath(path), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s as a non-admin", key)
+	}
+}
+
+func TestAdminRoutesRequireTwoFactor(t *testing.T) {
+	users := new(mock_store.MockUserStore)
+	users.On("GetUserById", mock.Anything, "admin-1").Return(&models.User{ID: "admin-1", Username: "admin1", IsAdmin: true}, nil)
+
+	twoFactor := new(mock_store.MockTwoFactorStore)
+	twoFactor.On("GetTwoFactor", mock.Anything, "admin-1").Return((*models.TwoFactor)(nil), errs.ErrTwoFactorNotEnabled)
+
+	app := newTestApp(users)
+	app.Store.TwoFactor = twoFactor
+	app.AppConfig.TwoFactorConf.RequiredForAdmins = true
+	engine := testEngine(t, app)
+	token := testToken(t, app, "admin-1")
+
+	for key, level := range routeAccess {
+		if level != admin {
+			continue
+		}
+
+		method, path, _ := strings.Cut(key, " ")
+		req := httptest.NewRequest(method, samplePath(path), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s as an admin without two-factor", key)
+		assert.Contains(t, w.Body.String(), "two-factor", "%s as an admin without two-factor", key)
+	}
+}
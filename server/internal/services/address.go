@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+type AddressService struct {
+	repo store.AddressRepository
+}
+
+func NewAddressService(repo store.AddressRepository) *AddressService {
+	return &AddressService{
+		repo: repo,
+	}
+}
+
+func newAddress(userID string, req *models.AddressRequest) *models.Address {
+	return &models.Address{
+		UserID:     userID,
+		Label:      strings.TrimSpace(req.Label),
+		FullName:   strings.TrimSpace(req.FullName),
+		Line1:      strings.TrimSpace(req.Line1),
+		Line2:      strings.TrimSpace(req.Line2),
+		City:       strings.TrimSpace(req.City),
+		Region:     strings.TrimSpace(req.Region),
+		PostalCode: strings.TrimSpace(req.PostalCode),
+		Country:    strings.TrimSpace(req.Country),
+		Phone:      strings.TrimSpace(req.Phone),
+		IsDefault:  req.IsDefault,
+	}
+}
+
+func (a *AddressService) CreateAddress(ctx context.Context, userID string, req *models.AddressRequest) (*models.Address, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	address := newAddress(userID, req)
+	if err := a.repo.CreateAddress(ctx, address); err != nil {
+		return nil, errs.ErrFailedToSaveAddress
+	}
+
+	return address, nil
+}
+
+func (a *AddressService) GetAddresses(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Address], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	addresses, total, err := a.repo.GetAddresses(ctx, userID, page)
+	if err != nil {
+		return nil, errs.ErrFailedToRetrieveAddresses
+	}
+
+	return pagination.NewPage(addresses, page, total, func(last *models.Address) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
+
+func (a *AddressService) UpdateAddress(ctx context.Context, id, userID string, req *models.AddressRequest) (*models.Address, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	address := newAddress(userID, req)
+	address.ID = id
+
+	if err := a.repo.UpdateAddress(ctx, address); err != nil {
+		if errors.Is(err, errs.ErrAddressNotFound) {
+			return nil, err
+		}
+		return nil, errs.ErrFailedToSaveAddress
+	}
+
+	return address, nil
+}
+
+func (a *AddressService) DeleteAddress(ctx context.Context, id, userID string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := a.repo.DeleteAddress(ctx, id, userID); err != nil {
+		if errors.Is(err, errs.ErrAddressNotFound) {
+			return "", err
+		}
+		return "", errs.ErrFailedToDeleteAddress
+	}
+
+	return "address deleted successfully", nil
+}
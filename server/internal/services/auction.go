@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/puremike/online_auction_api/internal/cached"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/store"
 )
 
@@ -16,19 +19,27 @@ type AuctionService struct {
 	repo           store.AuctionRepository
 	bidRepo        store.BidRepository
 	notRepo        store.NotificationRepository
+	watchRepo      store.WatchlistRepository
+	feedbackRepo   store.FeedbackRepository
+	searchRepo     store.SavedSearchRepository
 	auctionUpdates chan<- *models.AuctionUpdateEvent
 	notifications  chan<- *models.NotificationEvent
 	cached         cached.CachedAuctionInterface
+	holds          *HoldService
 }
 
-func NewAuctionService(repo store.AuctionRepository, bidRepo store.BidRepository, notRepo store.NotificationRepository, auctionUpdates chan<- *models.AuctionUpdateEvent, notifications chan<- *models.NotificationEvent, cached cached.CachedAuctionInterface) *AuctionService {
+func NewAuctionService(repo store.AuctionRepository, bidRepo store.BidRepository, notRepo store.NotificationRepository, watchRepo store.WatchlistRepository, feedbackRepo store.FeedbackRepository, searchRepo store.SavedSearchRepository, auctionUpdates chan<- *models.AuctionUpdateEvent, notifications chan<- *models.NotificationEvent, cached cached.CachedAuctionInterface, holds *HoldService) *AuctionService {
 	return &AuctionService{
 		repo:           repo,
 		bidRepo:        bidRepo,
 		notRepo:        notRepo,
+		watchRepo:      watchRepo,
+		feedbackRepo:   feedbackRepo,
+		searchRepo:     searchRepo,
 		auctionUpdates: auctionUpdates,
 		notifications:  notifications,
 		cached:         cached,
+		holds:          holds,
 	}
 }
 
@@ -41,20 +52,27 @@ func (a *AuctionService) CreateAuction(ctx context.Context, req *models.Auction)
 		return &models.CreateAuctionResponse{}, errs.ErrInvalidAuctionDetails
 	}
 
+	if err := validateShippingOptions(req.ShippingOptions, req.WeightKg); err != nil {
+		return &models.CreateAuctionResponse{}, err
+	}
+
 	auction := &models.Auction{
-		Title:         req.Title,
-		Description:   req.Description,
-		StartingPrice: req.StartingPrice,
-		CurrentPrice:  req.StartingPrice,
-		Type:          strings.ToLower(req.Type),
-		Status:        "open",
-		StartTime:     req.StartTime,
-		EndTime:       req.EndTime,
-		SellerID:      req.SellerID,
-		WinnerID:      req.SellerID,
-		ImagePath:     req.ImagePath,
-		Category:      req.Category,
-		IsPaid:        false,
+		Title:           req.Title,
+		Description:     req.Description,
+		StartingPrice:   req.StartingPrice,
+		CurrentPrice:    req.StartingPrice,
+		Type:            strings.ToLower(req.Type),
+		Status:          "open",
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		SellerID:        req.SellerID,
+		WinnerID:        req.SellerID,
+		ImagePath:       req.ImagePath,
+		Category:        req.Category,
+		IsPaid:          false,
+		HoldThreshold:   req.HoldThreshold,
+		WeightKg:        req.WeightKg,
+		ShippingOptions: req.ShippingOptions,
 	}
 
 	createdAuction, err := a.repo.CreateAuction(ctx, auction)
@@ -62,20 +80,26 @@ func (a *AuctionService) CreateAuction(ctx context.Context, req *models.Auction)
 		return &models.CreateAuctionResponse{}, errs.ErrFailedToCreateAuction
 	}
 
+	// matching runs outside the request so saved search alerts never slow down listing
+	go a.alertSavedSearches(*createdAuction)
+
 	res := &models.CreateAuctionResponse{
-		ID:            createdAuction.ID,
-		SellerID:      createdAuction.SellerID,
-		Title:         createdAuction.Title,
-		Description:   createdAuction.Description,
-		StartingPrice: createdAuction.StartingPrice,
-		CurrentPrice:  createdAuction.CurrentPrice,
-		Type:          createdAuction.Type,
-		Status:        createdAuction.Status,
-		StartTime:     createdAuction.StartTime,
-		EndTime:       createdAuction.EndTime,
-		CreatedAt:     createdAuction.CreatedAt,
-		ImagePath:     createdAuction.ImagePath,
-		Category:      createdAuction.Category,
+		ID:              createdAuction.ID,
+		SellerID:        createdAuction.SellerID,
+		Title:           createdAuction.Title,
+		Description:     createdAuction.Description,
+		StartingPrice:   createdAuction.StartingPrice,
+		CurrentPrice:    createdAuction.CurrentPrice,
+		Type:            createdAuction.Type,
+		Status:          createdAuction.Status,
+		StartTime:       createdAuction.StartTime,
+		EndTime:         createdAuction.EndTime,
+		CreatedAt:       createdAuction.CreatedAt,
+		ImagePath:       createdAuction.ImagePath,
+		Category:        createdAuction.Category,
+		HoldThreshold:   createdAuction.HoldThreshold,
+		WeightKg:        createdAuction.WeightKg,
+		ShippingOptions: createdAuction.ShippingOptions,
 	}
 
 	return res, nil
@@ -90,17 +114,24 @@ func (a *AuctionService) UpdateAuction(ctx context.Context, req *models.Auction,
 		return "", errs.ErrInvalidAuctionDetails
 	}
 
+	if err := validateShippingOptions(req.ShippingOptions, req.WeightKg); err != nil {
+		return "", err
+	}
+
 	auction := &models.Auction{
-		Title:         req.Title,
-		Description:   req.Description,
-		StartingPrice: req.StartingPrice,
-		CurrentPrice:  req.StartingPrice,
-		Type:          req.Type,
-		Status:        "open",
-		StartTime:     req.StartTime,
-		EndTime:       req.EndTime,
-		SellerID:      req.SellerID,
-		WinnerID:      req.SellerID,
+		Title:           req.Title,
+		Description:     req.Description,
+		StartingPrice:   req.StartingPrice,
+		CurrentPrice:    req.StartingPrice,
+		Type:            req.Type,
+		Status:          "open",
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		SellerID:        req.SellerID,
+		WinnerID:        req.SellerID,
+		HoldThreshold:   req.HoldThreshold,
+		WeightKg:        req.WeightKg,
+		ShippingOptions: req.ShippingOptions,
 	}
 
 	if err := a.repo.UpdateAuction(ctx, auction, id); err != nil {
@@ -115,11 +146,12 @@ func (a *AuctionService) DeleteAuction(ctx context.Context, id string) (string,
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	if err := a.bidRepo.DeleteBidsByAuction(ctx, id); err != nil {
-		return "", errs.ErrFailedToDeleteBids
-	}
-
+	// bids are deleted with the auction; deleting them first would lose them
+	// when the auction can't be deleted
 	if err := a.cached.DeleteAuctionFromCache(ctx, id); err != nil {
+		if errors.Is(err, errs.ErrAuctionHasInvoice) {
+			return "", err
+		}
 		return "", errs.ErrFailedToDeleteAuction
 	}
 
@@ -139,7 +171,46 @@ func (a *AuctionService) GetAuctionById(ctx context.Context, id string) (*models
 		return &models.CreateAuctionResponse{}, fmt.Errorf("failed to retrieve auction: %w", err)
 	}
 
+	watcherCount, err := a.watchRepo.CountWatchers(ctx, id)
+	if err != nil {
+		return &models.CreateAuctionResponse{}, fmt.Errorf("failed to count auction watchers: %w", err)
+	}
+
+	// the listing is still useful without its seller's reputation
+	feedback, err := a.feedbackRepo.GetFeedbackScores(ctx, auction.SellerID)
+	if err != nil {
+		log.Printf("failed to get feedback scores of seller %s: %v", auction.SellerID, err)
+	}
+
 	res := &models.CreateAuctionResponse{
+		ID:              auction.ID,
+		SellerID:        auction.SellerID,
+		Title:           auction.Title,
+		Description:     auction.Description,
+		StartingPrice:   auction.StartingPrice,
+		CurrentPrice:    auction.CurrentPrice,
+		Type:            auction.Type,
+		Status:          auction.Status,
+		StartTime:       auction.StartTime,
+		EndTime:         auction.EndTime,
+		CreatedAt:       auction.CreatedAt,
+		ImagePath:       auction.ImagePath,
+		WatcherCount:    watcherCount,
+		HoldThreshold:   auction.HoldThreshold,
+		WeightKg:        auction.WeightKg,
+		ShippingOptions: auction.ShippingOptions,
+	}
+
+	if feedback != nil {
+		res.SellerReputation = &feedback.AsSeller
+	}
+
+	return res, nil
+}
+
+// auctionResponse is the listing view of an auction shared by the paged endpoints
+func auctionResponse(auction models.Auction) models.CreateAuctionResponse {
+	return models.CreateAuctionResponse{
 		ID:            auction.ID,
 		SellerID:      auction.SellerID,
 		Title:         auction.Title,
@@ -152,120 +223,144 @@ func (a *AuctionService) GetAuctionById(ctx context.Context, id string) (*models
 		EndTime:       auction.EndTime,
 		CreatedAt:     auction.CreatedAt,
 		ImagePath:     auction.ImagePath,
+		Category:      auction.Category,
+		IsPaid:        auction.IsPaid,
+		HoldThreshold: auction.HoldThreshold,
+		Rank:          auction.Rank,
+		Highlight:     auction.Highlight,
+	}
+}
+
+// auctionPage converts a look-ahead result set into a page of auction responses
+func auctionPage(auctions []models.Auction, page *pagination.Params, total int, next func(last models.CreateAuctionResponse) *pagination.Cursor) *pagination.Page[models.CreateAuctionResponse] {
+
+	res := make([]models.CreateAuctionResponse, 0, len(auctions))
+	for _, auction := range auctions {
+		res = append(res, auctionResponse(auction))
 	}
 
-	return res, nil
+	return pagination.NewPage(res, page, total, next)
 }
 
-func (a *AuctionService) GetAuctions(ctx context.Context, limit, offset int, filter *models.AuctionFilter) (*[]models.CreateAuctionResponse, error) {
+// createdAtCursor resumes a newest-first listing after the given auction
+func createdAtCursor(last models.CreateAuctionResponse) *pagination.Cursor {
+	return pagination.Keyset(last.CreatedAt, last.ID)
+}
+
+func (a *AuctionService) GetAuctions(ctx context.Context, page *pagination.Params, filter *models.AuctionFilter) (*pagination.Page[models.CreateAuctionResponse], error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	auctions, err := a.repo.GetAuctions(ctx, limit, offset, filter)
+	if !models.IsValidAuctionSort(filter.Sort) {
+		return nil, errs.ErrInvalidSortKey
+	}
+
+	if filter.MinPrice < 0 || filter.MaxPrice < 0 || filter.EndingWithinHours < 0 {
+		return nil, errs.ErrInvalidAuctionFilter
+	}
+
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return nil, errs.ErrInvalidPriceRange
+	}
+
+	auctions, total, err := a.repo.GetAuctions(ctx, page, filter)
 	if err != nil {
-		return &[]models.CreateAuctionResponse{}, errors.New("failed to retrieve auctions")
+		return nil, errors.New("failed to retrieve auctions")
 	}
 
-	res := &[]models.CreateAuctionResponse{}
-
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			Category:      auction.Category,
-			IsPaid:        auction.IsPaid,
-		})
+	// rank, price and bid count orderings have no stable keyset and page by offset
+	next := createdAtCursor
+	if !filter.OrderedByCreation() {
+		next = func(models.CreateAuctionResponse) *pagination.Cursor { return page.NextOffset() }
 	}
 
-	return res, nil
+	return auctionPage(*auctions, page, total, next), nil
 }
 
-func (a *AuctionService) GetAuctionsBySellerID(ctx context.Context, sellerID string) (*[]models.CreateAuctionResponse, error) {
+func (a *AuctionService) GetAuctionsBySellerID(ctx context.Context, sellerID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	auctions, err := a.repo.GetAuctionBySellerId(ctx, sellerID)
+	auctions, total, err := a.repo.GetAuctionBySellerId(ctx, sellerID, page)
 	if err != nil {
-		return &[]models.CreateAuctionResponse{}, errors.New("failed to retrieve auctions")
-	}
-
-	res := &[]models.CreateAuctionResponse{}
-
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			IsPaid:        auction.IsPaid,
-		})
+		return nil, errors.New("failed to retrieve auctions")
 	}
 
-	return res, nil
+	return auctionPage(*auctions, page, total, createdAtCursor), nil
 }
 
-func (a *AuctionService) GetWonAuctionsByWinnerID(ctx context.Context, winnerID string) (*[]models.CreateAuctionResponse, error) {
+func (a *AuctionService) GetWonAuctionsByWinnerID(ctx context.Context, winnerID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
 	existingAuction, err := a.repo.GetAuctionByWinnerId(ctx, winnerID)
 	if err != nil {
-		return &[]models.CreateAuctionResponse{}, errs.ErrAuctionNotFound
+		return nil, errs.ErrAuctionNotFound
 	}
 
 	if existingAuction.Status != "closed" {
-		return &[]models.CreateAuctionResponse{}, errs.ErrAuctionNotFound
+		return nil, errs.ErrAuctionNotFound
 	}
 
-	auctions, err := a.repo.GetWonAuctionsByWinnerID(ctx, winnerID)
+	auctions, total, err := a.repo.GetWonAuctionsByWinnerID(ctx, winnerID, page)
 	if err != nil {
-		return &[]models.CreateAuctionResponse{}, errors.New("failed to retrieve auctions")
+		return nil, errors.New("failed to retrieve auctions")
 	}
 
-	res := &[]models.CreateAuctionResponse{}
-
-	for _, auction := range *auctions {
-		*res = append(*res, models.CreateAuctionResponse{
-			ID:            auction.ID,
-			SellerID:      auction.SellerID,
-			Title:         auction.Title,
-			Description:   auction.Description,
-			StartingPrice: auction.StartingPrice,
-			CurrentPrice:  auction.CurrentPrice,
-			Type:          auction.Type,
-			Status:        auction.Status,
-			StartTime:     auction.StartTime,
-			EndTime:       auction.EndTime,
-			CreatedAt:     auction.CreatedAt,
-			ImagePath:     auction.ImagePath,
-			IsPaid:        auction.IsPaid,
-		})
+	return auctionPage(*auctions, page, total, createdAtCursor), nil
+}
+
+func (a *AuctionService) GetBiddedAuctionsForUser(ctx context.Context, bidderID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	auctions, total, err := a.repo.GetBiddedAuctions(ctx, bidderID, page)
+	if err != nil {
+		return nil, errors.New("failed to retrieve auctions")
 	}
 
-	return res, nil
+	return auctionPage(*auctions, page, total, createdAtCursor), nil
 }
 
-func (a *AuctionService) GetBiddedAuctionsForUser(ctx context.Context, bidderID string) (*[]models.Auction, error) {
-	return a.repo.GetBiddedAuctions(context.Background(), bidderID)
+// alertSavedSearches notifies every user whose saved search matches a newly created auction
+func (a *AuctionService) alertSavedSearches(auction models.Auction) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryDefaultContext)
+	defer cancel()
+
+	searches, err := a.searchRepo.GetMatchingSavedSearches(ctx, &auction)
+	if err != nil {
+		log.Printf("GetMatchingSavedSearches failed for auction %s: %v", auction.ID, err)
+		return
+	}
+
+	// a user with several matching searches is only alerted once
+	alerted := make(map[string]struct{})
+	for _, search := range searches {
+		if _, seen := alerted[search.UserID]; seen {
+			continue
+		}
+		alerted[search.UserID] = struct{}{}
+
+		a.notifications <- &models.NotificationEvent{
+			Type:      models.NotificationNewListing,
+			UserID:    search.UserID,
+			Message:   fmt.Sprintf("New auction %s matches your saved search %s", auction.Title, search.Name),
+			AuctionID: auction.ID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    search.UserID,
+			Message:   fmt.Sprintf("New auction %s matches your saved search %s, auctionId: %s", auction.Title, search.Name, auction.ID),
+			AuctionID: auction.ID,
+			IsRead:    false,
+		}
+		if err := a.notRepo.CreateNotification(ctx, not); err != nil {
+			log.Printf("CreateNotification failed for saved search %s: %v", search.ID, err)
+		}
+	}
 }
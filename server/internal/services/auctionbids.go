@@ -63,6 +63,10 @@ func (a *AuctionService) PlaceBid(ctx context.Context, req *models.PlaceBidReque
 		return nil, errors.New("unknown auction type")
 	}
 
+	if err := a.holds.RequireHold(ctx, auction, req.BidderID, req.BidAmount); err != nil {
+		return nil, err
+	}
+
 	// Retrieve the previous highest bid
 	previousBid, err := a.bidRepo.GetHighestBid(ctx, req.AuctionID)
 	if err != nil {
@@ -104,6 +108,10 @@ func (a *AuctionService) PlaceBid(ctx context.Context, req *models.PlaceBidReque
 		return nil, errs.ErrFailedToUpdateAuction
 	}
 
+	if auction.Status == "closed" {
+		go a.holds.SettleHolds(req.AuctionID, req.BidderID)
+	}
+
 	// WebSocket broadcast
 	a.auctionUpdates <- &models.AuctionUpdateEvent{
 		EventType:    models.AuctionNewBid,
@@ -146,6 +154,8 @@ func (a *AuctionService) PlaceBid(ctx context.Context, req *models.PlaceBidReque
 		}
 	}
 
+	a.notifyPriceWatchers(ctx, auction, req.BidderID, previousHighestBidderID)
+
 	return &models.BidResponse{
 		AuctionID: req.AuctionID,
 		BidderID:  req.BidderID,
@@ -193,6 +203,8 @@ func (a *AuctionService) CloseAuction(ctx context.Context, auctionID string, req
 		}
 	}
 
+	go a.holds.SettleHolds(auctionID, winnerID)
+
 	// Notify winner
 	if winnerID != "" {
 		a.notifications <- &models.NotificationEvent{
@@ -275,3 +287,37 @@ func (a *AuctionService) CloseAuction(ctx context.Context, auctionID string, req
 
 	return res, nil
 }
+
+// notifyPriceWatchers tells watchers who opted in to price changes about the new price.
+// The bidder and the outbid user are skipped since they are already notified.
+func (a *AuctionService) notifyPriceWatchers(ctx context.Context, auction *models.Auction, bidderID, outbidUserID string) {
+	watchers, err := a.watchRepo.GetPriceChangeWatchers(ctx, auction.ID)
+	if err != nil {
+		log.Printf("GetPriceChangeWatchers failed: %v", err)
+		return
+	}
+
+	for _, id := range watchers {
+		if id == bidderID || id == outbidUserID {
+			continue
+		}
+
+		a.notifications <- &models.NotificationEvent{
+			Type:      models.NotificationPriceChange,
+			UserID:    id,
+			Message:   fmt.Sprintf("The price of auction %s you are watching changed to %.2f", auction.Title, auction.CurrentPrice),
+			AuctionID: auction.ID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    id,
+			Message:   fmt.Sprintf("The price of auction %s you are watching changed to %.2f, auctionId: %s", auction.Title, auction.CurrentPrice, auction.ID),
+			AuctionID: auction.ID,
+			IsRead:    false,
+		}
+		if err := a.notRepo.CreateNotification(ctx, not); err != nil {
+			log.Printf("CreateNotification failed for watcher %s: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,632 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// disputableOrders are the order statuses a buyer can open a dispute from
+var disputableOrders = []string{models.OrderAwaitingShipment, models.OrderShipped, models.OrderDelivered}
+
+// disputeTransitions is the dispute state machine: the statuses each status
+// may be reached from. Who may make a move is checked by the action itself.
+var disputeTransitions = map[string][]string{
+	models.DisputeUnderReview:    {models.DisputeOpen, models.DisputeAwaitingReturn, models.DisputeReturnShipped},
+	models.DisputeAwaitingReturn: {models.DisputeOpen, models.DisputeUnderReview},
+	models.DisputeReturnShipped:  {models.DisputeAwaitingReturn},
+	models.DisputeRefunded:       {models.DisputeOpen, models.DisputeUnderReview, models.DisputeAwaitingReturn, models.DisputeReturnShipped},
+	models.DisputeRejected:       {models.DisputeOpen, models.DisputeUnderReview, models.DisputeAwaitingReturn, models.DisputeReturnShipped},
+}
+
+// DisputeService handles a buyer's claims about an order. The seller can
+// refund right away or ask for the item back first; when they don't agree, or
+// someone misses a deadline, an admin arbitrates. Refunds go through the
+// PaymentService. A dispute settled without a full refund completes its order,
+// which releases the seller's remaining funds.
+type DisputeService struct {
+	repo          store.DisputeRepository
+	orderRepo     store.OrderRepository
+	paymentRepo   store.PaymentRepository
+	ledgerRepo    store.LedgerRepository
+	auctionRepo   store.AuctionRepository
+	notRepo       store.NotificationRepository
+	notifications chan<- *models.NotificationEvent
+	payments      *PaymentService
+	respondWithin time.Duration
+	returnWithin  time.Duration
+}
+
+func NewDisputeService(repo store.DisputeRepository, orderRepo store.OrderRepository, paymentRepo store.PaymentRepository, ledgerRepo store.LedgerRepository, auctionRepo store.AuctionRepository, notRepo store.NotificationRepository, notifications chan<- *models.NotificationEvent, payments *PaymentService, respondWithin, returnWithin time.Duration) *DisputeService {
+	return &DisputeService{
+		repo:          repo,
+		orderRepo:     orderRepo,
+		paymentRepo:   paymentRepo,
+		ledgerRepo:    ledgerRepo,
+		auctionRepo:   auctionRepo,
+		notRepo:       notRepo,
+		notifications: notifications,
+		payments:      payments,
+		respondWithin: respondWithin,
+		returnWithin:  returnWithin,
+	}
+}
+
+// OpenDispute lets the buyer dispute an order that isn't completed yet. The
+// order is marked disputed, which keeps the seller's funds held, and the
+// seller has until the deadline to respond.
+func (d *DisputeService) OpenDispute(ctx context.Context, orderID, buyerID string, req *models.OpenDisputeRequest) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := d.orderRepo.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get order %s to dispute: %v", orderID, err)
+		return nil, errs.ErrFailedToCreateDispute
+	}
+
+	if order.BuyerID != buyerID {
+		return nil, errs.ErrNotAllowedToOpenDispute
+	}
+
+	dispute := &models.Dispute{
+		OrderID:     order.ID,
+		AuctionID:   order.AuctionID,
+		BuyerID:     order.BuyerID,
+		SellerID:    order.SellerID,
+		Reason:      req.Reason,
+		Description: strings.TrimSpace(req.Description),
+		Status:      models.DisputeOpen,
+		Deadline:    d.deadline(d.respondWithin),
+	}
+
+	if err := d.repo.CreateDispute(ctx, dispute, disputableOrders); err != nil {
+		if errors.Is(err, errs.ErrIllegalOrderTransition) {
+			return nil, errs.ErrOrderNotDisputable
+		}
+		log.Printf("failed to open dispute on order %s: %v", orderID, err)
+		return nil, errs.ErrFailedToCreateDispute
+	}
+
+	d.notifyDispute(ctx, dispute, fmt.Sprintf("was opened by the buyer, respond by %s", dispute.Deadline.Format(time.RFC1123)), dispute.SellerID)
+
+	return dispute, nil
+}
+
+// GetDispute returns a dispute to its buyer, its seller or an admin
+func (d *DisputeService) GetDispute(ctx context.Context, id string, user *models.User) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if disputeRole(dispute, user) == "" {
+		return nil, errs.ErrDisputeNotFound
+	}
+
+	return dispute, nil
+}
+
+// GetDisputes returns the disputes the user opened or received, newest first
+func (d *DisputeService) GetDisputes(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Dispute], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	disputes, total, err := d.repo.GetDisputes(ctx, userID, page)
+	if err != nil {
+		log.Printf("failed to get disputes of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToRetrieveDisputes
+	}
+
+	return disputePage(disputes, page, total), nil
+}
+
+// AdminGetDisputes returns the disputes in a status, or all of them when
+// status is empty, newest first
+func (d *DisputeService) AdminGetDisputes(ctx context.Context, status string, page *pagination.Params) (*pagination.Page[*models.Dispute], error) {
+
+	if status != "" && !models.IsValidDisputeStatus(status) {
+		return nil, errs.ErrInvalidDisputeStatus
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	disputes, total, err := d.repo.GetDisputesByStatus(ctx, status, page)
+	if err != nil {
+		log.Printf("failed to get %q disputes: %v", status, err)
+		return nil, errs.ErrFailedToRetrieveDisputes
+	}
+
+	return disputePage(disputes, page, total), nil
+}
+
+// GetMessages returns the thread of a dispute, oldest first
+func (d *DisputeService) GetMessages(ctx context.Context, id string, user *models.User, page *pagination.Params) (*pagination.Page[*models.DisputeMessage], error) {
+
+	dispute, err := d.GetDispute(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	messages, total, err := d.repo.GetDisputeMessages(ctx, dispute.ID, page)
+	if err != nil {
+		log.Printf("failed to get messages of dispute %s: %v", dispute.ID, err)
+		return nil, errs.ErrFailedToRetrieveDisputes
+	}
+
+	return pagination.NewPage(messages, page, total, func(last *models.DisputeMessage) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
+
+// PostMessage adds a message from the buyer, the seller or an admin to the
+// thread of an unresolved dispute
+func (d *DisputeService) PostMessage(ctx context.Context, id string, user *models.User, req *models.DisputeMessageRequest) (*models.DisputeMessage, error) {
+	return d.post(ctx, id, user, strings.TrimSpace(req.Body), "")
+}
+
+// AddEvidence adds an uploaded image to the thread of an unresolved dispute
+func (d *DisputeService) AddEvidence(ctx context.Context, id string, user *models.User, imagePath string) (*models.DisputeMessage, error) {
+	return d.post(ctx, id, user, "", imagePath)
+}
+
+// AcceptDispute lets the seller refund the buyer in full without asking for
+// the item back. The order is cancelled.
+func (d *DisputeService) AcceptDispute(ctx context.Context, id string, seller *models.User) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.SellerID != seller.ID {
+		return nil, errs.ErrNotAllowedToUpdateDispute
+	}
+
+	if err := d.refund(ctx, dispute, seller, 0, "Accepted by the seller"); err != nil {
+		return nil, err
+	}
+
+	d.notifyDispute(ctx, dispute, "was accepted by the seller and you have been refunded", dispute.BuyerID)
+
+	return dispute, nil
+}
+
+// RequestReturn lets the seller ask for the item back before refunding. The
+// buyer has until the deadline to ship it.
+func (d *DisputeService) RequestReturn(ctx context.Context, id, sellerID string) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.SellerID != sellerID {
+		return nil, errs.ErrNotAllowedToUpdateDispute
+	}
+
+	dispute.Deadline = d.deadline(d.returnWithin)
+	if err := d.transition(ctx, dispute, models.DisputeAwaitingReturn, []string{models.DisputeOpen}, ""); err != nil {
+		return nil, err
+	}
+
+	d.note(ctx, dispute, fmt.Sprintf("The seller asked for the item back. It has to be shipped by %s.", dispute.Deadline.Format(time.RFC1123)))
+	d.notifyDispute(ctx, dispute, fmt.Sprintf("needs the item returned, ship it by %s", dispute.Deadline.Format(time.RFC1123)), dispute.BuyerID)
+
+	return dispute, nil
+}
+
+// ShipReturn lets the buyer record that the item was sent back. The seller has
+// until the deadline to confirm it arrived.
+func (d *DisputeService) ShipReturn(ctx context.Context, id, buyerID string, req *models.ReturnShipmentRequest) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.BuyerID != buyerID {
+		return nil, errs.ErrNotAllowedToUpdateDispute
+	}
+
+	dispute.ReturnCarrier, dispute.ReturnTrackingNumber = req.Carrier, req.TrackingNumber
+	dispute.Deadline = d.deadline(d.respondWithin)
+	if err := d.transition(ctx, dispute, models.DisputeReturnShipped, disputeTransitions[models.DisputeReturnShipped], ""); err != nil {
+		return nil, err
+	}
+
+	d.note(ctx, dispute, fmt.Sprintf("The buyer shipped the return with %s, tracking number %s.", dispute.ReturnCarrier, dispute.ReturnTrackingNumber))
+	d.notifyDispute(ctx, dispute, fmt.Sprintf("has a return on its way with %s, tracking number %s, confirm it arrived by %s",
+		dispute.ReturnCarrier, dispute.ReturnTrackingNumber, dispute.Deadline.Format(time.RFC1123)), dispute.SellerID)
+
+	return dispute, nil
+}
+
+// ConfirmReturn lets the seller confirm the returned item arrived, which
+// refunds the buyer in full and cancels the order
+func (d *DisputeService) ConfirmReturn(ctx context.Context, id string, seller *models.User) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.SellerID != seller.ID {
+		return nil, errs.ErrNotAllowedToUpdateDispute
+	}
+	if dispute.Status != models.DisputeReturnShipped {
+		return nil, errs.ErrIllegalDisputeTransition
+	}
+
+	if err := d.refund(ctx, dispute, seller, 0, "Returned item received by the seller"); err != nil {
+		return nil, err
+	}
+
+	d.notifyDispute(ctx, dispute, "was settled: the seller received the return and you have been refunded", dispute.BuyerID)
+
+	return dispute, nil
+}
+
+// Escalate lets the buyer or the seller ask an admin to arbitrate
+func (d *DisputeService) Escalate(ctx context.Context, id, userID string) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.BuyerID != userID && dispute.SellerID != userID {
+		return nil, errs.ErrNotAllowedToUpdateDispute
+	}
+
+	dispute.Deadline = nil
+	if err := d.transition(ctx, dispute, models.DisputeUnderReview, disputeTransitions[models.DisputeUnderReview], ""); err != nil {
+		return nil, err
+	}
+
+	by, other := "buyer", dispute.SellerID
+	if userID == dispute.SellerID {
+		by, other = "seller", dispute.BuyerID
+	}
+
+	d.note(ctx, dispute, fmt.Sprintf("The %s asked an admin to review the dispute.", by))
+	d.notifyDispute(ctx, dispute, fmt.Sprintf("was sent to an admin for review by the %s", by), other)
+
+	return dispute, nil
+}
+
+// ResolveDispute is an admin's ruling on a dispute: refund the buyer in full
+// or in part, have the item returned first, or reject the dispute. Rejecting
+// completes the order, a partial refund does too.
+func (d *DisputeService) ResolveDispute(ctx context.Context, id string, admin *models.User, req *models.ResolveDisputeRequest) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	note := strings.TrimSpace(req.Note)
+
+	switch req.Outcome {
+	case models.DisputeOutcomeRefund:
+		if err := d.refund(ctx, dispute, admin, req.Amount, note); err != nil {
+			return nil, err
+		}
+		d.notifyDispute(ctx, dispute, fmt.Sprintf("was resolved by an admin with a refund of %.2f: %s", dispute.RefundAmount, note), dispute.BuyerID, dispute.SellerID)
+
+	case models.DisputeOutcomeReturn:
+		dispute.Deadline = d.deadline(d.returnWithin)
+		if err := d.transition(ctx, dispute, models.DisputeAwaitingReturn, disputeTransitions[models.DisputeAwaitingReturn], ""); err != nil {
+			return nil, err
+		}
+		d.note(ctx, dispute, fmt.Sprintf("An admin asked for the item to be returned by %s. %s", dispute.Deadline.Format(time.RFC1123), note))
+		d.notifyDispute(ctx, dispute, fmt.Sprintf("was reviewed by an admin: the item is to be returned by %s for a refund", dispute.Deadline.Format(time.RFC1123)), dispute.BuyerID, dispute.SellerID)
+
+	case models.DisputeOutcomeReject:
+		if err := d.reject(ctx, dispute, admin, note); err != nil {
+			return nil, err
+		}
+		d.notifyDispute(ctx, dispute, fmt.Sprintf("was rejected by an admin: %s", note), dispute.BuyerID, dispute.SellerID)
+	}
+
+	return dispute, nil
+}
+
+// EscalateOverdueDisputes sends the disputes whose buyer or seller missed
+// their deadline to an admin, and returns how many it escalated
+func (d *DisputeService) EscalateOverdueDisputes(ctx context.Context) (int, error) {
+
+	disputes, err := d.repo.GetOverdueDisputes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	escalated := 0
+	for _, dispute := range disputes {
+		missed := dispute.Status
+		dispute.Deadline = nil
+		if err := d.transition(ctx, dispute, models.DisputeUnderReview, []string{missed}, ""); err != nil {
+			// most likely someone acted on it in the meantime
+			log.Printf("failed to escalate overdue dispute %s: %v", dispute.ID, err)
+			continue
+		}
+		escalated++
+
+		d.note(ctx, dispute, "The deadline passed without a response, an admin will review the dispute.")
+		d.notifyDispute(ctx, dispute, "passed its deadline and was sent to an admin for review", dispute.BuyerID, dispute.SellerID)
+	}
+
+	return escalated, nil
+}
+
+// refund refunds amount of the dispute's payment, or everything not yet
+// refunded when amount is 0, and resolves the dispute. A full refund cancels
+// the order; a partial one completes it and releases the rest of the funds.
+func (d *DisputeService) refund(ctx context.Context, dispute *models.Dispute, user *models.User, amount float64, note string) error {
+
+	from := disputeTransitions[models.DisputeRefunded]
+	if !slices.Contains(from, dispute.Status) {
+		return errs.ErrIllegalDisputeTransition
+	}
+
+	refunds, err := d.payments.RefundPayment(ctx, dispute.OrderID, user, &models.CreateRefundRequest{
+		Amount: amount,
+		Reason: fmt.Sprintf("Dispute %s: %s", dispute.ID, note),
+	})
+	if err != nil {
+		return err
+	}
+
+	refunded := 0.0
+	for _, refund := range refunds {
+		refunded += refund.Amount
+	}
+
+	payment, err := d.paymentRepo.GetPaymentByOrderID(ctx, dispute.OrderID)
+	if err != nil {
+		log.Printf("failed to get payment of refunded dispute %s: %v", dispute.ID, err)
+		return errs.ErrFailedToUpdateDispute
+	}
+
+	orderStatus := models.OrderCompleted
+	if payment.Status == PaymentStatusRefunded {
+		orderStatus = models.OrderCancelled
+	}
+
+	dispute.RefundAmount += refunded
+	dispute.ResolutionNote, dispute.ResolvedBy, dispute.Deadline = note, &user.ID, nil
+	if err := d.transition(ctx, dispute, models.DisputeRefunded, from, orderStatus); err != nil {
+		// the buyer has the money back, the dispute needs an admin to close it
+		log.Printf("dispute %s refunded %.2f but failed to resolve: %v", dispute.ID, refunded, err)
+		return err
+	}
+
+	d.note(ctx, dispute, fmt.Sprintf("Refunded %.2f. %s", refunded, note))
+
+	if orderStatus == models.OrderCompleted {
+		d.release(ctx, payment.ID, dispute)
+	}
+
+	return nil
+}
+
+// reject resolves the dispute in the seller's favour, completing the order and
+// releasing the seller's funds
+func (d *DisputeService) reject(ctx context.Context, dispute *models.Dispute, user *models.User, note string) error {
+
+	dispute.ResolutionNote, dispute.ResolvedBy, dispute.Deadline = note, &user.ID, nil
+	if err := d.transition(ctx, dispute, models.DisputeRejected, disputeTransitions[models.DisputeRejected], models.OrderCompleted); err != nil {
+		return err
+	}
+
+	d.note(ctx, dispute, fmt.Sprintf("Dispute rejected. %s", note))
+
+	order, err := d.orderRepo.GetOrder(ctx, dispute.OrderID)
+	if err != nil {
+		log.Printf("failed to get order of rejected dispute %s: %v", dispute.ID, err)
+		return nil
+	}
+
+	d.release(ctx, order.PaymentID, dispute)
+
+	return nil
+}
+
+// release makes the seller's held funds available. A failed release is left
+// to the ledger release worker.
+func (d *DisputeService) release(ctx context.Context, paymentID string, dispute *models.Dispute) {
+	if _, err := d.ledgerRepo.ReleasePayment(ctx, paymentID); err != nil {
+		log.Printf("failed to release funds of settled dispute %s: %v", dispute.ID, err)
+	}
+}
+
+func (d *DisputeService) post(ctx context.Context, id string, user *models.User, body, imagePath string) (*models.DisputeMessage, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	dispute, err := d.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role := disputeRole(dispute, user)
+	if role == "" {
+		return nil, errs.ErrDisputeNotFound
+	}
+	if dispute.ResolvedAt != nil {
+		return nil, errs.ErrIllegalDisputeTransition
+	}
+
+	message := &models.DisputeMessage{
+		DisputeID:  dispute.ID,
+		SenderID:   &user.ID,
+		SenderRole: role,
+		Body:       body,
+		ImagePath:  imagePath,
+	}
+
+	if err := d.repo.CreateDisputeMessage(ctx, message); err != nil {
+		log.Printf("failed to post message to dispute %s: %v", dispute.ID, err)
+		return nil, errs.ErrFailedToPostDisputeMessage
+	}
+
+	what := "has a new message"
+	if imagePath != "" {
+		what = "has new evidence"
+	}
+
+	recipients := []string{}
+	for _, userID := range []string{dispute.BuyerID, dispute.SellerID} {
+		if userID != user.ID {
+			recipients = append(recipients, userID)
+		}
+	}
+	d.notifyDispute(ctx, dispute, fmt.Sprintf("%s from the %s", what, role), recipients...)
+
+	return message, nil
+}
+
+// note records a status change in the dispute's thread
+func (d *DisputeService) note(ctx context.Context, dispute *models.Dispute, body string) {
+
+	message := &models.DisputeMessage{
+		DisputeID:  dispute.ID,
+		SenderRole: models.DisputeSenderSystem,
+		Body:       body,
+	}
+
+	if err := d.repo.CreateDisputeMessage(ctx, message); err != nil {
+		log.Printf("failed to record %q on dispute %s: %v", body, dispute.ID, err)
+	}
+}
+
+func (d *DisputeService) getDispute(ctx context.Context, id string) (*models.Dispute, error) {
+
+	dispute, err := d.repo.GetDispute(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrDisputeNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get dispute %s: %v", id, err)
+		return nil, errs.ErrFailedToRetrieveDisputes
+	}
+
+	return dispute, nil
+}
+
+// transition moves a dispute to status to from one of the from statuses,
+// settling its order too when orderStatus is set
+func (d *DisputeService) transition(ctx context.Context, dispute *models.Dispute, to string, from []string, orderStatus string) error {
+
+	current := dispute.Status
+	dispute.Status = to
+
+	if err := d.repo.TransitionDispute(ctx, dispute, from, orderStatus); err != nil {
+		dispute.Status = current
+		if errors.Is(err, errs.ErrIllegalDisputeTransition) || errors.Is(err, errs.ErrDisputeNotFound) {
+			return err
+		}
+		log.Printf("failed to move dispute %s to %s: %v", dispute.ID, to, err)
+		return errs.ErrFailedToUpdateDispute
+	}
+
+	log.Printf("dispute %s (order %s): %s -> %s", dispute.ID, dispute.OrderID, current, to)
+
+	return nil
+}
+
+func (d *DisputeService) deadline(after time.Duration) *time.Time {
+	deadline := time.Now().Add(after)
+	return &deadline
+}
+
+func (d *DisputeService) notifyDispute(ctx context.Context, dispute *models.Dispute, what string, userIDs ...string) {
+
+	auction, err := d.auctionRepo.GetAuctionById(ctx, dispute.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to notify about dispute %s: %v", dispute.AuctionID, dispute.ID, err)
+		return
+	}
+
+	message := fmt.Sprintf("The dispute about %s %s", auction.Title, what)
+
+	for _, userID := range userIDs {
+		d.notifications <- &models.NotificationEvent{
+			Type:      models.NotificationDisputeUpdate,
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			IsRead:    false,
+		}
+		if err := d.notRepo.CreateNotification(ctx, not); err != nil {
+			log.Printf("CreateNotification failed for dispute update on auction %s: %v", auction.ID, err)
+		}
+	}
+}
+
+// disputeRole is the part the user plays in a dispute, empty if none
+func disputeRole(dispute *models.Dispute, user *models.User) string {
+	switch {
+	case user.ID == dispute.BuyerID:
+		return models.DisputeSenderBuyer
+	case user.ID == dispute.SellerID:
+		return models.DisputeSenderSeller
+	case user.IsAdmin:
+		return models.DisputeSenderAdmin
+	}
+	return ""
+}
+
+func disputePage(disputes []*models.Dispute, page *pagination.Params, total int) *pagination.Page[*models.Dispute] {
+	return pagination.NewPage(disputes, page, total, func(last *models.Dispute) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	})
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// FeedbackService lets the buyer and the seller of a completed order rate each
+// other once. Ratings can be changed for editWindow after they are left, and a
+// seller can answer a rating about them with a public reply. Cancelled orders
+// and unpaid auctions, which never get an order, can't be rated.
+type FeedbackService struct {
+	repo          store.FeedbackRepository
+	orderRepo     store.OrderRepository
+	userRepo      store.UserRepository
+	auctionRepo   store.AuctionRepository
+	notRepo       store.NotificationRepository
+	notifications chan<- *models.NotificationEvent
+	editWindow    time.Duration
+}
+
+func NewFeedbackService(repo store.FeedbackRepository, orderRepo store.OrderRepository, userRepo store.UserRepository, auctionRepo store.AuctionRepository, notRepo store.NotificationRepository, notifications chan<- *models.NotificationEvent, editWindow time.Duration) *FeedbackService {
+	return &FeedbackService{
+		repo:          repo,
+		orderRepo:     orderRepo,
+		userRepo:      userRepo,
+		auctionRepo:   auctionRepo,
+		notRepo:       notRepo,
+		notifications: notifications,
+		editWindow:    editWindow,
+	}
+}
+
+// LeaveFeedback rates the other party of a completed order
+func (f *FeedbackService) LeaveFeedback(ctx context.Context, orderID, userID string, req *models.FeedbackRequest) (*models.Feedback, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := f.orderRepo.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get order %s to leave feedback: %v", orderID, err)
+		return nil, errs.ErrFailedToSaveFeedback
+	}
+
+	feedback := &models.Feedback{
+		OrderID:    order.ID,
+		AuctionID:  order.AuctionID,
+		FromUserID: userID,
+		Rating:     req.Rating,
+		Comment:    strings.TrimSpace(req.Comment),
+	}
+
+	switch userID {
+	case order.BuyerID:
+		feedback.ToUserID, feedback.Role = order.SellerID, models.FeedbackRoleSeller
+	case order.SellerID:
+		feedback.ToUserID, feedback.Role = order.BuyerID, models.FeedbackRoleBuyer
+	default:
+		return nil, errs.ErrOrderNotFound
+	}
+
+	if order.Status != models.OrderCompleted {
+		return nil, errs.ErrFeedbackNotAllowed
+	}
+
+	if err := f.repo.CreateFeedback(ctx, feedback); err != nil {
+		if errors.Is(err, errs.ErrFeedbackAlreadyLeft) {
+			return nil, err
+		}
+		log.Printf("failed to save feedback of user %s on order %s: %v", userID, orderID, err)
+		return nil, errs.ErrFailedToSaveFeedback
+	}
+
+	f.notify(ctx, feedback, fmt.Sprintf("You received %s feedback as the %s", feedback.Rating, feedback.Role), feedback.ToUserID)
+
+	return feedback, nil
+}
+
+// UpdateFeedback lets the author change a rating within the edit window
+func (f *FeedbackService) UpdateFeedback(ctx context.Context, id, userID string, req *models.FeedbackRequest) (*models.Feedback, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	feedback, err := f.getFeedback(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedback.FromUserID != userID {
+		return nil, errs.ErrNotAllowedToUpdateFeedback
+	}
+	if time.Since(feedback.CreatedAt) > f.editWindow {
+		return nil, errs.ErrFeedbackEditWindowClosed
+	}
+
+	previous := feedback.Rating
+	feedback.Rating, feedback.Comment = req.Rating, strings.TrimSpace(req.Comment)
+	if err := f.repo.UpdateFeedback(ctx, feedback); err != nil {
+		log.Printf("failed to update feedback %s: %v", id, err)
+		return nil, errs.ErrFailedToSaveFeedback
+	}
+
+	if feedback.Rating != previous {
+		f.notify(ctx, feedback, fmt.Sprintf("Feedback you received as the %s was changed from %s to %s", feedback.Role, previous, feedback.Rating), feedback.ToUserID)
+	}
+
+	return feedback, nil
+}
+
+// ReplyToFeedback lets a seller publicly answer a rating about them, once
+func (f *FeedbackService) ReplyToFeedback(ctx context.Context, id, sellerID string, req *models.FeedbackReplyRequest) (*models.Feedback, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	feedback, err := f.getFeedback(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedback.ToUserID != sellerID || feedback.Role != models.FeedbackRoleSeller {
+		return nil, errs.ErrNotAllowedToReplyFeedback
+	}
+
+	feedback.Reply = strings.TrimSpace(req.Reply)
+	if err := f.repo.ReplyToFeedback(ctx, feedback); err != nil {
+		if errors.Is(err, errs.ErrFeedbackAlreadyReplied) {
+			return nil, err
+		}
+		log.Printf("failed to reply to feedback %s: %v", id, err)
+		return nil, errs.ErrFailedToSaveFeedback
+	}
+
+	f.notify(ctx, feedback, "The seller replied to your feedback", feedback.FromUserID)
+
+	return feedback, nil
+}
+
+// GetUserFeedback returns the feedback a user received as a seller or as a
+// buyer, newest first
+func (f *FeedbackService) GetUserFeedback(ctx context.Context, username, role string, page *pagination.Params) (*pagination.Page[*models.Feedback], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if role != models.FeedbackRoleSeller && role != models.FeedbackRoleBuyer {
+		return nil, errs.ErrInvalidFeedbackRole
+	}
+
+	user, err := f.userRepo.GetUserByUsername(ctx, strings.ToLower(username))
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get user %s to list feedback: %v", username, err)
+		return nil, errs.ErrFailedToRetrieveFeedback
+	}
+
+	feedback, total, err := f.repo.GetReceivedFeedback(ctx, user.ID, role, page)
+	if err != nil {
+		log.Printf("failed to get %s feedback of user %s: %v", role, user.ID, err)
+		return nil, errs.ErrFailedToRetrieveFeedback
+	}
+
+	return pagination.NewPage(feedback, page, total, func(last *models.Feedback) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
+
+func (f *FeedbackService) getFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+
+	feedback, err := f.repo.GetFeedback(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrFeedbackNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get feedback %s: %v", id, err)
+		return nil, errs.ErrFailedToRetrieveFeedback
+	}
+
+	return feedback, nil
+}
+
+func (f *FeedbackService) notify(ctx context.Context, feedback *models.Feedback, what, userID string) {
+
+	auction, err := f.auctionRepo.GetAuctionById(ctx, feedback.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to notify about feedback %s: %v", feedback.AuctionID, feedback.ID, err)
+		return
+	}
+
+	message := fmt.Sprintf("%s for %s", what, auction.Title)
+
+	f.notifications <- &models.NotificationEvent{
+		Type:      models.NotificationFeedback,
+		UserID:    userID,
+		Message:   message,
+		AuctionID: auction.ID,
+		TimeStamp: time.Now(),
+	}
+
+	not := &store.Notification{
+		UserID:    userID,
+		Message:   message,
+		AuctionID: auction.ID,
+		IsRead:    false,
+	}
+	if err := f.notRepo.CreateNotification(ctx, not); err != nil {
+		log.Printf("CreateNotification failed for feedback on auction %s: %v", auction.ID, err)
+	}
+}
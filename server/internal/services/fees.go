@@ -0,0 +1,45 @@
+package services
+
+import (
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+)
+
+// FeeService previews the fees of a sale from the fee schedules checkout and
+// the seller ledger charge
+type FeeService struct {
+	fees *payments.FeeSchedules
+}
+
+func NewFeeService(fees *payments.FeeSchedules) *FeeService {
+	return &FeeService{
+		fees: fees,
+	}
+}
+
+// PreviewFees shows a seller what a sale in the category at the given price
+// would cost the buyer and what the seller would net after fees
+func (f *FeeService) PreviewFees(sellerID string, req *models.FeePreviewRequest) *models.FeePreview {
+
+	premium, sellerFee := f.fees.For(req.Category, sellerID)
+	quote := premium.Quote(req.Price)
+
+	tiers := []models.FeeTierCharge{}
+	for _, charge := range sellerFee.Breakdown(quote.Price) {
+		tiers = append(tiers, models.FeeTierCharge{UpTo: charge.Tier.UpTo, Percent: charge.Tier.Percent, Amount: payments.FromCents(charge.Amount)})
+	}
+
+	fee := min(sellerFee.On(quote.Price), quote.Price)
+
+	return &models.FeePreview{
+		Price:          payments.FromCents(quote.Price),
+		Category:       req.Category,
+		Currency:       payments.DefaultCurrency,
+		BuyerPremium:   payments.FromCents(quote.Fee),
+		BuyerPays:      payments.FromCents(quote.Total),
+		SellerFee:      payments.FromCents(fee),
+		SellerFeeTiers: tiers,
+		SellerFeeFixed: sellerFee.Fixed,
+		NetProceeds:    payments.FromCents(quote.Price - fee),
+	}
+}
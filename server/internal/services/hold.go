@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// minHoldAmount is the smallest amount card providers accept, in cents
+const minHoldAmount = 50
+
+// HoldService manages the card holds bidders authorize before bidding at or
+// above an auction's hold threshold. When the auction closes the winner's hold
+// is captured as a deposit towards checkout and everyone else's is released.
+type HoldService struct {
+	provider    payments.PaymentProvider
+	repo        store.HoldRepository
+	auctionRepo store.AuctionRepository
+	percent     float64
+	validFor    time.Duration
+	returnURL   string
+}
+
+func NewHoldService(provider payments.PaymentProvider, repo store.HoldRepository, auctionRepo store.AuctionRepository, percent float64, validFor time.Duration, returnURL string) *HoldService {
+	return &HoldService{
+		provider:    provider,
+		repo:        repo,
+		auctionRepo: auctionRepo,
+		percent:     percent,
+		validFor:    validFor,
+		returnURL:   returnURL,
+	}
+}
+
+// CreateHold starts a card hold on an auction for the bidder. A bidder with a
+// pending or still valid hold gets that one back instead of a second hold.
+func (h *HoldService) CreateHold(ctx context.Context, auctionID, bidderID string) (*models.BidHoldResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	auction, err := h.auctionRepo.GetAuctionById(ctx, auctionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAuctionNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToCreateBidHold
+	}
+
+	if auction.HoldThreshold <= 0 {
+		return nil, errs.ErrBidHoldNotRequired
+	}
+	if auction.Status != "open" {
+		return nil, errs.ErrAuctionNotOpenForBids
+	}
+	if bidderID == auction.SellerID {
+		return nil, errs.ErrBidBySeller
+	}
+
+	existing, err := h.repo.GetLiveHold(ctx, auctionID, bidderID)
+	if err != nil && !errors.Is(err, errs.ErrBidHoldNotFound) {
+		log.Printf("failed to get hold of bidder %s on auction %s: %v", bidderID, auctionID, err)
+		return nil, errs.ErrFailedToCreateBidHold
+	}
+
+	if existing != nil {
+		remote, err := h.syncHold(ctx, existing)
+		if err != nil {
+			return nil, errs.ErrFailedToCreateBidHold
+		}
+
+		switch {
+		case existing.Status == models.HoldPending, existing.Status == models.HoldAuthorized && h.isValid(existing):
+			return h.holdResponse(existing, remote), nil
+		case existing.Status == models.HoldAuthorized:
+			// expired, the bidder authorizes a fresh one
+			h.releaseHold(ctx, existing)
+		}
+	}
+
+	amount := max(int64(math.Round(float64(payments.ToCents(auction.HoldThreshold))*h.percent/100)), minHoldAmount)
+	id := uuid.New().String()
+
+	remote, err := h.provider.CreateHold(ctx, &payments.HoldRequest{
+		HoldID:    id,
+		AuctionID: auctionID,
+		BidderID:  bidderID,
+		Name:      fmt.Sprintf("Bid hold: %s", auction.Title),
+		Amount:    amount,
+		ReturnURL: h.returnURL,
+	})
+	if err != nil {
+		log.Printf("failed to create %s hold: %v", h.provider.Name(), err)
+		return nil, errs.ErrFailedToCreateBidHold
+	}
+
+	hold := &models.BidHold{
+		ID:             id,
+		AuctionID:      auctionID,
+		BidderID:       bidderID,
+		Amount:         payments.FromCents(amount),
+		Provider:       h.provider.Name(),
+		ProviderHoldID: remote.ID,
+		Status:         models.HoldPending,
+	}
+
+	if err := h.repo.CreateHold(ctx, hold); err != nil {
+		log.Printf("failed to record hold of bidder %s on auction %s: %v", bidderID, auctionID, err)
+		// most likely a concurrent request recorded one first, don't leave this one open
+		if rerr := h.provider.ReleaseHold(ctx, remote.ID); rerr != nil {
+			log.Printf("failed to release %s hold %s: %v", h.provider.Name(), remote.ID, rerr)
+		}
+		return nil, errs.ErrFailedToCreateBidHold
+	}
+
+	return h.holdResponse(hold, remote), nil
+}
+
+// GetHold returns the bidder's current hold on an auction, refreshed from the provider
+func (h *HoldService) GetHold(ctx context.Context, auctionID, bidderID string) (*models.BidHoldResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	hold, err := h.repo.GetLiveHold(ctx, auctionID, bidderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrBidHoldNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get hold of bidder %s on auction %s: %v", bidderID, auctionID, err)
+		return nil, errs.ErrFailedToGetBidHold
+	}
+
+	remote, err := h.syncHold(ctx, hold)
+	if err != nil {
+		return nil, errs.ErrFailedToGetBidHold
+	}
+
+	return h.holdResponse(hold, remote), nil
+}
+
+// RequireHold rejects a bid at or above the auction's hold threshold unless
+// the bidder holds a valid authorization on it
+func (h *HoldService) RequireHold(ctx context.Context, auction *models.Auction, bidderID string, amount float64) error {
+
+	if auction.HoldThreshold <= 0 || amount < auction.HoldThreshold {
+		return nil
+	}
+
+	hold, err := h.repo.GetLiveHold(ctx, auction.ID, bidderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrBidHoldNotFound) {
+			return errs.ErrBidHoldRequired
+		}
+		log.Printf("failed to get hold of bidder %s on auction %s: %v", bidderID, auction.ID, err)
+		return errs.ErrFailedToGetBidHold
+	}
+
+	// the bidder may have just authorized it
+	if hold.Status == models.HoldPending {
+		if _, err := h.syncHold(ctx, hold); err != nil {
+			return errs.ErrFailedToGetBidHold
+		}
+	}
+
+	if hold.Status != models.HoldAuthorized || !h.isValid(hold) {
+		return errs.ErrBidHoldRequired
+	}
+
+	return nil
+}
+
+// SettleHolds captures the winner's hold on a closed auction and releases
+// every other one. It runs after the close so provider calls don't hold up the
+// request; a hold that fails to settle is logged and left as it was.
+func (h *HoldService) SettleHolds(auctionID, winnerID string) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryDefaultContext)
+	defer cancel()
+
+	holds, err := h.repo.GetLiveHolds(ctx, auctionID)
+	if err != nil {
+		log.Printf("failed to get holds on auction %s: %v", auctionID, err)
+		return
+	}
+
+	for _, hold := range holds {
+		if hold.Status == models.HoldCaptured {
+			continue
+		}
+
+		if hold.BidderID != winnerID || hold.Status != models.HoldAuthorized {
+			h.releaseHold(ctx, hold)
+			continue
+		}
+
+		remote, err := h.provider.CaptureHold(ctx, hold.ProviderHoldID)
+		if err != nil {
+			log.Printf("failed to capture %s hold %s of winner %s: %v", h.provider.Name(), hold.ProviderHoldID, winnerID, err)
+			continue
+		}
+
+		hold.Status = string(remote.Status)
+		if err := h.repo.UpdateHoldStatus(ctx, hold); err != nil {
+			log.Printf("failed to update hold %s: %v", hold.ID, err)
+			continue
+		}
+
+		log.Printf("captured %.2f hold of winner %s on auction %s", hold.Amount, winnerID, auctionID)
+	}
+}
+
+// Deposit is what was captured from the buyer's hold on an auction, in cents.
+// It is deducted from the checkout total.
+func (h *HoldService) Deposit(ctx context.Context, auctionID, buyerID string) (int64, error) {
+
+	hold, err := h.CapturedHold(ctx, auctionID, buyerID)
+	if err != nil || hold == nil {
+		return 0, err
+	}
+
+	return payments.ToCents(hold.Amount), nil
+}
+
+// CapturedHold is the buyer's hold on an auction the deposit was captured
+// from, or nil if there was none
+func (h *HoldService) CapturedHold(ctx context.Context, auctionID, buyerID string) (*models.BidHold, error) {
+
+	hold, err := h.repo.GetLiveHold(ctx, auctionID, buyerID)
+	if err != nil {
+		if errors.Is(err, errs.ErrBidHoldNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if hold.Status != models.HoldCaptured {
+		return nil, nil
+	}
+
+	return hold, nil
+}
+
+// syncHold refreshes the status of a pending or authorized hold from the provider
+func (h *HoldService) syncHold(ctx context.Context, hold *models.BidHold) (*payments.Hold, error) {
+
+	remote, err := h.provider.GetHold(ctx, hold.ProviderHoldID)
+	if err != nil {
+		log.Printf("failed to get %s hold %s: %v", h.provider.Name(), hold.ProviderHoldID, err)
+		return nil, err
+	}
+
+	if string(remote.Status) == hold.Status {
+		return remote, nil
+	}
+
+	hold.Status = string(remote.Status)
+	if err := h.repo.UpdateHoldStatus(ctx, hold); err != nil {
+		log.Printf("failed to update hold %s: %v", hold.ID, err)
+		return nil, err
+	}
+
+	return remote, nil
+}
+
+func (h *HoldService) releaseHold(ctx context.Context, hold *models.BidHold) {
+
+	if err := h.provider.ReleaseHold(ctx, hold.ProviderHoldID); err != nil {
+		log.Printf("failed to release %s hold %s: %v", h.provider.Name(), hold.ProviderHoldID, err)
+		return
+	}
+
+	hold.Status = models.HoldReleased
+	if err := h.repo.UpdateHoldStatus(ctx, hold); err != nil {
+		log.Printf("failed to update hold %s: %v", hold.ID, err)
+	}
+}
+
+// isValid reports whether an authorized hold can still be relied on
+func (h *HoldService) isValid(hold *models.BidHold) bool {
+	return hold.AuthorizedAt != nil && time.Since(*hold.AuthorizedAt) < h.validFor
+}
+
+func (h *HoldService) holdResponse(hold *models.BidHold, remote *payments.Hold) *models.BidHoldResponse {
+
+	res := &models.BidHoldResponse{BidHold: hold}
+
+	switch hold.Status {
+	case models.HoldPending:
+		res.AuthorizeURL = remote.URL
+	case models.HoldAuthorized:
+		if hold.AuthorizedAt != nil {
+			validUntil := hold.AuthorizedAt.Add(h.validFor)
+			res.ValidUntil = &validUntil
+		}
+	}
+
+	return res
+}
@@ -2,20 +2,26 @@ package services
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"github.com/puremike/online_auction_api/internal/models"
-	"github.com/stripe/stripe-go/v82"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/payments"
 )
 
 type UserServiceInterface interface {
 	CreateUser(ctx context.Context, user *models.User) (*models.UserResponse, error)
-	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	Login(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error)
+	AdminLogin(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error)
+	CompleteTwoFactorLogin(ctx context.Context, req *models.TwoFactorLoginRequest, client *models.Client) (*models.LoginResponse, error)
 	UserProfile(ctx context.Context, username string) (*models.UserResponse, error)
 	MeProfile(ctx context.Context, userID string) (*models.UserResponse, error)
-	Refresh(ctx context.Context, refreshToken string) (string, error)
+	Refresh(ctx context.Context, refreshToken string, client *models.Client) (*models.LoginResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
 	UpdateProfile(ctx context.Context, req *models.User, id string) (string, error)
-	ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string) (string, error)
-	GetUsers(ctx context.Context) (*[]models.UserResponse, error)
+	ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string, client *models.Client) (*models.LoginResponse, error)
+	GetUsers(ctx context.Context, page *pagination.Params) (*pagination.Page[models.UserResponse], error)
 	DeleteUser(ctx context.Context, id string) (string, error)
 }
 
@@ -24,23 +30,136 @@ type AuctionServiceInterface interface {
 	UpdateAuction(ctx context.Context, req *models.Auction, id string) (string, error)
 	DeleteAuction(ctx context.Context, id string) (string, error)
 	GetAuctionById(ctx context.Context, id string) (*models.CreateAuctionResponse, error)
-	GetAuctions(ctx context.Context, limit, offset int, filter *models.AuctionFilter) (*[]models.CreateAuctionResponse, error)
-	GetAuctionsBySellerID(ctx context.Context, sellerID string) (*[]models.CreateAuctionResponse, error)
-	GetWonAuctionsByWinnerID(ctx context.Context, winnerID string) (*[]models.CreateAuctionResponse, error)
-	GetBiddedAuctionsForUser(ctx context.Context, bidderID string) (*[]models.Auction, error)
+	GetAuctions(ctx context.Context, page *pagination.Params, filter *models.AuctionFilter) (*pagination.Page[models.CreateAuctionResponse], error)
+	GetAuctionsBySellerID(ctx context.Context, sellerID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error)
+	GetWonAuctionsByWinnerID(ctx context.Context, winnerID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error)
+	GetBiddedAuctionsForUser(ctx context.Context, bidderID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error)
 	PlaceBid(ctx context.Context, req *models.PlaceBidRequest) (*models.BidResponse, error)
 	CloseAuction(ctx context.Context, auctionID string, requestingUserID string) (*models.WinnerResponse, error)
 }
 
+type WatchlistServiceInterface interface {
+	WatchAuction(ctx context.Context, req *models.Watch, sellerID string) (*models.WatchResponse, error)
+	UnwatchAuction(ctx context.Context, userID, auctionID string) (string, error)
+	GetWatchedAuctions(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error)
+}
+
+type SavedSearchServiceInterface interface {
+	CreateSavedSearch(ctx context.Context, req *models.SavedSearch) (*models.SavedSearch, error)
+	GetSavedSearches(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.SavedSearch], error)
+	GetSavedSearch(ctx context.Context, id, userID string) (*models.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, req *models.SavedSearch) (*models.SavedSearch, error)
+	SetSavedSearchAlerts(ctx context.Context, id, userID string, enabled bool) (*models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id, userID string) (string, error)
+}
+
 type CSServiceInterface interface {
 	ContactSupport(ctx context.Context, req *models.ContactSupport) (*models.SupportRes, error)
 }
 
+type LedgerServiceInterface interface {
+	GetBalance(ctx context.Context, sellerID string) (*models.Balance, error)
+	RequestPayout(ctx context.Context, sellerID string) (*models.Payout, error)
+	GetPayouts(ctx context.Context, sellerID string, page *pagination.Params) (*pagination.Page[*models.Payout], error)
+}
+
+type HoldServiceInterface interface {
+	CreateHold(ctx context.Context, auctionID, bidderID string) (*models.BidHoldResponse, error)
+	GetHold(ctx context.Context, auctionID, bidderID string) (*models.BidHoldResponse, error)
+}
+
+type AddressServiceInterface interface {
+	CreateAddress(ctx context.Context, userID string, req *models.AddressRequest) (*models.Address, error)
+	GetAddresses(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Address], error)
+	UpdateAddress(ctx context.Context, id, userID string, req *models.AddressRequest) (*models.Address, error)
+	DeleteAddress(ctx context.Context, id, userID string) (string, error)
+}
+
+type ShippingServiceInterface interface {
+	SelectShipping(ctx context.Context, auctionID, buyerID string, req *models.ShippingSelectionRequest) (*models.ShippingSelection, error)
+	GetShipping(ctx context.Context, auctionID string, user *models.User) (*models.ShippingSelection, error)
+}
+
+type OrderServiceInterface interface {
+	GetOrder(ctx context.Context, id string, user *models.User) (*models.Order, error)
+	GetOrders(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Order], error)
+	ShipOrder(ctx context.Context, id, sellerID string, req *models.ShipOrderRequest) (*models.Order, error)
+	DeliverOrder(ctx context.Context, id, userID string) (*models.Order, error)
+	CompleteOrder(ctx context.Context, id, buyerID string) (*models.Order, error)
+	AutoCompleteOrders(ctx context.Context, shippedFor, deliveredFor time.Duration) (int, error)
+}
+
+type DisputeServiceInterface interface {
+	OpenDispute(ctx context.Context, orderID, buyerID string, req *models.OpenDisputeRequest) (*models.Dispute, error)
+	GetDispute(ctx context.Context, id string, user *models.User) (*models.Dispute, error)
+	GetDisputes(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Dispute], error)
+	AdminGetDisputes(ctx context.Context, status string, page *pagination.Params) (*pagination.Page[*models.Dispute], error)
+	GetMessages(ctx context.Context, id string, user *models.User, page *pagination.Params) (*pagination.Page[*models.DisputeMessage], error)
+	PostMessage(ctx context.Context, id string, user *models.User, req *models.DisputeMessageRequest) (*models.DisputeMessage, error)
+	AddEvidence(ctx context.Context, id string, user *models.User, imagePath string) (*models.DisputeMessage, error)
+	AcceptDispute(ctx context.Context, id string, seller *models.User) (*models.Dispute, error)
+	RequestReturn(ctx context.Context, id, sellerID string) (*models.Dispute, error)
+	ShipReturn(ctx context.Context, id, buyerID string, req *models.ReturnShipmentRequest) (*models.Dispute, error)
+	ConfirmReturn(ctx context.Context, id string, seller *models.User) (*models.Dispute, error)
+	Escalate(ctx context.Context, id, userID string) (*models.Dispute, error)
+	ResolveDispute(ctx context.Context, id string, admin *models.User, req *models.ResolveDisputeRequest) (*models.Dispute, error)
+	EscalateOverdueDisputes(ctx context.Context) (int, error)
+}
+
+type FeedbackServiceInterface interface {
+	LeaveFeedback(ctx context.Context, orderID, userID string, req *models.FeedbackRequest) (*models.Feedback, error)
+	UpdateFeedback(ctx context.Context, id, userID string, req *models.FeedbackRequest) (*models.Feedback, error)
+	ReplyToFeedback(ctx context.Context, id, sellerID string, req *models.FeedbackReplyRequest) (*models.Feedback, error)
+	GetUserFeedback(ctx context.Context, username, role string, page *pagination.Params) (*pagination.Page[*models.Feedback], error)
+}
+
+type FeeServiceInterface interface {
+	PreviewFees(sellerID string, req *models.FeePreviewRequest) *models.FeePreview
+}
+
+type InvoiceServiceInterface interface {
+	GetInvoice(ctx context.Context, orderID string, user *models.User) (*models.Invoice, error)
+}
+
 type PaymentServiceInterface interface {
-	CreatePaymentCheckout(ctx context.Context, amount int64, orderID, buyerID, auctionID string) (*stripe.CheckoutSession, error)
-	HandleCheckoutSessionCompleted(ctx context.Context, event *stripe.Event, session *stripe.CheckoutSession) error
-	HandlePaymentIntentSucceeded(ctx context.Context, event *stripe.Event, pi *stripe.PaymentIntent) error
-	HandlePaymentIntentFailed(ctx context.Context, event *stripe.Event, pi *stripe.PaymentIntent) error
+	CreatePaymentCheckout(ctx context.Context, auctionID, buyerID string) (*models.CreatePaymentResponse, error)
+	ParseWebhook(payload []byte, header http.Header) (*payments.WebhookEvent, error)
+	ProcessWebhook(ctx context.Context, event *payments.WebhookEvent) error
+	ReplayWebhookEvent(ctx context.Context, id string) (*models.WebhookEvent, error)
+	GetWebhookEvents(ctx context.Context, status string, page *pagination.Params) (*pagination.Page[*models.WebhookEvent], error)
+	RefundPayment(ctx context.Context, orderID string, user *models.User, req *models.CreateRefundRequest) ([]*models.PaymentRefund, error)
+	GetRefunds(ctx context.Context, orderID string, user *models.User) ([]*models.PaymentRefund, error)
+	ReconcilePayments(ctx context.Context, pendingAge, abandonAfter time.Duration) (*models.ReconciliationReport, error)
+	GetPaymentMismatches(ctx context.Context, action string, page *pagination.Params) (*pagination.Page[*models.PaymentMismatch], error)
 	GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error)
-	UpdateAuctionPayment(ctx context.Context, isPaid bool, id string) error
+	GetPaymentStatus(ctx context.Context, orderID string, user *models.User) (*models.PaymentStatusResponse, error)
+	GetPaymentStatusBySession(ctx context.Context, sessionID string, user *models.User) (*models.PaymentStatusResponse, error)
+}
+
+type VerificationServiceInterface interface {
+	SendVerification(ctx context.Context, userID, email string) error
+	ResendVerification(ctx context.Context, user *models.User) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+type PasswordResetServiceInterface interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+}
+
+type TwoFactorServiceInterface interface {
+	Setup(ctx context.Context, user *models.User) (*models.TwoFactorSetupResponse, error)
+	Confirm(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error)
+	Enabled(ctx context.Context, userID string) (bool, error)
+	Challenge(ctx context.Context, user *models.User) (string, error)
+	CompleteChallenge(ctx context.Context, challenge, code string) (string, error)
+}
+
+type SessionServiceInterface interface {
+	GetSessions(ctx context.Context, userID, refreshToken string) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) (int64, error)
+	AdminGetSessions(ctx context.Context, userID string) ([]*models.Session, error)
 }
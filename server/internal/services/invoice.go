@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// InvoiceService issues the invoices of completed payments and serves them to
+// the buyer, the seller and admins
+type InvoiceService struct {
+	repo        store.InvoiceRepository
+	paymentRepo store.PaymentRepository
+	auctionRepo store.AuctionRepository
+	userRepo    store.UserRepository
+}
+
+func NewInvoiceService(repo store.InvoiceRepository, paymentRepo store.PaymentRepository, auctionRepo store.AuctionRepository, userRepo store.UserRepository) *InvoiceService {
+	return &InvoiceService{
+		repo:        repo,
+		paymentRepo: paymentRepo,
+		auctionRepo: auctionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// IssueInvoice issues the invoice of a completed payment. Issuing it again
+// returns the invoice already issued.
+func (i *InvoiceService) IssueInvoice(ctx context.Context, payment *models.Payment) (*models.Invoice, error) {
+
+	auction, err := i.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to invoice payment %s: %v", payment.AuctionID, payment.ID, err)
+		return nil, errs.ErrFailedToIssueInvoice
+	}
+
+	buyer, err := i.userRepo.GetUserById(ctx, payment.BuyerID)
+	if err != nil {
+		log.Printf("failed to get buyer %s to invoice payment %s: %v", payment.BuyerID, payment.ID, err)
+		return nil, errs.ErrFailedToIssueInvoice
+	}
+
+	seller, err := i.userRepo.GetUserById(ctx, auction.SellerID)
+	if err != nil {
+		log.Printf("failed to get seller %s to invoice payment %s: %v", auction.SellerID, payment.ID, err)
+		return nil, errs.ErrFailedToIssueInvoice
+	}
+
+	reference := payment.PaymentIntentID
+	if reference == "" {
+		reference = payment.SessionID
+	}
+
+	price := payments.FromCents(finalPrice(payment))
+
+	invoice := &models.Invoice{
+		PaymentID:        payment.ID,
+		AuctionID:        auction.ID,
+		OrderID:          payment.OrderID,
+		BuyerID:          buyer.ID,
+		BuyerName:        buyer.FullName,
+		BuyerEmail:       buyer.Email,
+		SellerID:         seller.ID,
+		SellerName:       seller.FullName,
+		SellerEmail:      seller.Email,
+		AuctionTitle:     auction.Title,
+		Currency:         payments.DefaultCurrency,
+		Lines:            []models.InvoiceLine{{Kind: models.InvoiceLineItem, Description: auction.Title, Amount: price}},
+		Subtotal:         price,
+		Fees:             payment.Fee,
+		Shipping:         payment.Shipping,
+		Tax:              payment.Tax,
+		Total:            payments.FromCents(payments.ToCents(payment.Amount) + payments.ToCents(payment.Deposit)), // the deposit was paid when the auction closed
+		PaymentReference: reference,
+	}
+	if payment.Fee > 0 {
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{Kind: models.InvoiceLineFee, Description: "Buyer's premium", Amount: payment.Fee})
+	}
+	if payment.Shipping > 0 {
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{Kind: models.InvoiceLineShipping, Description: shippingLabel(payment.ShippingMethod), Amount: payment.Shipping})
+	}
+	for _, line := range payment.TaxLines {
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{Kind: models.InvoiceLineTax, Description: fmt.Sprintf("%s (%g%%)", line.Name, line.Rate), Amount: line.Amount})
+	}
+
+	if err := i.repo.CreateInvoice(ctx, invoice); err != nil {
+		log.Printf("failed to issue invoice for payment %s: %v", payment.ID, err)
+		return nil, errs.ErrFailedToIssueInvoice
+	}
+
+	return invoice, nil
+}
+
+// GetInvoice returns the invoice of a payment to its buyer, the seller of the
+// auction or an admin. A paid payment that is missing its invoice, e.g.
+// because issuing failed when it completed, is invoiced now.
+func (i *InvoiceService) GetInvoice(ctx context.Context, orderID string, user *models.User) (*models.Invoice, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payment, err := i.paymentRepo.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return nil, errs.ErrInvoiceNotFound
+		}
+		log.Printf("failed to get payment for order %s: %v", orderID, err)
+		return nil, errs.ErrFailedToGetPayment
+	}
+
+	auction, err := i.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s: %v", payment.AuctionID, err)
+		return nil, errs.ErrFailedToGetPayment
+	}
+
+	if !user.IsAdmin && payment.BuyerID != user.ID && auction.SellerID != user.ID {
+		return nil, errs.ErrInvoiceNotFound
+	}
+
+	invoice, err := i.repo.GetInvoiceByPaymentID(ctx, payment.ID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, errs.ErrInvoiceNotFound) {
+		log.Printf("failed to get invoice of payment %s: %v", payment.ID, err)
+		return nil, errs.ErrFailedToIssueInvoice
+	}
+
+	switch payment.Status {
+	case PaymentStatusCompleted, PaymentStatusPartiallyRefunded, PaymentStatusRefunded:
+		return i.IssueInvoice(ctx, payment)
+	}
+
+	return nil, errs.ErrPaymentNotCompleted
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// LedgerService keeps track of what sellers are owed. A completed payment
+// credits the seller with the final price minus the platform fee; the funds are
+// held until the order completes, or until the hold period passes for payments
+// that have no order, after which the seller can have them paid out.
+type LedgerService struct {
+	repo        store.LedgerRepository
+	auctionRepo store.AuctionRepository
+	payouts     payments.PayoutProvider
+	fees        *payments.FeeSchedules
+	holdPeriod  time.Duration
+}
+
+func NewLedgerService(repo store.LedgerRepository, auctionRepo store.AuctionRepository, payouts payments.PayoutProvider, fees *payments.FeeSchedules, holdPeriod time.Duration) *LedgerService {
+	return &LedgerService{
+		repo:        repo,
+		auctionRepo: auctionRepo,
+		payouts:     payouts,
+		fees:        fees,
+		holdPeriod:  holdPeriod,
+	}
+}
+
+// CreditSale credits the seller of a completed payment. Crediting the same
+// payment again is a no-op.
+func (l *LedgerService) CreditSale(ctx context.Context, payment *models.Payment) error {
+
+	auction, err := l.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to credit payment %s: %v", payment.AuctionID, payment.ID, err)
+		return errs.ErrFailedToUpdatePayment
+	}
+
+	// the buyer fee is the platform's and the tax is remitted, the seller is owed
+	// the final price and the shipping they pay for, with fees on the price only
+	gross := finalPrice(payment)
+	_, sellerFee := l.fees.For(auction.Category, auction.SellerID)
+	fee := min(sellerFee.On(gross), gross)
+
+	entry := &models.LedgerEntry{
+		SellerID:    auction.SellerID,
+		Amount:      payments.FromCents(gross - fee + payments.ToCents(payment.Shipping)),
+		Gross:       payments.FromCents(gross),
+		PlatformFee: payments.FromCents(fee),
+		PaymentID:   &payment.ID,
+		AuctionID:   &auction.ID,
+		AvailableAt: time.Now().Add(l.holdPeriod),
+	}
+
+	credited, err := l.repo.CreditSale(ctx, entry)
+	if err != nil {
+		log.Printf("failed to credit seller %s for payment %s: %v", auction.SellerID, payment.ID, err)
+		return errs.ErrFailedToUpdatePayment
+	}
+
+	if credited {
+		log.Printf("credited seller %s %.2f for payment %s, held until %s", entry.SellerID, entry.Amount, payment.ID, entry.AvailableAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// DebitRefund takes the seller's share of a refund back out of their balance
+func (l *LedgerService) DebitRefund(ctx context.Context, payment *models.Payment, refund *models.PaymentRefund) {
+	if err := l.repo.DebitRefund(ctx, payment.ID, refund.ID, refund.Amount); err != nil {
+		log.Printf("failed to debit refund %s of payment %s from the seller: %v", refund.ID, payment.ID, err)
+	}
+}
+
+func (l *LedgerService) GetBalance(ctx context.Context, sellerID string) (*models.Balance, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	balance, err := l.repo.GetBalance(ctx, sellerID)
+	if err != nil {
+		log.Printf("failed to get balance of seller %s: %v", sellerID, err)
+		return nil, errs.ErrFailedToGetBalance
+	}
+
+	balance.Currency = payments.DefaultCurrency
+
+	return balance, nil
+}
+
+// RequestPayout pays out the seller's whole available balance. A payout the
+// provider refuses is marked failed and its amount credited back.
+func (l *LedgerService) RequestPayout(ctx context.Context, sellerID string) (*models.Payout, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payout, err := l.repo.CreatePayout(ctx, sellerID, l.payouts.Name())
+	if err != nil {
+		if errors.Is(err, errs.ErrNothingToPayOut) {
+			return nil, err
+		}
+		log.Printf("failed to create payout for seller %s: %v", sellerID, err)
+		return nil, errs.ErrFailedToPayOut
+	}
+
+	res, err := l.payouts.Payout(ctx, &payments.PayoutRequest{
+		PayoutID: payout.ID,
+		SellerID: sellerID,
+		Amount:   payments.ToCents(payout.Amount),
+		Currency: payments.DefaultCurrency,
+	})
+	if err != nil {
+		log.Printf("%s payout %s failed: %v", l.payouts.Name(), payout.ID, err)
+		payout.Error = err.Error()
+		if err := l.repo.FailPayout(ctx, payout); err != nil {
+			log.Printf("failed to mark payout %s failed: %v", payout.ID, err)
+		}
+		return nil, errs.ErrFailedToPayOut
+	}
+
+	payout.ProviderPayoutID = res.ID
+	if err := l.repo.CompletePayout(ctx, payout); err != nil {
+		log.Printf("failed to mark payout %s (%s) paid: %v", payout.ID, res.ID, err)
+	}
+
+	return payout, nil
+}
+
+func (l *LedgerService) GetPayouts(ctx context.Context, sellerID string, page *pagination.Params) (*pagination.Page[*models.Payout], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payouts, total, err := l.repo.GetPayouts(ctx, sellerID, page)
+	if err != nil {
+		log.Printf("failed to get payouts of seller %s: %v", sellerID, err)
+		return nil, errs.ErrFailedToRetrievePayouts
+	}
+
+	return pagination.NewPage(payouts, page, total, func(last *models.Payout) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
@@ -0,0 +1,30 @@
+package mock_services
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/cached"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ cached.CachedUserInterface = (*MockCachedUser)(nil)
+
+type MockCachedUser struct {
+	mock.Mock
+}
+
+func (m *MockCachedUser) GetUserFromCache(ctx context.Context, userId string) (*models.User, error) {
+	ret := m.Called(ctx, userId)
+	return ret.Get(0).(*models.User), ret.Error(1)
+}
+
+func (m *MockCachedUser) DeleteUserFromCache(ctx context.Context, userId string) error {
+	ret := m.Called(ctx, userId)
+	return ret.Error(0)
+}
+
+func (m *MockCachedUser) EvictUser(ctx context.Context, userId string) error {
+	ret := m.Called(ctx, userId)
+	return ret.Error(0)
+}
@@ -0,0 +1,100 @@
+package mock_services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sellerBook follows a sale entry and its refund debits the way
+// LedgerStore.DebitRefund computes them: the refund's share of everything the
+// buyer paid, capped at what is left of the sale, and whatever is left once the
+// payment is fully refunded. Amounts are in cents.
+type sellerBook struct {
+	paid, credited, left int64
+	status               string
+}
+
+func (b *sellerBook) debit(refund int64) {
+	share := b.left
+	if b.status != services.PaymentStatusRefunded {
+		share = min(int64(math.Round(float64(b.credited)*float64(refund)/float64(b.paid))), b.left)
+	}
+	b.left -= share
+}
+
+func TestLedger_RefundsLeaveSellerAtZero(t *testing.T) {
+	require := require.New(t)
+
+	paymentService, m := newRefundPaymentService()
+	seller := &models.User{ID: "seller-1"}
+
+	fees := &payments.FeeSchedules{Default: payments.FeeSchedule{SellerFee: &payments.TieredFee{Tiers: []payments.Tier{{Percent: 7.77}}}}}
+	ledger := services.NewLedgerService(m.ledger, m.auctions, nil, fees, 0)
+
+	payment := completedPayment(0)
+	book := &sellerBook{paid: payments.ToCents(payment.Amount), status: payment.Status}
+
+	m.ledger.
+		On("CreditSale", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			book.credited = payments.ToCents(args.Get(1).(*models.LedgerEntry).Amount)
+			book.left = book.credited
+		}).
+		Return(true, nil).Once()
+
+	require.NoError(ledger.CreditSale(context.Background(), payment))
+	require.Equal(int64(92_23), book.left, "Expected the seller credited the price less 7.77%")
+
+	m.ledger.
+		On("DebitRefund", mock.Anything, "payment-1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			book.debit(payments.ToCents(args.Get(3).(float64)))
+		}).
+		Return(nil)
+	m.provider.
+		On("Refund", mock.Anything, mock.Anything).
+		Return(&payments.Refund{ID: "re_1", Status: "succeeded"}, nil)
+	m.payments.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil)
+
+	refunds := []struct {
+		amount float64 // 0 refunds the rest
+		status string
+	}{
+		{33.33, services.PaymentStatusPartiallyRefunded},
+		{33.33, services.PaymentStatusPartiallyRefunded},
+		{0, services.PaymentStatusRefunded},
+	}
+
+	for i, r := range refunds {
+		m.payments.On("GetPaymentByOrderID", mock.Anything, "order-1").Return(completedPayment(payment.RefundedAmount), nil).Once()
+		m.payments.
+			On("CreateRefund", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				refund := args.Get(1).(*models.PaymentRefund)
+				refund.ID = fmt.Sprintf("refund-%d", i+1)
+				payment.RefundedAmount = payments.FromCents(payments.ToCents(payment.RefundedAmount) + payments.ToCents(refund.Amount))
+				book.status = r.status
+			}).
+			Return(&models.Payment{ID: "payment-1", AuctionID: "auction-1", BuyerID: "buyer-1", Status: r.status}, nil).Once()
+
+		_, err := paymentService.RefundPayment(context.Background(), "order-1", seller, &models.CreateRefundRequest{Amount: r.amount, Reason: "damaged"})
+		require.NoError(err, "Expected refund %d to succeed", i+1)
+
+		if r.status != services.PaymentStatusRefunded {
+			assert.Positive(t, book.left, "Expected the seller to keep part of the sale after a partial refund")
+		}
+	}
+
+	assert.Equal(t, 100.0, payment.RefundedAmount)
+	assert.Zero(t, book.left, "Expected nothing left of the sale after the final refund")
+	m.ledger.AssertNumberOfCalls(t, "DebitRefund", 3)
+}
@@ -0,0 +1,23 @@
+package mock_services
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/mailer"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ mailer.Mailer = (*MockMailer)(nil)
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Name() string {
+	return "mock"
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	ret := m.Called(ctx, msg)
+	return ret.Error(0)
+}
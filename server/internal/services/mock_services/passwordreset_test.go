@@ -0,0 +1,119 @@
+package mock_services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/mailer"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/puremike/online_auction_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPasswordResetService() (*services.PasswordResetService, *mock_store.MockUserTokenStore, *mock_store.MockUserStore, *MockMailer, *MockCachedUser) {
+
+	mockTokens := new(mock_store.MockUserTokenStore)
+	mockUsers := new(mock_store.MockUserStore)
+	mockMailer := new(MockMailer)
+	mockCached := new(MockCachedUser)
+
+	return services.NewPasswordResetService(mockTokens, mockUsers, mockMailer, mockCached, time.Hour, "http://localhost:3000/reset-password"), mockTokens, mockUsers, mockMailer, mockCached
+}
+
+func TestForgotPassword_SameAnswerForUnknownEmail(t *testing.T) {
+	assert := assert.New(t)
+
+	resetService, mockTokens, mockUsers, mockMailer, _ := newPasswordResetService()
+
+	looked := make(chan string, 2)
+	mailed := make(chan *mailer.Message, 1)
+
+	mockUsers.
+		On("GetUserByEmail", mock.Anything, "known@example.com").
+		Run(func(args mock.Arguments) { looked <- args.String(1) }).
+		Return(&models.User{ID: "test-id-1", Email: "known@example.com"}, nil).Once()
+	mockUsers.
+		On("GetUserByEmail", mock.Anything, "unknown@example.com").
+		Run(func(args mock.Arguments) { looked <- args.String(1) }).
+		Return((*models.User)(nil), errs.ErrUserNotFound).Once()
+	mockTokens.
+		On("CreateToken", mock.Anything, mock.MatchedBy(func(token *models.UserToken) bool {
+			return token.UserID == "test-id-1" && token.Purpose == models.TokenPasswordReset
+		})).
+		Return(nil).Once()
+	mockMailer.
+		On("Send", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { mailed <- args.Get(1).(*mailer.Message) }).
+		Return(nil).Once()
+
+	known := resetService.ForgotPassword(context.Background(), "Known@example.com")
+	unknown := resetService.ForgotPassword(context.Background(), "unknown@example.com")
+	assert.NoError(known)
+	assert.Equal(known, unknown, "Expected the same answer whether or not the email has an account")
+
+	for range 2 {
+		select {
+		case <-looked:
+		case <-time.After(time.Second):
+			t.Fatal("Expected both emails to be looked up")
+		}
+	}
+
+	select {
+	case msg := <-mailed:
+		assert.Equal("known@example.com", msg.To)
+		assert.True(strings.Contains(msg.Body, "?token="), "Expected the mail to carry the reset link")
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reset link mailed to the known email")
+	}
+
+	mockTokens.AssertNumberOfCalls(t, "CreateToken", 1)
+	mockMailer.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestResetPassword_UsedOrExpiredToken(t *testing.T) {
+	assert := assert.New(t)
+
+	resetService, mockTokens, _, _, mockCached := newPasswordResetService()
+
+	// the store reports a used, expired or unknown token alike
+	mockTokens.
+		On("ResetPassword", mock.Anything, utils.HashToken("spent-token"), mock.Anything).
+		Return("", errs.ErrInvalidToken).Once()
+
+	err := resetService.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "spent-token", NewPassword: "@NewPassword123", ConfirmPassword: "@NewPassword123"})
+	assert.ErrorIs(err, errs.ErrInvalidToken)
+
+	mockTokens.AssertExpectations(t)
+	mockCached.AssertNotCalled(t, "EvictUser", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_SignsOutEverywhere(t *testing.T) {
+	require := require.New(t)
+
+	resetService, mockTokens, _, _, mockCached := newPasswordResetService()
+
+	// the store revokes the user's sessions in the transaction that sets the password
+	var stored string
+	mockTokens.
+		On("ResetPassword", mock.Anything, utils.HashToken("reset-token"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { stored = args.String(2) }).
+		Return("test-id-1", nil).Once()
+	mockCached.
+		On("EvictUser", mock.Anything, "test-id-1").
+		Return(nil).Once()
+
+	err := resetService.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "reset-token", NewPassword: "@NewPassword123", ConfirmPassword: "@NewPassword123"})
+	require.NoError(err, "Expected no error when resetting the password")
+	require.NoError(utils.CompareHashedPassword(stored, "@NewPassword123"), "Expected the new password to be stored hashed")
+
+	mockTokens.AssertExpectations(t)
+	mockCached.AssertExpectations(t)
+}
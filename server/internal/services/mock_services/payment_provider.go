@@ -0,0 +1,64 @@
+package mock_services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ payments.PaymentProvider = (*MockPaymentProvider)(nil)
+
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+func (m *MockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockPaymentProvider) CreateCheckout(ctx context.Context, req *payments.CheckoutRequest) (*payments.CheckoutSession, error) {
+	ret := m.Called(ctx, req)
+	return ret.Get(0).(*payments.CheckoutSession), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) GetSession(ctx context.Context, sessionID string) (*payments.CheckoutSession, error) {
+	ret := m.Called(ctx, sessionID)
+	return ret.Get(0).(*payments.CheckoutSession), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) ExpireSession(ctx context.Context, sessionID string) error {
+	ret := m.Called(ctx, sessionID)
+	return ret.Error(0)
+}
+
+func (m *MockPaymentProvider) ParseWebhook(payload []byte, header http.Header) (*payments.WebhookEvent, error) {
+	ret := m.Called(payload, header)
+	return ret.Get(0).(*payments.WebhookEvent), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) Refund(ctx context.Context, req *payments.RefundRequest) (*payments.Refund, error) {
+	ret := m.Called(ctx, req)
+	return ret.Get(0).(*payments.Refund), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) CreateHold(ctx context.Context, req *payments.HoldRequest) (*payments.Hold, error) {
+	ret := m.Called(ctx, req)
+	return ret.Get(0).(*payments.Hold), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) GetHold(ctx context.Context, holdID string) (*payments.Hold, error) {
+	ret := m.Called(ctx, holdID)
+	return ret.Get(0).(*payments.Hold), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) CaptureHold(ctx context.Context, holdID string) (*payments.Hold, error) {
+	ret := m.Called(ctx, holdID)
+	return ret.Get(0).(*payments.Hold), ret.Error(1)
+}
+
+func (m *MockPaymentProvider) ReleaseHold(ctx context.Context, holdID string) error {
+	ret := m.Called(ctx, holdID)
+	return ret.Error(0)
+}
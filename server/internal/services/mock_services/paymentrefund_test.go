@@ -0,0 +1,194 @@
+package mock_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type refundMocks struct {
+	provider      *MockPaymentProvider
+	payments      *mock_store.MockPaymentStore
+	events        *mock_store.MockWebhookEventStore
+	auctions      *mock_store.MockAuctionStore
+	notifications *mock_store.MockNotificationStore
+	ledger        *mock_store.MockLedgerStore
+}
+
+func newRefundPaymentService() (*services.PaymentService, *refundMocks) {
+
+	m := &refundMocks{
+		provider:      new(MockPaymentProvider),
+		payments:      new(mock_store.MockPaymentStore),
+		events:        new(mock_store.MockWebhookEventStore),
+		auctions:      new(mock_store.MockAuctionStore),
+		notifications: new(mock_store.MockNotificationStore),
+		ledger:        new(mock_store.MockLedgerStore),
+	}
+
+	m.auctions.
+		On("GetAuctionById", mock.Anything, "auction-1").
+		Return(&models.Auction{ID: "auction-1", Title: "Lamp", SellerID: "seller-1"}, nil)
+	m.notifications.
+		On("CreateNotification", mock.Anything, mock.Anything).
+		Return(nil)
+
+	ledger := services.NewLedgerService(m.ledger, m.auctions, nil, nil, 0)
+	notifications := make(chan *models.NotificationEvent, 10)
+
+	return services.NewPaymentService(m.provider, m.payments, m.auctions, nil, m.events, nil, m.notifications, notifications, ledger, nil, nil, nil, nil, nil, nil), m
+}
+
+func completedPayment(refunded float64) *models.Payment {
+	return &models.Payment{ID: "payment-1", AuctionID: "auction-1", BuyerID: "buyer-1", OrderID: "order-1", SessionID: "cs_1", Amount: 100, Status: services.PaymentStatusCompleted, RefundedAmount: refunded}
+}
+
+// expectRefund reserves a refund of amount on the payment, giving it refundID,
+// and returns the payment with the status it moves to
+func (m *refundMocks) expectRefund(refundID string, amount float64, status string) {
+	m.payments.
+		On("CreateRefund", mock.Anything, mock.MatchedBy(func(refund *models.PaymentRefund) bool { return refund.Amount == amount }), mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.PaymentRefund).ID = refundID
+		}).
+		Return(&models.Payment{ID: "payment-1", AuctionID: "auction-1", BuyerID: "buyer-1", Status: status}, nil).Once()
+}
+
+func TestRefundPayment_Full(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	paymentService, m := newRefundPaymentService()
+	seller := &models.User{ID: "seller-1"}
+
+	m.payments.On("GetPaymentByOrderID", mock.Anything, "order-1").Return(completedPayment(0), nil).Once()
+	m.expectRefund("refund-1", 100, services.PaymentStatusRefunded)
+	m.provider.
+		On("Refund", mock.Anything, &payments.RefundRequest{SessionID: "cs_1", Amount: 10000, Reason: "damaged"}).
+		Return(&payments.Refund{ID: "re_1", Amount: 10000, Status: "succeeded"}, nil).Once()
+	m.payments.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil).Once()
+	m.ledger.On("DebitRefund", mock.Anything, "payment-1", "refund-1", 100.0).Return(nil).Once()
+
+	refunds, err := paymentService.RefundPayment(context.Background(), "order-1", seller, &models.CreateRefundRequest{Reason: "damaged"})
+	require.NoError(err, "Expected no error when refunding in full")
+	require.Len(refunds, 1)
+	assert.Equal(100.0, refunds[0].Amount, "Expected a refund without an amount to refund everything")
+	assert.Equal("re_1", refunds[0].ProviderRefundID)
+
+	m.payments.AssertExpectations(t)
+	m.provider.AssertExpectations(t)
+	m.ledger.AssertExpectations(t)
+}
+
+func TestRefundPayment_Partial(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	paymentService, m := newRefundPaymentService()
+	seller := &models.User{ID: "seller-1"}
+
+	m.payments.On("GetPaymentByOrderID", mock.Anything, "order-1").Return(completedPayment(25), nil).Once()
+	m.expectRefund("refund-2", 40, services.PaymentStatusPartiallyRefunded)
+	m.provider.
+		On("Refund", mock.Anything, &payments.RefundRequest{SessionID: "cs_1", Amount: 4000, Reason: "scratched"}).
+		Return(&payments.Refund{ID: "re_2", Amount: 4000, Status: "succeeded"}, nil).Once()
+	m.payments.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil).Once()
+	m.ledger.On("DebitRefund", mock.Anything, "payment-1", "refund-2", 40.0).Return(nil).Once()
+
+	refunds, err := paymentService.RefundPayment(context.Background(), "order-1", seller, &models.CreateRefundRequest{Amount: 40, Reason: "scratched"})
+	require.NoError(err, "Expected no error when refunding in part")
+	require.Len(refunds, 1)
+	assert.Equal(40.0, refunds[0].Amount)
+
+	m.payments.AssertExpectations(t)
+	m.provider.AssertExpectations(t)
+	m.ledger.AssertExpectations(t)
+}
+
+func TestRefundPayment_ExceedsPayment(t *testing.T) {
+	assert := assert.New(t)
+
+	paymentService, m := newRefundPaymentService()
+	seller := &models.User{ID: "seller-1"}
+
+	m.payments.On("GetPaymentByOrderID", mock.Anything, "order-1").Return(completedPayment(80), nil).Once()
+
+	refunds, err := paymentService.RefundPayment(context.Background(), "order-1", seller, &models.CreateRefundRequest{Amount: 20.01, Reason: "damaged"})
+	assert.ErrorIs(err, errs.ErrRefundExceedsPayment)
+	assert.Nil(refunds)
+
+	m.payments.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything, mock.Anything)
+	m.provider.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_ProviderRefusal(t *testing.T) {
+	assert := assert.New(t)
+
+	paymentService, m := newRefundPaymentService()
+	seller := &models.User{ID: "seller-1"}
+
+	m.payments.On("GetPaymentByOrderID", mock.Anything, "order-1").Return(completedPayment(0), nil).Once()
+	m.expectRefund("refund-3", 30, services.PaymentStatusPartiallyRefunded)
+	m.provider.
+		On("Refund", mock.Anything, mock.Anything).
+		Return((*payments.Refund)(nil), errors.New("card expired")).Once()
+	m.payments.On("FailRefund", mock.Anything, "refund-3").Return(nil).Once()
+
+	refunds, err := paymentService.RefundPayment(context.Background(), "order-1", seller, &models.CreateRefundRequest{Amount: 30, Reason: "damaged"})
+	assert.ErrorIs(err, errs.ErrFailedToRefundPayment)
+	assert.Nil(refunds)
+
+	m.payments.AssertExpectations(t)
+	m.payments.AssertNotCalled(t, "CompleteRefund", mock.Anything, mock.Anything)
+	m.ledger.AssertNotCalled(t, "DebitRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChargeRefunded_RecordsOnlyExternalRefunds(t *testing.T) {
+	tests := []struct {
+		name           string
+		refunded       float64 // already on the payment, refunds through the API included
+		amountRefunded int64   // total the provider reports refunded from the checkout charge
+		external       float64 // refund expected to be recorded, 0 for none
+	}{
+		{"refund made at the provider", 0, 3000, 30},
+		{"announcement of an API refund", 30, 3000, 0},
+		{"API refund and a later provider refund", 30, 5000, 20},
+		{"checkout and deposit refunded through the API", 120, 10000, 0},
+		{"checkout and part of the deposit refunded through the API", 110, 10000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentService, m := newRefundPaymentService()
+
+			payment := completedPayment(tt.refunded)
+			payment.Deposit = 20
+			event := &payments.WebhookEvent{ID: "evt_1", Type: payments.EventChargeRefunded, PaymentIntentID: "pi_1", AmountRefunded: tt.amountRefunded}
+
+			m.events.On("ClaimWebhookEvent", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Once()
+			m.events.On("FinishWebhookEvent", mock.Anything, mock.Anything).Return(nil).Once()
+			m.payments.On("GetPaymentByIntentID", mock.Anything, "pi_1").Return(payment, nil).Once()
+			if tt.external > 0 {
+				m.expectRefund("refund-1", tt.external, services.PaymentStatusPartiallyRefunded)
+				m.ledger.On("DebitRefund", mock.Anything, "payment-1", "refund-1", tt.external).Return(nil).Once()
+			}
+
+			assert.NoError(t, paymentService.ProcessWebhook(context.Background(), event))
+
+			if tt.external == 0 {
+				m.payments.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything, mock.Anything)
+			}
+			m.payments.AssertExpectations(t)
+			m.ledger.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package mock_services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newWebhookPaymentService() (*services.PaymentService, *mock_store.MockPaymentStore, *mock_store.MockWebhookEventStore) {
+
+	mockPayments := new(mock_store.MockPaymentStore)
+	mockEvents := new(mock_store.MockWebhookEventStore)
+
+	return services.NewPaymentService(new(MockPaymentProvider), mockPayments, nil, nil, mockEvents, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil), mockPayments, mockEvents
+}
+
+func TestProcessWebhook_SkipsDuplicateDelivery(t *testing.T) {
+	assert := assert.New(t)
+
+	paymentService, mockPayments, mockEvents := newWebhookPaymentService()
+	event := &payments.WebhookEvent{ID: "evt_1", Type: payments.EventPaymentFailed, Metadata: map[string]string{"order_id": "order-1", "buyer_id": "buyer-1"}}
+
+	mockEvents.
+		On("ClaimWebhookEvent", mock.Anything, mock.MatchedBy(func(record *models.WebhookEvent) bool { return record.EventID == "evt_1" }), mock.Anything).
+		Return(false, nil).Once()
+
+	assert.NoError(paymentService.ProcessWebhook(context.Background(), event), "Expected a duplicate delivery to be acknowledged")
+
+	mockEvents.AssertNotCalled(t, "FinishWebhookEvent", mock.Anything, mock.Anything)
+	mockPayments.AssertNotCalled(t, "GetPayment", mock.Anything, mock.Anything, mock.Anything)
+	mockEvents.AssertExpectations(t)
+}
+
+func TestProcessWebhook_IgnoresLateFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	paymentService, mockPayments, mockEvents := newWebhookPaymentService()
+	event := &payments.WebhookEvent{ID: "evt_2", Type: payments.EventPaymentFailed, Metadata: map[string]string{"order_id": "order-1", "buyer_id": "buyer-1"}}
+
+	mockEvents.
+		On("ClaimWebhookEvent", mock.Anything, mock.Anything, mock.Anything).
+		Return(true, nil).Once()
+	mockPayments.
+		On("GetPayment", mock.Anything, "order-1", "buyer-1").
+		Return(&models.Payment{ID: "payment-1", Status: services.PaymentStatusCompleted}, nil).Once()
+	mockPayments.
+		On("TransitionPayment", mock.Anything, "payment-1", services.PaymentStatusFailed, []string{services.PaymentStatusPending}).
+		Return("", errs.ErrIllegalPaymentTransition).Once()
+	mockEvents.
+		On("FinishWebhookEvent", mock.Anything, mock.MatchedBy(func(record *models.WebhookEvent) bool { return record.Status == models.WebhookEventIgnored })).
+		Return(nil).Once()
+
+	assert.NoError(paymentService.ProcessWebhook(context.Background(), event), "Expected a late payment_failed not to be retried")
+
+	mockPayments.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package mock_services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/auth"
+	"github.com/puremike/online_auction_api/internal/cached"
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/puremike/online_auction_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionUserService() (*services.UserService, *mock_store.MockUserStore, *mock_store.MockSessionStore) {
+
+	app := &config.Application{
+		AppConfig: &config.AppConfig{
+			AuthConfig: config.AuthConfig{Aud: "aud", Iss: "iss", Secret: "secret", TokenExp: time.Minute, RefreshTokenExp: time.Hour},
+		},
+		JwtAUth: auth.NewJWTAuthenticator("secret", "iss", "aud"),
+	}
+
+	mockRepo := new(mock_store.MockUserStore)
+	mockSessions := new(mock_store.MockSessionStore)
+
+	return services.NewUserService(mockRepo, mockSessions, nil, app, cached.NewCached(app).User, nil), mockRepo, mockSessions
+}
+
+func TestRefresh_IssuesNewToken(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	userService, mockRepo, mockSessions := newSessionUserService()
+	client := &models.Client{UserAgent: "test", IPAddress: "127.0.0.1"}
+
+	var next *models.RefreshToken
+	mockSessions.
+		On("RotateRefreshToken", mock.Anything, utils.HashToken("old-token"), mock.AnythingOfType("*models.RefreshToken"), client).
+		Run(func(args mock.Arguments) {
+			next = args.Get(2).(*models.RefreshToken)
+			next.UserID = "test-id-1"
+		}).
+		Return(nil).Once()
+
+	mockRepo.
+		On("GetUserById", mock.Anything, "test-id-1").
+		Return(&models.User{ID: "test-id-1", Username: "testuser"}, nil).Once()
+
+	res, err := userService.Refresh(context.Background(), "old-token", client)
+	require.NoError(err, "Expected no error when refreshing")
+	assert.Equal("test-id-1", res.ID)
+	assert.NotEmpty(res.Token, "Expected a new access token")
+	assert.NotEqual("old-token", res.RefreshToken, "Expected a new refresh token")
+	assert.Equal(next.TokenHash, utils.HashToken(res.RefreshToken), "Expected the new refresh token to be the one stored")
+
+	mockRepo.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestRefresh_ReusedToken(t *testing.T) {
+	assert := assert.New(t)
+
+	userService, mockRepo, mockSessions := newSessionUserService()
+	client := &models.Client{}
+
+	mockSessions.
+		On("RotateRefreshToken", mock.Anything, utils.HashToken("spent-token"), mock.Anything, client).
+		Return(errs.ErrRefreshTokenReused).Once()
+
+	res, err := userService.Refresh(context.Background(), "spent-token", client)
+	assert.ErrorIs(err, errs.ErrRefreshTokenReused)
+	assert.Nil(res, "Expected no tokens for a reused refresh token")
+
+	mockRepo.AssertNotCalled(t, "GetUserById", mock.Anything, mock.Anything)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestLogout_RevokesToken(t *testing.T) {
+	assert := assert.New(t)
+
+	userService, _, mockSessions := newSessionUserService()
+
+	mockSessions.
+		On("RevokeSessionByToken", mock.Anything, utils.HashToken("refresh-token")).
+		Return(nil).Once()
+
+	assert.NoError(userService.Logout(context.Background(), "refresh-token"))
+	assert.NoError(userService.Logout(context.Background(), ""), "Expected logging out without a token to do nothing")
+
+	mockSessions.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package mock_services
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ services.TwoFactorServiceInterface = (*MockTwoFactorService)(nil)
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Setup(ctx context.Context, user *models.User) (*models.TwoFactorSetupResponse, error) {
+	ret := m.Called(ctx, user)
+	return ret.Get(0).(*models.TwoFactorSetupResponse), ret.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+	ret := m.Called(ctx, userID, code)
+	return ret.Get(0).(*models.RecoveryCodesResponse), ret.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(ctx context.Context, userID, code string) error {
+	ret := m.Called(ctx, userID, code)
+	return ret.Error(0)
+}
+
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+	ret := m.Called(ctx, userID, code)
+	return ret.Get(0).(*models.RecoveryCodesResponse), ret.Error(1)
+}
+
+func (m *MockTwoFactorService) Enabled(ctx context.Context, userID string) (bool, error) {
+	ret := m.Called(ctx, userID)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (m *MockTwoFactorService) Challenge(ctx context.Context, user *models.User) (string, error) {
+	ret := m.Called(ctx, user)
+	return ret.String(0), ret.Error(1)
+}
+
+func (m *MockTwoFactorService) CompleteChallenge(ctx context.Context, challenge, code string) (string, error) {
+	ret := m.Called(ctx, challenge, code)
+	return ret.String(0), ret.Error(1)
+}
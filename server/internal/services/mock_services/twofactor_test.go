@@ -0,0 +1,79 @@
+package mock_services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/auth"
+	"github.com/puremike/online_auction_api/internal/cached"
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/services"
+	"github.com/puremike/online_auction_api/internal/store/mock_store"
+	"github.com/puremike/online_auction_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin_AdminWithoutTwoFactor(t *testing.T) {
+	require := require.New(t)
+
+	hashedPassword, err := utils.HashedPassword("@SecurePassword123")
+	require.NoError(err, "Expected no error when hashing password")
+
+	admin := &models.User{ID: "admin-1", Username: "admin", Email: "admin@example.com", Password: hashedPassword, IsAdmin: true}
+	req := &models.LoginRequest{Email: admin.Email, Password: "@SecurePassword123"}
+
+	newService := func(required bool) (*services.UserService, *mock_store.MockSessionStore, *MockTwoFactorService) {
+		app := &config.Application{
+			AppConfig: &config.AppConfig{
+				AuthConfig:    config.AuthConfig{Aud: "aud", Iss: "iss", Secret: "secret", TokenExp: time.Minute, RefreshTokenExp: time.Hour},
+				TwoFactorConf: config.TwoFactorConf{RequiredForAdmins: required},
+			},
+			JwtAUth: auth.NewJWTAuthenticator("secret", "iss", "aud"),
+		}
+
+		mockRepo := new(mock_store.MockUserStore)
+		mockRepo.On("GetUserByEmail", mock.Anything, admin.Email).Return(admin, nil)
+
+		mockSessions := new(mock_store.MockSessionStore)
+		mockTwoFactor := new(MockTwoFactorService)
+		mockTwoFactor.On("Enabled", mock.Anything, admin.ID).Return(false, nil)
+
+		return services.NewUserService(mockRepo, mockSessions, nil, app, cached.NewCached(app).User, mockTwoFactor), mockSessions, mockTwoFactor
+	}
+
+	t.Run("required", func(t *testing.T) {
+		assert := assert.New(t)
+		userService, mockSessions, _ := newService(true)
+
+		// /login refuses admins just like /admin/login
+		for name, login := range map[string]func(context.Context, *models.LoginRequest, *models.Client) (*models.LoginResponse, error){
+			"login":       userService.Login,
+			"admin login": userService.AdminLogin,
+		} {
+			res, err := login(context.Background(), req, &models.Client{})
+			assert.ErrorIs(err, errs.ErrTwoFactorRequired, name)
+			assert.Empty(res.Token, "%s: expected no access token", name)
+			assert.Empty(res.RefreshToken, "%s: expected no refresh token", name)
+		}
+
+		mockSessions.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not required", func(t *testing.T) {
+		assert := assert.New(t)
+		userService, mockSessions, _ := newService(false)
+
+		mockSessions.On("CreateSession", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		res, err := userService.Login(context.Background(), req, &models.Client{})
+		assert.NoError(err)
+		assert.NotEmpty(res.Token, "Expected an access token")
+
+		mockSessions.AssertExpectations(t)
+	})
+}
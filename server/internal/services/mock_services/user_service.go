@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/services"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,8 +20,16 @@ func (m *MockUserService) CreateUser(ctx context.Context, user *models.User) (*m
 	return ret.Get(0).(*models.UserResponse), ret.Error(1)
 }
 
-func (m *MockUserService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
-	ret := m.Called(ctx, req)
+func (m *MockUserService) Login(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error) {
+	ret := m.Called(ctx, req, client)
+	return ret.Get(0).(*models.LoginResponse), ret.Error(1)
+}
+func (m *MockUserService) AdminLogin(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error) {
+	ret := m.Called(ctx, req, client)
+	return ret.Get(0).(*models.LoginResponse), ret.Error(1)
+}
+func (m *MockUserService) CompleteTwoFactorLogin(ctx context.Context, req *models.TwoFactorLoginRequest, client *models.Client) (*models.LoginResponse, error) {
+	ret := m.Called(ctx, req, client)
 	return ret.Get(0).(*models.LoginResponse), ret.Error(1)
 }
 func (m *MockUserService) UserProfile(ctx context.Context, username string) (*models.UserResponse, error) {
@@ -31,21 +40,25 @@ func (m *MockUserService) MeProfile(ctx context.Context, userID string) (*models
 	ret := m.Called(ctx, userID)
 	return ret.Get(0).(*models.UserResponse), ret.Error(1)
 }
-func (m *MockUserService) Refresh(ctx context.Context, refreshToken string) (string, error) {
+func (m *MockUserService) Refresh(ctx context.Context, refreshToken string, client *models.Client) (*models.LoginResponse, error) {
+	ret := m.Called(ctx, refreshToken, client)
+	return ret.Get(0).(*models.LoginResponse), ret.Error(1)
+}
+func (m *MockUserService) Logout(ctx context.Context, refreshToken string) error {
 	ret := m.Called(ctx, refreshToken)
-	return ret.String(0), ret.Error(1)
+	return ret.Error(0)
 }
 func (m *MockUserService) UpdateProfile(ctx context.Context, req *models.User, id string) (string, error) {
 	ret := m.Called(ctx, req, id)
 	return ret.String(0), ret.Error(1)
 }
-func (m *MockUserService) ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string) (string, error) {
-	ret := m.Called(ctx, req, id)
-	return ret.String(0), ret.Error(1)
+func (m *MockUserService) ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string, client *models.Client) (*models.LoginResponse, error) {
+	ret := m.Called(ctx, req, id, client)
+	return ret.Get(0).(*models.LoginResponse), ret.Error(1)
 }
-func (m *MockUserService) GetUsers(ctx context.Context) (*[]models.UserResponse, error) {
-	ret := m.Called(ctx)
-	return ret.Get(0).(*[]models.UserResponse), ret.Error(1)
+func (m *MockUserService) GetUsers(ctx context.Context, page *pagination.Params) (*pagination.Page[models.UserResponse], error) {
+	ret := m.Called(ctx, page)
+	return ret.Get(0).(*pagination.Page[models.UserResponse]), ret.Error(1)
 }
 func (m *MockUserService) DeleteUser(ctx context.Context, id string) (string, error) {
 	ret := m.Called(ctx, id)
@@ -29,7 +29,7 @@ func TestCreateUser_Success(t *testing.T) {
 	hashedPassword, err := utils.HashedPassword("@SecurePassword123")
 	require.NoError(err, "Expected no error when hashing password")
 
-	userService := services.NewUserService(mockRepo, app, cached.User)
+	userService := services.NewUserService(mockRepo, nil, nil, app, cached.User, nil)
 
 	expectedUser := &models.User{
 		ID:        "test-id-1",
@@ -73,7 +73,7 @@ func TestCreateUser_InvalidDetails(t *testing.T) {
 	mockRepo := new(mock_store.MockUserStore)
 	app := &config.Application{}
 	cached := cached.NewCached(app)
-	userService := services.NewUserService(mockRepo, app, cached.User)
+	userService := services.NewUserService(mockRepo, nil, nil, app, cached.User, nil)
 
 	tests := []struct {
 		name          string
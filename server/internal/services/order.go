@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// orderTransitions is the order state machine: the statuses each status may be
+// reached from. Pickup orders skip shipping and are delivered by hand. Orders
+// enter and leave the disputed status through the DisputeService.
+var orderTransitions = map[string][]string{
+	models.OrderShipped:   {models.OrderAwaitingShipment},
+	models.OrderDelivered: {models.OrderAwaitingShipment, models.OrderShipped},
+	models.OrderCompleted: {models.OrderShipped, models.OrderDelivered},
+}
+
+// OrderService tracks the fulfilment of paid auctions. Sellers ship, buyers
+// confirm delivery and completion or dispute the order, and orders nobody
+// confirms are completed automatically. Completing an order releases the
+// seller's funds.
+type OrderService struct {
+	repo          store.OrderRepository
+	ledgerRepo    store.LedgerRepository
+	auctionRepo   store.AuctionRepository
+	notRepo       store.NotificationRepository
+	notifications chan<- *models.NotificationEvent
+}
+
+func NewOrderService(repo store.OrderRepository, ledgerRepo store.LedgerRepository, auctionRepo store.AuctionRepository, notRepo store.NotificationRepository, notifications chan<- *models.NotificationEvent) *OrderService {
+	return &OrderService{
+		repo:          repo,
+		ledgerRepo:    ledgerRepo,
+		auctionRepo:   auctionRepo,
+		notRepo:       notRepo,
+		notifications: notifications,
+	}
+}
+
+// OpenOrder opens the order of a completed payment, awaiting shipment by the
+// seller. Opening it again is a no-op.
+func (o *OrderService) OpenOrder(ctx context.Context, payment *models.Payment) error {
+
+	auction, err := o.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to open order %s: %v", payment.AuctionID, payment.OrderID, err)
+		return errs.ErrFailedToCreateOrder
+	}
+
+	order := &models.Order{
+		ID:             payment.OrderID,
+		PaymentID:      payment.ID,
+		AuctionID:      auction.ID,
+		BuyerID:        payment.BuyerID,
+		SellerID:       auction.SellerID,
+		ShippingMethod: payment.ShippingMethod,
+	}
+
+	created, err := o.repo.CreateOrder(ctx, order)
+	if err != nil {
+		log.Printf("failed to open order %s: %v", payment.OrderID, err)
+		return errs.ErrFailedToCreateOrder
+	}
+
+	if created {
+		o.notify(ctx, auction, fmt.Sprintf("%s has been paid for and is awaiting shipment", auction.Title), order.SellerID)
+	}
+
+	return nil
+}
+
+// GetOrder returns an order to its buyer, its seller or an admin
+func (o *OrderService) GetOrder(ctx context.Context, id string, user *models.User) (*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := o.getOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin && order.BuyerID != user.ID && order.SellerID != user.ID {
+		return nil, errs.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// GetOrders returns the orders the user bought or sold, newest first
+func (o *OrderService) GetOrders(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.Order], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	orders, total, err := o.repo.GetOrders(ctx, userID, page)
+	if err != nil {
+		log.Printf("failed to get orders of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToRetrieveOrders
+	}
+
+	return pagination.NewPage(orders, page, total, func(last *models.Order) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
+
+// ShipOrder lets the seller record that the item was sent
+func (o *OrderService) ShipOrder(ctx context.Context, id, sellerID string, req *models.ShipOrderRequest) (*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := o.getOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.SellerID != sellerID {
+		return nil, errs.ErrNotAllowedToUpdateOrder
+	}
+	if order.ShippingMethod == models.ShippingPickup {
+		return nil, errs.ErrIllegalOrderTransition
+	}
+
+	order.Carrier, order.TrackingNumber = req.Carrier, req.TrackingNumber
+	if err := o.transition(ctx, order, models.OrderShipped); err != nil {
+		return nil, err
+	}
+
+	o.notifyTransition(ctx, order, fmt.Sprintf("has shipped with %s, tracking number %s", order.Carrier, order.TrackingNumber), order.BuyerID)
+
+	return order, nil
+}
+
+// DeliverOrder records that the item arrived. The buyer can confirm a shipped
+// order was delivered; the seller can too, or hand over a pickup order.
+func (o *OrderService) DeliverOrder(ctx context.Context, id, userID string) (*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := o.getOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.BuyerID != userID && order.SellerID != userID {
+		return nil, errs.ErrNotAllowedToUpdateOrder
+	}
+	// only pickup orders are delivered without being shipped
+	if order.Status == models.OrderAwaitingShipment && (order.ShippingMethod != models.ShippingPickup || order.SellerID != userID) {
+		return nil, errs.ErrIllegalOrderTransition
+	}
+
+	if err := o.transition(ctx, order, models.OrderDelivered); err != nil {
+		return nil, err
+	}
+
+	if userID == order.SellerID {
+		o.notifyTransition(ctx, order, "was marked delivered by the seller, confirm you received it or open a dispute", order.BuyerID)
+	} else {
+		o.notifyTransition(ctx, order, "was confirmed delivered by the buyer", order.SellerID)
+	}
+
+	return order, nil
+}
+
+// CompleteOrder lets the buyer confirm they received the item as described,
+// which releases the seller's funds
+func (o *OrderService) CompleteOrder(ctx context.Context, id, buyerID string) (*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	order, err := o.getOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.BuyerID != buyerID {
+		return nil, errs.ErrNotAllowedToUpdateOrder
+	}
+
+	if err := o.complete(ctx, order); err != nil {
+		return nil, err
+	}
+
+	o.notifyTransition(ctx, order, "was completed by the buyer", order.SellerID)
+
+	return order, nil
+}
+
+// AutoCompleteOrders completes orders shipped longer than shippedFor or
+// delivered longer than deliveredFor ago without the buyer confirming or
+// disputing them, and returns how many it completed
+func (o *OrderService) AutoCompleteOrders(ctx context.Context, shippedFor, deliveredFor time.Duration) (int, error) {
+
+	completed := 0
+	for status, after := range map[string]time.Duration{models.OrderShipped: shippedFor, models.OrderDelivered: deliveredFor} {
+		orders, err := o.repo.GetStaleOrders(ctx, status, after)
+		if err != nil {
+			return completed, err
+		}
+
+		for _, order := range orders {
+			if err := o.complete(ctx, order); err != nil {
+				// most likely the buyer acted on it in the meantime
+				log.Printf("failed to auto-complete order %s: %v", order.ID, err)
+				continue
+			}
+			completed++
+
+			o.notifyTransition(ctx, order, "was completed automatically", order.BuyerID, order.SellerID)
+		}
+	}
+
+	return completed, nil
+}
+
+func (o *OrderService) getOrder(ctx context.Context, id string) (*models.Order, error) {
+
+	order, err := o.repo.GetOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get order %s: %v", id, err)
+		return nil, errs.ErrFailedToRetrieveOrders
+	}
+
+	return order, nil
+}
+
+// transition applies a status change allowed by orderTransitions
+func (o *OrderService) transition(ctx context.Context, order *models.Order, to string) error {
+
+	from := order.Status
+	order.Status = to
+
+	if err := o.repo.TransitionOrder(ctx, order, orderTransitions[to]); err != nil {
+		order.Status = from
+		if errors.Is(err, errs.ErrIllegalOrderTransition) || errors.Is(err, errs.ErrOrderNotFound) {
+			return err
+		}
+		log.Printf("failed to move order %s to %s: %v", order.ID, to, err)
+		return errs.ErrFailedToUpdateOrder
+	}
+
+	log.Printf("order %s: %s -> %s", order.ID, from, to)
+
+	return nil
+}
+
+// complete completes an order and releases the seller's held funds. A failed
+// release is left to the ledger release worker.
+func (o *OrderService) complete(ctx context.Context, order *models.Order) error {
+
+	if err := o.transition(ctx, order, models.OrderCompleted); err != nil {
+		return err
+	}
+
+	if _, err := o.ledgerRepo.ReleasePayment(ctx, order.PaymentID); err != nil {
+		log.Printf("failed to release funds of completed order %s: %v", order.ID, err)
+	}
+
+	return nil
+}
+
+func (o *OrderService) notifyTransition(ctx context.Context, order *models.Order, what string, userIDs ...string) {
+
+	auction, err := o.auctionRepo.GetAuctionById(ctx, order.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s to notify about order %s: %v", order.AuctionID, order.ID, err)
+		return
+	}
+
+	o.notify(ctx, auction, fmt.Sprintf("The order for %s %s", auction.Title, what), userIDs...)
+}
+
+func (o *OrderService) notify(ctx context.Context, auction *models.Auction, message string, userIDs ...string) {
+
+	for _, userID := range userIDs {
+		o.notifications <- &models.NotificationEvent{
+			Type:      models.NotificationOrderUpdate,
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			IsRead:    false,
+		}
+		if err := o.notRepo.CreateNotification(ctx, not); err != nil {
+			log.Printf("CreateNotification failed for order update on auction %s: %v", auction.ID, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/cached"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/mailer"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/puremike/online_auction_api/internal/utils"
+)
+
+// PasswordResetService lets users who forgot their password set a new one
+// through a single-use link mailed to them. Only the token's hash is stored,
+// and a reset signs the user out everywhere.
+type PasswordResetService struct {
+	repo     store.UserTokenRepository
+	userRepo store.UserRepository
+	mailer   mailer.Mailer
+	cached   cached.CachedUserInterface
+	tokenTTL time.Duration
+	url      string
+}
+
+func NewPasswordResetService(repo store.UserTokenRepository, userRepo store.UserRepository, mailer mailer.Mailer, cached cached.CachedUserInterface, tokenTTL time.Duration, url string) *PasswordResetService {
+	return &PasswordResetService{
+		repo:     repo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		cached:   cached,
+		tokenTTL: tokenTTL,
+		url:      url,
+	}
+}
+
+// ForgotPassword mails a reset link if an account uses the email. It answers
+// the same way, and as fast, whether or not one does, so it can't be used to
+// find out who has an account.
+func (p *PasswordResetService) ForgotPassword(ctx context.Context, email string) error {
+	go p.sendReset(strings.ToLower(email))
+	return nil
+}
+
+func (p *PasswordResetService) sendReset(email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryDefaultContext)
+	defer cancel()
+
+	user, err := p.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, errs.ErrUserNotFound) {
+			log.Printf("failed to get user to send a password reset: %v", err)
+		}
+		return
+	}
+
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		log.Printf("failed to generate password reset token for user %s: %v", user.ID, err)
+		return
+	}
+
+	if err := p.repo.CreateToken(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPasswordReset,
+		TokenHash: hash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(p.tokenTTL),
+	}); err != nil {
+		log.Printf("failed to store password reset token of user %s: %v", user.ID, err)
+		return
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Someone asked to reset the password of your account. Choose a new password by opening the link below. It expires in %s and works once.\n\n%s?token=%s\n\nIf it wasn't you, ignore this email; your password hasn't changed.", p.tokenTTL, p.url, url.QueryEscape(token)),
+	}
+	if err := p.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send password reset email to user %s through %s: %v", user.ID, p.mailer.Name(), err)
+	}
+}
+
+// ResetPassword sets a new password with the token from a reset link
+func (p *PasswordResetService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if len(req.NewPassword) < 8 {
+		return errs.ErrInvalidPassword
+	}
+	if req.NewPassword != req.ConfirmPassword {
+		return errs.ErrPasswordsDoNotMatch
+	}
+
+	hashedPassword, err := utils.HashedPassword(req.NewPassword)
+	if err != nil {
+		return errs.ErrFailedToHashPassword
+	}
+
+	userID, err := p.repo.ResetPassword(ctx, utils.HashToken(req.Token), hashedPassword)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidToken) {
+			return err
+		}
+		log.Printf("failed to reset password: %v", err)
+		return errs.ErrFailedToResetPassword
+	}
+
+	// the cached user still holds the old password hash
+	if err := p.cached.EvictUser(ctx, userID); err != nil {
+		log.Printf("failed to evict user %s from cache after a password reset: %v", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/payments"
+)
+
+// ReconcilePayments checks every payment pending for longer than pendingAge
+// against the provider, in case its webhook was lost. Paid sessions complete
+// the payment, expired sessions fail it, and sessions still open after
+// abandonAfter are expired at the provider and their payment failed. Finally
+// every auction's is_paid flag is made to agree with its payments. Each fix is
+// recorded as a mismatch for admins to review.
+func (p *PaymentService) ReconcilePayments(ctx context.Context, pendingAge, abandonAfter time.Duration) (*models.ReconciliationReport, error) {
+
+	pending, err := p.repo.GetStalePendingPayments(ctx, pendingAge)
+	if err != nil {
+		log.Printf("failed to get stale pending payments: %v", err)
+		return nil, errs.ErrFailedToReconcilePayments
+	}
+
+	report := &models.ReconciliationReport{Checked: len(pending), Mismatches: []*models.PaymentMismatch{}}
+
+	for _, payment := range pending {
+		if mismatch := p.reconcilePayment(ctx, payment, abandonAfter); mismatch != nil {
+			p.recordMismatch(ctx, mismatch)
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+	}
+
+	fixed, err := p.reconRepo.FixAuctionPaidFlags(ctx)
+	if err != nil {
+		log.Printf("failed to fix auction is_paid flags: %v", err)
+		return report, errs.ErrFailedToReconcilePayments
+	}
+
+	for auctionID, isPaid := range fixed {
+		mismatch := &models.PaymentMismatch{
+			AuctionID:   auctionID,
+			LocalStatus: fmt.Sprintf("is_paid=%t", !isPaid),
+			Action:      models.MismatchIsPaidFixed,
+			Detail:      fmt.Sprintf("is_paid set to %t to match the auction's payments", isPaid),
+		}
+		p.recordMismatch(ctx, mismatch)
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	return report, nil
+}
+
+// reconcilePayment brings one pending payment in line with its checkout
+// session. It returns nil when the two agree, or when a webhook settled the
+// payment while it was being checked.
+func (p *PaymentService) reconcilePayment(ctx context.Context, payment *models.Payment, abandonAfter time.Duration) *models.PaymentMismatch {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	mismatch := &models.PaymentMismatch{
+		PaymentID:   &payment.ID,
+		AuctionID:   payment.AuctionID,
+		SessionID:   payment.SessionID,
+		LocalStatus: payment.Status,
+	}
+
+	session, err := p.provider.GetSession(ctx, payment.SessionID)
+	if err != nil {
+		log.Printf("failed to get %s session %s of payment %s: %v", p.provider.Name(), payment.SessionID, payment.ID, err)
+		mismatch.Action, mismatch.Detail = models.MismatchUnresolved, err.Error()
+		return mismatch
+	}
+
+	mismatch.ProviderStatus = string(session.Status)
+	if session.PaymentStatus != "" {
+		mismatch.ProviderStatus += "/" + session.PaymentStatus
+	}
+
+	switch {
+	case session.Status == payments.SessionComplete && session.PaymentStatus == payments.PaymentStatusPaid:
+		mismatch.Action, mismatch.Detail = models.MismatchCompleted, "paid at the provider without a processed webhook"
+		err = p.setPaymentIntent(ctx, payment, session.PaymentIntentID)
+		if err == nil {
+			err = p.completePayment(ctx, payment)
+		}
+	case session.Status == payments.SessionExpired:
+		mismatch.Action, mismatch.Detail = models.MismatchFailed, "checkout session expired at the provider"
+		err = p.transitionPayment(ctx, payment, PaymentStatusFailed)
+	case session.Status == payments.SessionOpen && time.Since(payment.CreatedAt) >= abandonAfter:
+		mismatch.Action, mismatch.Detail = models.MismatchExpired, fmt.Sprintf("checkout abandoned for more than %s", abandonAfter)
+		if err = p.provider.ExpireSession(ctx, payment.SessionID); err == nil {
+			err = p.transitionPayment(ctx, payment, PaymentStatusFailed)
+		}
+	default:
+		// still open, or completed and waiting for a delayed payment method
+		return nil
+	}
+
+	if errors.Is(err, errs.ErrIllegalPaymentTransition) {
+		log.Printf("payment %s was settled while being reconciled: %v", payment.ID, err)
+		return nil
+	}
+
+	if err != nil {
+		log.Printf("failed to reconcile payment %s (%s): %v", payment.ID, mismatch.Action, err)
+		mismatch.Action, mismatch.Detail = models.MismatchUnresolved, mismatch.Detail+": "+err.Error()
+	}
+
+	return mismatch
+}
+
+func (p *PaymentService) recordMismatch(ctx context.Context, mismatch *models.PaymentMismatch) {
+
+	log.Printf("payment reconciliation: auction %s, session %s: local %s, provider %s: %s (%s)", mismatch.AuctionID, mismatch.SessionID, mismatch.LocalStatus, mismatch.ProviderStatus, mismatch.Action, mismatch.Detail)
+
+	if err := p.reconRepo.CreatePaymentMismatch(ctx, mismatch); err != nil {
+		log.Printf("failed to record payment mismatch of auction %s: %v", mismatch.AuctionID, err)
+	}
+}
+
+func (p *PaymentService) GetPaymentMismatches(ctx context.Context, action string, page *pagination.Params) (*pagination.Page[*models.PaymentMismatch], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if action != "" && !models.IsValidMismatchAction(action) {
+		return nil, errs.ErrInvalidMismatchAction
+	}
+
+	mismatches, total, err := p.reconRepo.GetPaymentMismatches(ctx, action, page)
+	if err != nil {
+		log.Printf("failed to get payment mismatches: %v", err)
+		return nil, errs.ErrFailedToRetrieveMismatches
+	}
+
+	return pagination.NewPage(mismatches, page, total, func(last *models.PaymentMismatch) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
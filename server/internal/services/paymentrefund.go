@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// RefundPayment refunds a completed payment in full or in part. Only an admin or
+// the seller of the auction may refund. The checkout charge is refunded first,
+// then the deposit captured from the buyer's bid hold; each is its own refund
+// at the provider and is returned in that order. Every amount is reserved on
+// the payment before the provider is called, and released again if the
+// provider refuses.
+func (p *PaymentService) RefundPayment(ctx context.Context, orderID string, user *models.User, req *models.CreateRefundRequest) ([]*models.PaymentRefund, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payment, auction, err := p.refundablePayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin && auction.SellerID != user.ID {
+		return nil, errs.ErrNotAllowedToRefund
+	}
+
+	amount, deposit, refunded := payments.ToCents(payment.Amount), payments.ToCents(payment.Deposit), payments.ToCents(payment.RefundedAmount)
+
+	reason := strings.TrimSpace(req.Reason)
+	cents := payments.ToCents(req.Amount)
+	if req.Amount == 0 {
+		cents = amount + deposit - refunded
+	}
+
+	if reason == "" || cents <= 0 {
+		return nil, errs.ErrInvalidRefund
+	}
+	if refunded+cents > amount+deposit {
+		return nil, errs.ErrRefundExceedsPayment
+	}
+
+	fromCheckout := min(cents, max(amount-refunded, 0))
+	fromDeposit := cents - fromCheckout
+
+	charges := []refundCharge{{payment.SessionID, fromCheckout}}
+
+	if fromDeposit > 0 {
+		hold, err := p.holds.CapturedHold(ctx, payment.AuctionID, payment.BuyerID)
+		if err != nil {
+			log.Printf("failed to get the deposit hold of payment %s: %v", payment.ID, err)
+			return nil, errs.ErrFailedToGetPayment
+		}
+		if hold == nil {
+			log.Printf("payment %s has a deposit of %.2f but no captured hold", payment.ID, payment.Deposit)
+			return nil, errs.ErrRefundExceedsPayment
+		}
+		charges = append(charges, refundCharge{hold.ProviderHoldID, fromDeposit})
+	}
+
+	var (
+		refunds []*models.PaymentRefund
+		updated *models.Payment
+	)
+	for _, charge := range charges {
+		if charge.cents <= 0 {
+			continue
+		}
+
+		refund := &models.PaymentRefund{
+			PaymentID:   payment.ID,
+			Amount:      payments.FromCents(charge.cents),
+			Reason:      reason,
+			Status:      models.RefundPending,
+			RequestedBy: &user.ID,
+		}
+
+		updated, err = p.refund(ctx, payment, refund, charge.sessionID)
+		if err != nil {
+			if len(refunds) > 0 {
+				log.Printf("refund of payment %s stopped after %d of %d charges: %v", payment.ID, len(refunds), len(charges), err)
+			}
+			return nil, err
+		}
+
+		refunds = append(refunds, refund)
+	}
+
+	total := 0.0
+	for _, refund := range refunds {
+		total += refund.Amount
+	}
+	p.notifyRefund(ctx, updated, &models.PaymentRefund{ID: refunds[0].ID, Amount: total, Reason: reason}, user.ID)
+
+	return refunds, nil
+}
+
+// refundCharge is the part of a refund taken from the charge of one provider
+// session, the checkout or the deposit hold
+type refundCharge struct {
+	sessionID string
+	cents     int64
+}
+
+// refund reserves a refund on the payment, refunds it at the provider from the
+// charge of sessionID and debits the seller's share
+func (p *PaymentService) refund(ctx context.Context, payment *models.Payment, refund *models.PaymentRefund, sessionID string) (*models.Payment, error) {
+
+	updated, err := p.repo.CreateRefund(ctx, refund, paymentTransitions[PaymentStatusRefunded])
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrIllegalPaymentTransition):
+			return nil, errs.ErrPaymentNotRefundable
+		case errors.Is(err, errs.ErrRefundExceedsPayment):
+			return nil, err
+		}
+		log.Printf("failed to reserve refund of payment %s: %v", payment.ID, err)
+		return nil, errs.ErrFailedToUpdatePayment
+	}
+
+	res, err := p.provider.Refund(ctx, &payments.RefundRequest{SessionID: sessionID, Amount: payments.ToCents(refund.Amount), Reason: refund.Reason})
+	if err != nil {
+		log.Printf("%s refused refund of payment %s: %v", p.provider.Name(), payment.ID, err)
+		p.releaseRefund(refund.ID)
+		return nil, errs.ErrFailedToRefundPayment
+	}
+
+	refund.ProviderRefundID = res.ID
+	if err := p.repo.CompleteRefund(ctx, refund); err != nil {
+		// the money is back with the buyer, the reservation already reflects it
+		log.Printf("failed to mark refund %s (%s) succeeded: %v", refund.ID, res.ID, err)
+	}
+
+	p.ledger.DebitRefund(ctx, updated, refund)
+
+	return updated, nil
+}
+
+// GetRefunds lists the refunds of a payment for an admin, the seller or the buyer
+func (p *PaymentService) GetRefunds(ctx context.Context, orderID string, user *models.User) ([]*models.PaymentRefund, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payment, auction, err := p.refundablePayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin && auction.SellerID != user.ID && payment.BuyerID != user.ID {
+		return nil, errs.ErrNotAllowedToRefund
+	}
+
+	refunds, err := p.repo.GetRefunds(ctx, payment.ID)
+	if err != nil {
+		log.Printf("failed to get refunds of payment %s: %v", payment.ID, err)
+		return nil, errs.ErrFailedToRetrieveRefunds
+	}
+
+	return refunds, nil
+}
+
+func (p *PaymentService) refundablePayment(ctx context.Context, orderID string) (*models.Payment, *models.Auction, error) {
+
+	payment, err := p.repo.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return nil, nil, err
+		}
+		log.Printf("failed to get payment for order %s: %v", orderID, err)
+		return nil, nil, errs.ErrFailedToGetPayment
+	}
+
+	auction, err := p.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAuctionNotFound) {
+			return nil, nil, err
+		}
+		log.Printf("failed to get auction %s: %v", payment.AuctionID, err)
+		return nil, nil, errs.ErrFailedToGetPayment
+	}
+
+	return payment, auction, nil
+}
+
+// releaseRefund uses its own context so a refused refund is released even if
+// the request has already timed out
+func (p *PaymentService) releaseRefund(refundID string) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.QueryBackgroundTimeout)
+	defer cancel()
+
+	if err := p.repo.FailRefund(ctx, refundID); err != nil {
+		log.Printf("failed to release refund %s: %v", refundID, err)
+	}
+}
+
+// notifyRefund tells the buyer, and the seller unless they issued it, about a refund
+func (p *PaymentService) notifyRefund(ctx context.Context, payment *models.Payment, refund *models.PaymentRefund, requestedBy string) {
+
+	auction, err := p.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s for refund notification: %v", payment.AuctionID, err)
+		return
+	}
+
+	kind := "partially refunded"
+	if payment.Status == PaymentStatusRefunded {
+		kind = "fully refunded"
+	}
+
+	messages := map[string]string{
+		payment.BuyerID: fmt.Sprintf("Your payment for %s was %s: $%.2f (%s)", auction.Title, kind, refund.Amount, refund.Reason),
+	}
+	if auction.SellerID != requestedBy {
+		messages[auction.SellerID] = fmt.Sprintf("The payment for %s was %s: $%.2f (%s)", auction.Title, kind, refund.Amount, refund.Reason)
+	}
+
+	for userID, message := range messages {
+		p.notifications <- &models.NotificationEvent{
+			Type:      models.NotificationRefund,
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    userID,
+			Message:   message,
+			AuctionID: auction.ID,
+			IsRead:    false,
+		}
+		if err := p.notRepo.CreateNotification(ctx, not); err != nil {
+			log.Printf("CreateNotification failed for refund %s: %v", refund.ID, err)
+		}
+	}
+}
@@ -2,291 +2,494 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
+	"github.com/google/uuid"
+	"github.com/puremike/online_auction_api/internal/config"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
 	"github.com/puremike/online_auction_api/internal/payments"
 	"github.com/puremike/online_auction_api/internal/store"
-	"github.com/stripe/stripe-go/v82"
-	"github.com/stripe/stripe-go/v82/checkout/session"
+	"github.com/puremike/online_auction_api/internal/tax"
 )
 
 type PaymentService struct {
-	stripe      *payments.StripePayment
-	repo        store.PaymentRepository
-	auctionRepo store.AuctionRepository
+	provider      payments.PaymentProvider
+	repo          store.PaymentRepository
+	auctionRepo   store.AuctionRepository
+	userRepo      store.UserRepository
+	fees          *payments.FeeSchedules
+	taxes         *tax.Engine
+	webhookRepo   store.WebhookEventRepository
+	reconRepo     store.ReconciliationRepository
+	notRepo       store.NotificationRepository
+	notifications chan<- *models.NotificationEvent
+	ledger        *LedgerService
+	invoices      *InvoiceService
+	holds         *HoldService
+	shipping      *ShippingService
+	orders        *OrderService
 }
 
-func NewPaymentService(stripe *payments.StripePayment, repo store.PaymentRepository, auctionRepo store.AuctionRepository) *PaymentService {
+func NewPaymentService(provider payments.PaymentProvider, repo store.PaymentRepository, auctionRepo store.AuctionRepository, userRepo store.UserRepository, webhookRepo store.WebhookEventRepository, reconRepo store.ReconciliationRepository, notRepo store.NotificationRepository, notifications chan<- *models.NotificationEvent, ledger *LedgerService, invoices *InvoiceService, fees *payments.FeeSchedules, taxes *tax.Engine, holds *HoldService, shipping *ShippingService, orders *OrderService) *PaymentService {
 	return &PaymentService{
-		stripe:      stripe,
-		repo:        repo,
-		auctionRepo: auctionRepo,
+		provider:      provider,
+		repo:          repo,
+		auctionRepo:   auctionRepo,
+		userRepo:      userRepo,
+		fees:          fees,
+		taxes:         taxes,
+		webhookRepo:   webhookRepo,
+		reconRepo:     reconRepo,
+		notRepo:       notRepo,
+		notifications: notifications,
+		ledger:        ledger,
+		invoices:      invoices,
+		holds:         holds,
+		shipping:      shipping,
+		orders:        orders,
 	}
 }
 
-const (
-	PaymentStatusPending   = "pending"
-	PaymentStatusCompleted = "completed"
-	PaymentStatusFailed    = "failed"
-)
+// PaymentServices are the payment service, the services it hands completed
+// payments to and the dispute service refunding through it
+type PaymentServices struct {
+	Payments *PaymentService
+	Ledger   *LedgerService
+	Invoices *InvoiceService
+	Holds    *HoldService
+	Shipping *ShippingService
+	Orders   *OrderService
+	Disputes *DisputeService
+}
 
-func (p *PaymentService) CreatePaymentCheckout(ctx context.Context, amount int64, orderID, buyerID, auctionID string) (*stripe.CheckoutSession, error) {
+// NewPaymentServices wires the payment services from the application
+// configuration. main builds them once and hands the same set to the API and
+// the background workers.
+func NewPaymentServices(app *config.Application) *PaymentServices {
 
-	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
-	defer cancel()
+	ledger := NewLedgerService(app.Store.Ledger, app.Store.Auctions, app.Payouts, app.Fees, app.AppConfig.LedgerConf.HoldPeriod)
 
-	if amount < 0 {
-		log.Printf("amount cannot be negative: %v", amount)
-		return nil, errs.ErrAmountCannotBeNegative
-	}
+	invoices := NewInvoiceService(app.Store.Invoices, app.Store.Payments, app.Store.Auctions, app.Store.Users)
 
-	amountInSmallestUnit := amount * 100 // convert to cent
-	params := &stripe.CheckoutSessionParams{
-		LineItems: []*stripe.CheckoutSessionLineItemParams{{
-			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-				Currency: stripe.String(string(stripe.CurrencyUSD)),
-				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-					Name: stripe.String("Order Payment"),
-				},
-				UnitAmount: stripe.Int64(amountInSmallestUnit),
-			},
-			Quantity: stripe.Int64(1),
-		}},
+	holds := NewHoldService(app.Payments, app.Store.Holds, app.Store.Auctions, app.AppConfig.HoldConf.Percent, app.AppConfig.HoldConf.ValidFor, app.AppConfig.HoldConf.ReturnURL)
 
-		Mode:       stripe.String(stripe.CheckoutSessionModePayment),
-		SuccessURL: stripe.String(p.stripe.SuccessURL),
-		CancelURL:  stripe.String(p.stripe.CancelURL),
+	shipping := NewShippingService(app.Store.Shipping, app.Store.Addresses, app.Store.Auctions)
 
-		PaymentMethodTypes: stripe.StringSlice([]string{
-			string(stripe.PaymentMethodTypeCard),
-		}),
+	orders := NewOrderService(app.Store.Orders, app.Store.Ledger, app.Store.Auctions, app.Store.Notifications, app.WsHub.NotificationUpdates)
 
-		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
-			Metadata: map[string]string{
-				"order_id":   orderID,
-				"buyer_id":   buyerID,
-				"auction_id": auctionID,
-			},
-		},
-	}
+	payment := NewPaymentService(app.Payments, app.Store.Payments, app.Store.Auctions, app.Store.Users, app.Store.WebhookEvents, app.Store.Reconciliation, app.Store.Notifications, app.WsHub.NotificationUpdates, ledger, invoices, app.Fees, app.Tax, holds, shipping, orders)
 
-	params.AddMetadata("order_id", orderID)
-	params.AddMetadata("buyer_id", buyerID)
-	params.AddMetadata("auction_id", auctionID)
+	disputes := NewDisputeService(app.Store.Disputes, app.Store.Orders, app.Store.Payments, app.Store.Ledger, app.Store.Auctions, app.Store.Notifications, app.WsHub.NotificationUpdates, payment, app.AppConfig.DisputeConf.RespondWithin, app.AppConfig.DisputeConf.ReturnWithin)
 
-	if params.Metadata != nil {
-		log.Printf("DEBUG: CreatePaymentCheckout - Params metadata before API call: %+v", params.Metadata)
-	} else {
-		log.Println("DEBUG: CreatePaymentCheckout - Params metadata is nil before API call.")
+	return &PaymentServices{
+		Payments: payment,
+		Ledger:   ledger,
+		Invoices: invoices,
+		Holds:    holds,
+		Shipping: shipping,
+		Orders:   orders,
+		Disputes: disputes,
 	}
+}
 
-	session, err := session.New(params)
+const (
+	PaymentStatusPending           = "pending"
+	PaymentStatusCompleted         = "completed"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+)
+
+// paymentTransitions is the payment state machine: the statuses each status may
+// be reached from. A failed payment can still complete when the buyer retries
+// a declined card on the same checkout session.
+var paymentTransitions = map[string][]string{
+	PaymentStatusCompleted:         {PaymentStatusPending, PaymentStatusFailed},
+	PaymentStatusFailed:            {PaymentStatusPending},
+	PaymentStatusRefunded:          {PaymentStatusCompleted, PaymentStatusPartiallyRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusCompleted, PaymentStatusPartiallyRefunded},
+}
+
+// transitionPayment applies a status change allowed by paymentTransitions.
+// Illegal transitions, such as completing an already completed payment, return an error
+// wrapping errs.ErrIllegalPaymentTransition.
+func (p *PaymentService) transitionPayment(ctx context.Context, payment *models.Payment, to string) error {
+
+	from, err := p.repo.TransitionPayment(ctx, payment.ID, to, paymentTransitions[to])
 	if err != nil {
-		log.Printf("failed to create Stripe checkout session: %v", err)
-		return nil, errs.ErrFailedToCreateStripeCheckout
+		if errors.Is(err, errs.ErrIllegalPaymentTransition) {
+			return err
+		}
+		log.Printf("failed to move payment %s to %s: %v", payment.ID, to, err)
+		return errs.ErrFailedToUpdatePayment
 	}
 
-	log.Printf("DEBUG: Stripe Session Created - SessionID: %s, Metadata from Stripe response: %+v", session.ID, session.Metadata)
+	log.Printf("payment %s (order %s): %s -> %s", payment.ID, payment.OrderID, from, to)
+	payment.Status = to
 
-	req := &models.Payment{
-		Amount:    float64(amount),
-		OrderID:   orderID,
-		BuyerID:   buyerID,
-		Status:    PaymentStatusPending,
-		AuctionID: auctionID,
-		SessionID: session.ID,
-	}
+	return nil
+}
 
-	// create payment and save to DB
-	if err := p.repo.CreatePayment(ctx, req); err != nil {
-		log.Printf("failed to create payment: %v", err)
-		return nil, errs.ErrFailedToCreatePayment
+// completePayment completes a payment, credits the seller, issues the invoice
+// and opens the order. A payment that was already completed goes through these
+// again (a no-op for each that already happened) so that a redelivered event
+// repairs one that failed the first time.
+func (p *PaymentService) completePayment(ctx context.Context, payment *models.Payment) error {
+
+	wasCompleted := payment.Status == PaymentStatusCompleted
+
+	err := p.transitionPayment(ctx, payment, PaymentStatusCompleted)
+	if err != nil && !(wasCompleted && errors.Is(err, errs.ErrIllegalPaymentTransition)) {
+		return err
 	}
 
-	return session, nil
-}
+	if cerr := p.ledger.CreditSale(ctx, payment); cerr != nil {
+		return cerr
+	}
 
-// func (p *PaymentService) GetPaymentStatus(sessionID string) (*stripe.CheckoutSession, error) {
-// 	session, err := session.Get(sessionID, nil)
-// 	if err != nil {
-// 		return nil, errs.ErrFailedToGetPaymentSession
-// 	}
+	if _, ierr := p.invoices.IssueInvoice(ctx, payment); ierr != nil {
+		return ierr
+	}
 
-// 	return session, nil
-// }
+	if oerr := p.orders.OpenOrder(ctx, payment); oerr != nil {
+		return oerr
+	}
 
-// func (p *PaymentService) GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error) {
-// 	return p.repo.GetPayment(ctx, orderID, buyerID)
-// }
+	return err
+}
 
-func (p *PaymentService) HandleCheckoutSessionCompleted(ctx context.Context, event *stripe.Event, session *stripe.CheckoutSession) error {
+// CreatePaymentCheckout starts (or resumes) checkout for a closed auction. The
+// amount is always derived from the auction's final price plus fees and the
+// shipping the winner chose; only the winner may pay, and an open checkout
+// session for the auction is reused.
+func (p *PaymentService) CreatePaymentCheckout(ctx context.Context, auctionID, buyerID string) (*models.CreatePaymentResponse, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	err := json.Unmarshal(event.Data.Raw, session)
+	// read from the store rather than the cache so status, winner and is_paid are current
+	auction, err := p.auctionRepo.GetAuctionById(ctx, auctionID)
 	if err != nil {
-		log.Printf("failed to unmarshal event data: %v", err)
-		return errs.ErrFailedToUnmarshalEvent
+		if errors.Is(err, errs.ErrAuctionNotFound) {
+			return nil, errs.ErrAuctionNotFound
+		}
+		log.Printf("failed to get auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToCreateStripeCheckout
 	}
 
-	orderID := session.Metadata["order_id"]
-	buyerID := session.Metadata["buyer_id"]
-	auctionID := session.Metadata["auction_id"]
-	stripeSessionID := session.ID
+	if auction.Status != "closed" {
+		return nil, errs.ErrAuctionNotClosed
+	}
 
-	log.Printf("DEBUG: PAYMENT CHECKOUT NOW -- MY ORDERID ---> %s", orderID)
-	log.Printf("DEBUG: PAYMENT CHECKOUT NOW -- MY AUCTIONID ---> %s", auctionID)
+	if auction.WinnerID == "" || auction.WinnerID != buyerID {
+		return nil, errs.ErrNotAuctionWinner
+	}
 
-	if orderID == "" || buyerID == "" {
-		log.Printf("Missing order_id or user_id in session metadata for session %s", stripeSessionID)
-		return errs.ErrMissingRequiredSessionMetadata
+	if auction.IsPaid {
+		return nil, errs.ErrAuctionAlreadyPaid
 	}
 
-	log.Printf("handling checkout session completed for session: %s, order: %s and user: %s", stripeSessionID, orderID, buyerID)
+	premium, _ := p.fees.For(auction.Category, auction.SellerID)
+	quote := premium.Quote(auction.CurrentPrice)
+	if quote.Total <= 0 {
+		log.Printf("invalid checkout amount %d for auction %s", quote.Total, auctionID)
+		return nil, errs.ErrAmountCannotBeNegative
+	}
 
-	payment, err := p.repo.GetPayment(ctx, orderID, buyerID)
+	shippingMethod, shipping, err := p.shipping.CheckoutShipping(ctx, auction, buyerID)
 	if err != nil {
-		log.Printf("failed to get payment for order %s and user %s:", orderID, buyerID)
-		return errs.ErrFailedToGetPayment
+		return nil, err
 	}
 
-	if payment.Status == PaymentStatusCompleted {
-		log.Printf("payment %s (Order: %s) already in terminal status '%s', skipping checkout.session.completed update.", payment.ID, orderID, payment.Status)
-		return nil
+	if res, err := p.resumePendingCheckout(ctx, auctionID, buyerID, shipping); res != nil || err != nil {
+		return res, err
 	}
 
-	// if payment.Status == PaymentStatusCompleted || payment.Status == PaymentStatusFailed {
-	// 	log.Printf("payment %s (Order: %s) already in terminal status '%s', skipping checkout.session.completed update.", payment.ID, orderID, payment.Status)
-	// }
+	taxLines, err := p.checkoutTax(ctx, auction, buyerID, quote)
+	if err != nil {
+		return nil, err
+	}
+	taxTotal := tax.Total(taxLines)
+
+	deposit, err := p.holds.Deposit(ctx, auctionID, buyerID)
+	if err != nil {
+		log.Printf("failed to get deposit of buyer %s on auction %s: %v", buyerID, auctionID, err)
+		return nil, errs.ErrFailedToCreateStripeCheckout
+	}
+	// the deposit never exceeds the price, the hold is a fraction of a threshold the winning bid reached
+	deposit = min(deposit, quote.Price)
+	total := quote.Total + taxTotal + shipping - deposit
 
-	newStatus := ""
+	orderID := uuid.New().String()
 
-	switch session.PaymentStatus {
-	case stripe.CheckoutSessionPaymentStatusPaid:
-		newStatus = PaymentStatusCompleted
-		log.Printf("Checkout session %s is paid. Setting internal payment status t %s", stripeSessionID, newStatus)
-	case stripe.CheckoutSessionPaymentStatusUnpaid:
-		newStatus = PaymentStatusPending
-		log.Printf("Checkout session %s is unpaid. Setting internal payment status t %s", stripeSessionID, newStatus)
-	default:
-		log.Printf("Checkout session %s has unknown payment status '%s'. Setting internal payment status t %s", stripeSessionID, session.PaymentStatus, newStatus)
+	checkout := &payments.CheckoutRequest{
+		OrderID:   orderID,
+		BuyerID:   buyerID,
+		AuctionID: auctionID,
+		LineItems: []payments.LineItem{{Name: auction.Title, Amount: quote.Price - deposit}},
+	}
+	if deposit > 0 {
+		checkout.LineItems[0].Name = fmt.Sprintf("%s (less %.2f deposit paid)", auction.Title, payments.FromCents(deposit))
+	}
+	if quote.Fee > 0 {
+		checkout.LineItems = append(checkout.LineItems, payments.LineItem{Name: premiumLabel(premium), Amount: quote.Fee})
+	}
+	if shipping > 0 {
+		checkout.LineItems = append(checkout.LineItems, payments.LineItem{Name: shippingLabel(shippingMethod), Amount: shipping})
 	}
 
-	if newStatus != "" && payment.Status != newStatus {
-		if err := p.repo.UpdatePayment(ctx, newStatus, payment.ID); err != nil {
-			log.Printf("failed to update payment: %v", err)
-			return errs.ErrFailedToUpdatePayment
-		}
+	paymentTax := []models.TaxLine{}
+	for _, line := range taxLines {
+		checkout.LineItems = append(checkout.LineItems, payments.LineItem{Name: fmt.Sprintf("%s (%g%%)", line.Name, line.Rate), Amount: line.Amount})
+		paymentTax = append(paymentTax, models.TaxLine{Name: line.Name, Rate: line.Rate, Amount: payments.FromCents(line.Amount)})
 	}
 
-	if newStatus == PaymentStatusCompleted {
-		if err := p.auctionRepo.UpdateAuctionPaymentStatus(ctx, true, auctionID); err != nil {
-			log.Printf("failed to update auction payment status: %v", err)
-			return errs.NewHTTPError("failed to update auction payment status", http.StatusInternalServerError)
+	session, err := p.provider.CreateCheckout(ctx, checkout)
+	if err != nil {
+		log.Printf("failed to create %s checkout session: %v", p.provider.Name(), err)
+		return nil, errs.ErrFailedToCreateStripeCheckout
+	}
 
-		}
+	req := &models.Payment{
+		Amount:         payments.FromCents(total),
+		Fee:            payments.FromCents(quote.Fee),
+		Tax:            payments.FromCents(taxTotal),
+		TaxLines:       paymentTax,
+		Deposit:        payments.FromCents(deposit),
+		Shipping:       payments.FromCents(shipping),
+		ShippingMethod: shippingMethod,
+		OrderID:        orderID,
+		BuyerID:        buyerID,
+		Status:         PaymentStatusPending,
+		AuctionID:      auctionID,
+		SessionID:      session.ID,
 	}
 
-	log.Printf("DEBUG: newStatus: %s, payment.Status: %s", newStatus, payment.Status)
+	// create payment and save to DB
+	if err := p.repo.CreatePayment(ctx, req); err != nil {
+		log.Printf("failed to create payment: %v", err)
+		// most likely a concurrent checkout won the pending slot, don't leave this session payable
+		p.expireCheckoutSession(session.ID)
+		return nil, errs.ErrFailedToCreatePayment
+	}
 
-	return nil
+	return &models.CreatePaymentResponse{
+		CheckoutURL: session.URL,
+		OrderID:     orderID,
+		Price:       payments.FromCents(quote.Price),
+		Fee:         payments.FromCents(quote.Fee),
+		Tax:         payments.FromCents(taxTotal),
+		Deposit:     payments.FromCents(deposit),
+		Shipping:    payments.FromCents(shipping),
+		Total:       payments.FromCents(total),
+	}, nil
 }
 
-func (p *PaymentService) HandlePaymentIntentSucceeded(ctx context.Context, event *stripe.Event, pi *stripe.PaymentIntent) error {
+// finalPrice is the auction's final price a payment pays for, in cents: what
+// was charged less the buyer's premium, shipping and tax, plus the deposit
+// captured from the buyer's bid hold
+func finalPrice(payment *models.Payment) int64 {
+	return payments.ToCents(payment.Amount) - payments.ToCents(payment.Fee) - payments.ToCents(payment.Shipping) - payments.ToCents(payment.Tax) + payments.ToCents(payment.Deposit)
+}
 
-	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
-	defer cancel()
+// premiumLabel names the buyer's premium line item, e.g. "Buyer's premium (10%)"
+func premiumLabel(premium payments.Fees) string {
+	if premium.Percent > 0 {
+		return fmt.Sprintf("Buyer's premium (%g%%)", premium.Percent)
+	}
+	return "Buyer's premium"
+}
+
+// checkoutTax computes the tax of a checkout from where the buyer and the
+// seller are located and the auction's category
+func (p *PaymentService) checkoutTax(ctx context.Context, auction *models.Auction, buyerID string, quote payments.Quote) ([]tax.Line, error) {
 
-	err := json.Unmarshal(event.Data.Raw, pi)
+	buyer, err := p.userRepo.GetUserById(ctx, buyerID)
 	if err != nil {
-		log.Printf("failed to unmarshal event data: %v", err)
-		return errs.ErrFailedToUnmarshalEvent
+		log.Printf("failed to get buyer %s to compute tax: %v", buyerID, err)
+		return nil, errs.ErrFailedToCreateStripeCheckout
 	}
 
-	// buyerID := pi.Metadata["buyer_id"]
-	orderID := pi.Metadata["order_id"]
-	buyerID := pi.Metadata["buyer_id"]
-	auctionID := pi.Metadata["auction_id"]
+	seller, err := p.userRepo.GetUserById(ctx, auction.SellerID)
+	if err != nil {
+		log.Printf("failed to get seller %s to compute tax: %v", auction.SellerID, err)
+		return nil, errs.ErrFailedToCreateStripeCheckout
+	}
+
+	return p.taxes.Calculate(tax.Sale{
+		BuyerLocation:  buyer.Location,
+		SellerLocation: seller.Location,
+		Category:       auction.Category,
+		Price:          quote.Price,
+		Fee:            quote.Fee,
+	}), nil
+}
 
-	log.Printf("DEBUG: PAYMENT INTENT NOW -- MY ORDERID ---> %s", orderID)
+// resumePendingCheckout returns the open checkout session of an auction so that
+// repeated checkout requests don't create duplicate sessions. A pending payment
+// whose session has expired, or that charges different shipping than the buyer
+// has since chosen, is marked failed, and nil is returned so a new one can be
+// created.
+func (p *PaymentService) resumePendingCheckout(ctx context.Context, auctionID, buyerID string, shipping int64) (*models.CreatePaymentResponse, error) {
 
-	payment, err := p.repo.GetPayment(ctx, orderID, buyerID)
+	pending, err := p.repo.GetPendingPayment(ctx, auctionID)
 	if err != nil {
-		log.Printf("failed to get payment: %v", err)
-		return errs.ErrFailedToGetPayment
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return nil, nil
+		}
+		log.Printf("failed to get pending payment for auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToGetPayment
 	}
 
-	if payment.Status == PaymentStatusCompleted {
-		log.Printf("payment %s (Order: %s) already in terminal status '%s', skipping payment_intent.succeeded update.", payment.ID, orderID, payment.Status)
-		return nil
+	existing, err := p.provider.GetSession(ctx, pending.SessionID)
+	if err != nil {
+		log.Printf("failed to get %s session %s: %v", p.provider.Name(), pending.SessionID, err)
+		return nil, errs.ErrFailedToGetPaymentSession
 	}
 
-	// if payment.Status == PaymentStatusFailed {
-	// 	log.Printf("Payment %s (Order: %s) was previously '%s', but received payment_intent.succeeded. Transitioning to 'completed'. PI: %s",
-	// 		payment.ID, orderID, PaymentStatusFailed, pi.ID)
-	// }
+	switch existing.Status {
+	case payments.SessionOpen:
+		if pending.BuyerID != buyerID {
+			return nil, errs.ErrNotAuctionWinner
+		}
+		if payments.ToCents(pending.Shipping) != shipping {
+			p.expireCheckoutSession(pending.SessionID)
+			break
+		}
+		return &models.CreatePaymentResponse{
+			CheckoutURL: existing.URL,
+			OrderID:     pending.OrderID,
+			Price:       payments.FromCents(finalPrice(pending)),
+			Fee:         pending.Fee,
+			Tax:         pending.Tax,
+			Deposit:     pending.Deposit,
+			Shipping:    pending.Shipping,
+			Total:       pending.Amount,
+		}, nil
+	case payments.SessionComplete:
+		// paid, waiting for the webhook to mark the payment completed
+		return nil, errs.ErrCheckoutAlreadyCompleted
+	}
 
-	newStatus := PaymentStatusCompleted
-	if err := p.repo.UpdatePayment(ctx, newStatus, payment.ID); err != nil {
-		log.Printf("failed to update payment: %v", err)
-		return errs.ErrFailedToUpdatePayment
+	// rejected if a webhook settled the payment in the meantime; the buyer can simply retry
+	if err := p.transitionPayment(ctx, pending, PaymentStatusFailed); err != nil {
+		return nil, err
 	}
 
-	if err := p.auctionRepo.UpdateAuctionPaymentStatus(ctx, true, auctionID); err != nil {
-		log.Printf("failed to update auction payment status: %v", err)
-		return errs.NewHTTPError("failed to update auction payment status", http.StatusInternalServerError)
+	return nil, nil
+}
+
+// expireCheckoutSession uses its own context since the request context may
+// already be done by the time a failed checkout is cleaned up
+func (p *PaymentService) expireCheckoutSession(sessionID string) {
 
+	ctx, cancel := context.WithTimeout(context.Background(), store.QueryBackgroundTimeout)
+	defer cancel()
+
+	if err := p.provider.ExpireSession(ctx, sessionID); err != nil {
+		log.Printf("failed to expire %s session %s: %v", p.provider.Name(), sessionID, err)
 	}
+}
 
-	log.Printf("Webhook event type: %s", event.Type)
+// ParseWebhook verifies and decodes a webhook delivery from the configured provider
+func (p *PaymentService) ParseWebhook(payload []byte, header http.Header) (*payments.WebhookEvent, error) {
 
-	return nil
+	event, err := p.provider.ParseWebhook(payload, header)
+	if err != nil {
+		log.Printf("failed to verify %s webhook: %v", p.provider.Name(), err)
+		return nil, errs.ErrInvalidWebhookSignature
+	}
+
+	return event, nil
 }
 
-func (p *PaymentService) HandlePaymentIntentFailed(ctx context.Context, event *stripe.Event, pi *stripe.PaymentIntent) error {
+// func (p *PaymentService) GetPaymentStatus(sessionID string) (*stripe.CheckoutSession, error) {
+// 	session, err := session.Get(sessionID, nil)
+// 	if err != nil {
+// 		return nil, errs.ErrFailedToGetPaymentSession
+// 	}
+
+// 	return session, nil
+// }
+
+// func (p *PaymentService) GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error) {
+// 	return p.repo.GetPayment(ctx, orderID, buyerID)
+// }
+
+func (p *PaymentService) GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error) {
+	return p.repo.GetPayment(ctx, orderID, buyerID)
+}
+
+// GetPaymentStatus returns the status of a payment to its buyer, the seller of
+// the auction or an admin. Anyone else gets not found.
+func (p *PaymentService) GetPaymentStatus(ctx context.Context, orderID string, user *models.User) (*models.PaymentStatusResponse, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	err := json.Unmarshal(event.Data.Raw, pi)
+	payment, auction, err := p.refundablePayment(ctx, orderID)
 	if err != nil {
-		log.Printf("failed to unmarshal event data: %v", err)
-		return errs.ErrFailedToUnmarshalEvent
+		return nil, err
 	}
 
-	//buyerID := pi.Metadata["buyer_id"]
-	orderID := pi.Metadata["order_id"]
-	buyerID := pi.Metadata["buyer_id"]
+	return p.paymentStatus(ctx, payment, auction, user)
+}
+
+// GetPaymentStatusBySession is GetPaymentStatus for the checkout session id the
+// provider redirects the buyer back with
+func (p *PaymentService) GetPaymentStatusBySession(ctx context.Context, sessionID string, user *models.User) (*models.PaymentStatusResponse, error) {
 
-	payment, err := p.repo.GetPayment(ctx, orderID, buyerID)
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payment, err := p.repo.GetPaymentBySessionID(ctx, sessionID)
 	if err != nil {
-		log.Printf("failed to get payment: %v", err)
-		return errs.ErrFailedToGetPayment
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get payment for session %s: %v", sessionID, err)
+		return nil, errs.ErrFailedToGetPayment
 	}
 
-	if payment.Status == PaymentStatusCompleted || payment.Status == PaymentStatusFailed {
-		log.Printf("payment %s (Order: %s) already in terminal status '%s', skipping update from payment_intent.payment_failed.", payment.ID, orderID, payment.Status)
-		return nil
+	auction, err := p.auctionRepo.GetAuctionById(ctx, payment.AuctionID)
+	if err != nil {
+		log.Printf("failed to get auction %s: %v", payment.AuctionID, err)
+		return nil, errs.ErrFailedToGetPayment
 	}
 
-	newStatus := PaymentStatusFailed
-	if err := p.repo.UpdatePayment(ctx, newStatus, payment.ID); err != nil {
-		log.Printf("failed to update payment: %v", err)
-		return errs.ErrFailedToUpdatePayment
+	return p.paymentStatus(ctx, payment, auction, user)
+}
+
+func (p *PaymentService) paymentStatus(ctx context.Context, payment *models.Payment, auction *models.Auction, user *models.User) (*models.PaymentStatusResponse, error) {
+
+	if !user.IsAdmin && payment.BuyerID != user.ID && auction.SellerID != user.ID {
+		return nil, errs.ErrPaymentNotFound
 	}
 
-	return nil
-}
+	res := &models.PaymentStatusResponse{
+		OrderID:        payment.OrderID,
+		AuctionID:      payment.AuctionID,
+		Status:         payment.Status,
+		Amount:         payment.Amount,
+		Fee:            payment.Fee,
+		Tax:            payment.Tax,
+		RefundedAmount: payment.RefundedAmount,
+		IsPaid:         auction.IsPaid,
+		UpdatedAt:      payment.UpdatedAt,
+	}
 
-func (p *PaymentService) GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error) {
-	return p.repo.GetPayment(ctx, orderID, buyerID)
-}
+	// read only: the status itself only changes through webhooks and reconciliation
+	if payment.Status == PaymentStatusPending {
+		if session, err := p.provider.GetSession(ctx, payment.SessionID); err != nil {
+			log.Printf("failed to get %s session %s: %v", p.provider.Name(), payment.SessionID, err)
+		} else {
+			res.CheckoutStatus = string(session.Status)
+		}
+	}
 
-func (p *PaymentService) UpdateAuctionPayment(ctx context.Context, isPaid bool, id string) error {
-	return p.auctionRepo.UpdateAuctionPaymentStatus(ctx, isPaid, id)
+	return res, nil
 }
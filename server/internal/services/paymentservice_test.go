@@ -0,0 +1,42 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{PaymentStatusPending, PaymentStatusCompleted, true},
+		{PaymentStatusPending, PaymentStatusFailed, true},
+		{PaymentStatusFailed, PaymentStatusCompleted, true},
+		{PaymentStatusCompleted, PaymentStatusRefunded, true},
+		{PaymentStatusCompleted, PaymentStatusPartiallyRefunded, true},
+		{PaymentStatusPartiallyRefunded, PaymentStatusPartiallyRefunded, true},
+		{PaymentStatusPartiallyRefunded, PaymentStatusRefunded, true},
+
+		// a payment_failed delivered after the payment completed
+		{PaymentStatusCompleted, PaymentStatusFailed, false},
+		{PaymentStatusCompleted, PaymentStatusCompleted, false},
+		{PaymentStatusCompleted, PaymentStatusPending, false},
+		{PaymentStatusFailed, PaymentStatusFailed, false},
+		{PaymentStatusPending, PaymentStatusRefunded, false},
+		{PaymentStatusFailed, PaymentStatusRefunded, false},
+		{PaymentStatusRefunded, PaymentStatusRefunded, false},
+		{PaymentStatusRefunded, PaymentStatusPartiallyRefunded, false},
+		{PaymentStatusRefunded, PaymentStatusCompleted, false},
+		{PaymentStatusRefunded, PaymentStatusFailed, false},
+		{PaymentStatusPartiallyRefunded, PaymentStatusFailed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, slices.Contains(paymentTransitions[tt.to], tt.from))
+		})
+	}
+}
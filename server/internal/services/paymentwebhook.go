@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/payments"
+)
+
+// webhookClaimTimeout is how long an event may sit in processing (e.g. after a
+// crash) before a redelivery is allowed to claim it again
+const webhookClaimTimeout = 5 * time.Minute
+
+var errUnhandledWebhookEvent = errors.New("unhandled webhook event type")
+
+// ProcessWebhook records a verified webhook event and applies it once. Duplicate
+// deliveries of an event that was already handled are skipped. Events that
+// would move a payment through an illegal transition (e.g. a late
+// payment_failed after the payment completed) are recorded as ignored. Only
+// processing failures are returned, so the provider retries those.
+func (p *PaymentService) ProcessWebhook(ctx context.Context, event *payments.WebhookEvent) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errs.ErrFailedToRecordWebhookEvent
+	}
+
+	record := &models.WebhookEvent{
+		Provider:  p.provider.Name(),
+		EventID:   event.ID,
+		EventType: string(event.Type),
+		Payload:   payload,
+	}
+
+	claimed, err := p.webhookRepo.ClaimWebhookEvent(ctx, record, webhookClaimTimeout)
+	if err != nil {
+		log.Printf("failed to record %s webhook event %s: %v", record.Provider, event.ID, err)
+		return errs.ErrFailedToRecordWebhookEvent
+	}
+
+	if !claimed {
+		log.Printf("skipping duplicate %s webhook event %s (%s)", record.Provider, event.ID, event.Type)
+		return nil
+	}
+
+	if err := p.processWebhookEvent(ctx, record, event); err != nil {
+		return errs.ErrFailedToProcessWebhookEvent
+	}
+
+	return nil
+}
+
+// ReplayWebhookEvent processes a failed event again from its stored payload.
+// The returned event carries the outcome of the replay.
+func (p *PaymentService) ReplayWebhookEvent(ctx context.Context, id string) (*models.WebhookEvent, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	record, err := p.webhookRepo.ClaimWebhookEventReplay(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrWebhookEventNotFound) || errors.Is(err, errs.ErrWebhookEventNotReplayable) {
+			return nil, err
+		}
+		log.Printf("failed to claim webhook event %s for replay: %v", id, err)
+		return nil, errs.ErrFailedToProcessWebhookEvent
+	}
+
+	event := &payments.WebhookEvent{}
+	if err := json.Unmarshal(record.Payload, event); err != nil {
+		log.Printf("failed to decode stored webhook event %s: %v", id, err)
+		record.Status, record.Error = models.WebhookEventFailed, err.Error()
+		if err := p.webhookRepo.FinishWebhookEvent(ctx, record); err != nil {
+			log.Printf("failed to update webhook event %s: %v", id, err)
+		}
+		return record, nil
+	}
+
+	// the outcome is recorded on the event, a failed replay is not a failed request
+	_ = p.processWebhookEvent(ctx, record, event)
+
+	return record, nil
+}
+
+func (p *PaymentService) GetWebhookEvents(ctx context.Context, status string, page *pagination.Params) (*pagination.Page[*models.WebhookEvent], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if status != "" && !models.IsValidWebhookEventStatus(status) {
+		return nil, errs.ErrInvalidWebhookEventStatus
+	}
+
+	events, total, err := p.webhookRepo.GetWebhookEvents(ctx, status, page)
+	if err != nil {
+		log.Printf("failed to get webhook events: %v", err)
+		return nil, errs.ErrFailedToRetrieveWebhookEvents
+	}
+
+	return pagination.NewPage(events, page, total, func(last *models.WebhookEvent) *pagination.Cursor {
+		return pagination.Keyset(last.ReceivedAt, last.ID)
+	}), nil
+}
+
+// processWebhookEvent applies a claimed event and records the outcome on it
+func (p *PaymentService) processWebhookEvent(ctx context.Context, record *models.WebhookEvent, event *payments.WebhookEvent) error {
+
+	err := p.applyWebhookEvent(ctx, event)
+
+	switch {
+	case err == nil:
+		record.Status, record.Error = models.WebhookEventProcessed, ""
+	case errors.Is(err, errs.ErrIllegalPaymentTransition), errors.Is(err, errUnhandledWebhookEvent):
+		log.Printf("ignoring %s webhook event %s (%s): %v", record.Provider, event.ID, event.Type, err)
+		record.Status, record.Error, err = models.WebhookEventIgnored, err.Error(), nil
+	default:
+		log.Printf("failed to process %s webhook event %s (%s): %v", record.Provider, event.ID, event.Type, err)
+		record.Status, record.Error = models.WebhookEventFailed, err.Error()
+	}
+
+	if ferr := p.webhookRepo.FinishWebhookEvent(ctx, record); ferr != nil {
+		log.Printf("failed to update webhook event %s: %v", record.ID, ferr)
+		if err == nil {
+			err = ferr
+		}
+	}
+
+	return err
+}
+
+func (p *PaymentService) applyWebhookEvent(ctx context.Context, event *payments.WebhookEvent) error {
+
+	// bid holds are checked with the provider when they are used, their events carry no payment
+	if event.IsHold() {
+		return errUnhandledWebhookEvent
+	}
+
+	switch event.Type {
+	case payments.EventCheckoutCompleted:
+		return p.handleCheckoutSessionCompleted(ctx, event)
+	case payments.EventPaymentSucceeded:
+		return p.handlePaymentIntentSucceeded(ctx, event)
+	case payments.EventPaymentFailed:
+		return p.handlePaymentIntentFailed(ctx, event)
+	case payments.EventChargeRefunded:
+		return p.handleChargeRefunded(ctx, event)
+	}
+
+	return errUnhandledWebhookEvent
+}
+
+func (p *PaymentService) webhookPayment(ctx context.Context, event *payments.WebhookEvent) (*models.Payment, error) {
+
+	orderID, buyerID := event.OrderID(), event.BuyerID()
+	if orderID == "" || buyerID == "" {
+		log.Printf("missing order_id or buyer_id in metadata of %s event %s", event.Type, event.ID)
+		return nil, errs.ErrMissingRequiredSessionMetadata
+	}
+
+	payment, err := p.repo.GetPayment(ctx, orderID, buyerID)
+	if err != nil {
+		log.Printf("failed to get payment for order %s and buyer %s: %v", orderID, buyerID, err)
+		return nil, errs.ErrFailedToGetPayment
+	}
+
+	return payment, nil
+}
+
+func (p *PaymentService) handleCheckoutSessionCompleted(ctx context.Context, event *payments.WebhookEvent) error {
+
+	payment, err := p.webhookPayment(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	// an unpaid completed session (delayed payment methods) stays pending until payment_intent.succeeded
+	if event.PaymentStatus != payments.PaymentStatusPaid {
+		log.Printf("checkout session %s completed with payment status '%s', payment %s stays %s", event.SessionID, event.PaymentStatus, payment.ID, payment.Status)
+		return nil
+	}
+
+	if err := p.setPaymentIntent(ctx, payment, event.PaymentIntentID); err != nil {
+		return err
+	}
+
+	return p.completePayment(ctx, payment)
+}
+
+func (p *PaymentService) handlePaymentIntentSucceeded(ctx context.Context, event *payments.WebhookEvent) error {
+
+	payment, err := p.webhookPayment(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	if err := p.setPaymentIntent(ctx, payment, event.PaymentIntentID); err != nil {
+		return err
+	}
+
+	return p.completePayment(ctx, payment)
+}
+
+func (p *PaymentService) handlePaymentIntentFailed(ctx context.Context, event *payments.WebhookEvent) error {
+
+	payment, err := p.webhookPayment(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	return p.transitionPayment(ctx, payment, PaymentStatusFailed)
+}
+
+func (p *PaymentService) setPaymentIntent(ctx context.Context, payment *models.Payment, paymentIntentID string) error {
+
+	if paymentIntentID == "" || payment.PaymentIntentID == paymentIntentID {
+		return nil
+	}
+
+	if err := p.repo.SetPaymentIntent(ctx, payment.ID, paymentIntentID); err != nil {
+		log.Printf("failed to set payment intent of payment %s: %v", payment.ID, err)
+		return errs.ErrFailedToUpdatePayment
+	}
+
+	payment.PaymentIntentID = paymentIntentID
+	return nil
+}
+
+// handleChargeRefunded reconciles refunds made directly at the provider (e.g.
+// in its dashboard). The event carries the total refunded so far of the
+// checkout charge; refunds made through the API are already reserved on the
+// payment before the provider is called, and take from the checkout charge
+// before the deposit, so only the difference is recorded.
+func (p *PaymentService) handleChargeRefunded(ctx context.Context, event *payments.WebhookEvent) error {
+
+	payment, err := p.repo.GetPaymentByIntentID(ctx, event.PaymentIntentID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return err
+		}
+		log.Printf("failed to get payment for payment intent %s: %v", event.PaymentIntentID, err)
+		return errs.ErrFailedToGetPayment
+	}
+
+	external := event.AmountRefunded - min(payments.ToCents(payment.RefundedAmount), payments.ToCents(payment.Amount))
+	if external <= 0 {
+		return nil
+	}
+
+	refund := &models.PaymentRefund{
+		PaymentID: payment.ID,
+		Amount:    payments.FromCents(external),
+		Reason:    "refunded at " + p.provider.Name(),
+		Status:    models.RefundSucceeded,
+	}
+
+	updated, err := p.repo.CreateRefund(ctx, refund, paymentTransitions[PaymentStatusRefunded])
+	if err != nil {
+		if errors.Is(err, errs.ErrIllegalPaymentTransition) || errors.Is(err, errs.ErrRefundExceedsPayment) {
+			return err
+		}
+		log.Printf("failed to record refund of payment %s: %v", payment.ID, err)
+		return errs.ErrFailedToUpdatePayment
+	}
+
+	p.ledger.DebitRefund(ctx, updated, refund)
+	p.notifyRefund(ctx, updated, refund, "")
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+type SavedSearchService struct {
+	repo store.SavedSearchRepository
+}
+
+func NewSavedSearchService(repo store.SavedSearchRepository) *SavedSearchService {
+	return &SavedSearchService{
+		repo: repo,
+	}
+}
+
+func validateSavedSearch(req *models.SavedSearch) error {
+	if req.UserID == "" || strings.TrimSpace(req.Name) == "" {
+		return errs.ErrInvalidSavedSearch
+	}
+
+	if req.Filter.MinPrice < 0 || req.Filter.MaxPrice < 0 {
+		return errs.ErrInvalidSavedSearch
+	}
+
+	if req.Filter.MaxPrice > 0 && req.Filter.MinPrice > req.Filter.MaxPrice {
+		return errs.ErrInvalidPriceRange
+	}
+
+	return nil
+}
+
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, req *models.SavedSearch) (*models.SavedSearch, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := validateSavedSearch(req); err != nil {
+		return nil, err
+	}
+
+	search := &models.SavedSearch{
+		UserID:            req.UserID,
+		Name:              strings.TrimSpace(req.Name),
+		Filter:            req.Filter,
+		NotifyNewListings: req.NotifyNewListings,
+	}
+	search.Filter.Keyword = strings.TrimSpace(search.Filter.Keyword)
+
+	if err := s.repo.CreateSavedSearch(ctx, search); err != nil {
+		return nil, errs.ErrFailedToSaveSearch
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchService) GetSavedSearches(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[*models.SavedSearch], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	searches, total, err := s.repo.GetSavedSearches(ctx, userID, page)
+	if err != nil {
+		return nil, errs.ErrFailedToRetrieveSavedSearch
+	}
+
+	return pagination.NewPage(searches, page, total, func(last *models.SavedSearch) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
+}
+
+func (s *SavedSearchService) GetSavedSearch(ctx context.Context, id, userID string) (*models.SavedSearch, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	search, err := s.repo.GetSavedSearchByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrSavedSearchNotFound) {
+			return nil, errs.ErrSavedSearchNotFound
+		}
+		return nil, errs.ErrFailedToRetrieveSavedSearch
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchService) UpdateSavedSearch(ctx context.Context, req *models.SavedSearch) (*models.SavedSearch, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := validateSavedSearch(req); err != nil {
+		return nil, err
+	}
+
+	search := &models.SavedSearch{
+		ID:                req.ID,
+		UserID:            req.UserID,
+		Name:              strings.TrimSpace(req.Name),
+		Filter:            req.Filter,
+		NotifyNewListings: req.NotifyNewListings,
+	}
+	search.Filter.Keyword = strings.TrimSpace(search.Filter.Keyword)
+
+	if err := s.repo.UpdateSavedSearch(ctx, search); err != nil {
+		if errors.Is(err, errs.ErrSavedSearchNotFound) {
+			return nil, errs.ErrSavedSearchNotFound
+		}
+		return nil, errs.ErrFailedToUpdateSavedSearch
+	}
+
+	return search, nil
+}
+
+// SetSavedSearchAlerts turns new-listing alerts on or off for a single saved search
+func (s *SavedSearchService) SetSavedSearchAlerts(ctx context.Context, id, userID string, enabled bool) (*models.SavedSearch, error) {
+
+	search, err := s.GetSavedSearch(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	search.NotifyNewListings = enabled
+
+	return s.UpdateSavedSearch(ctx, search)
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, id, userID string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := s.repo.DeleteSavedSearch(ctx, id, userID); err != nil {
+		if errors.Is(err, errs.ErrSavedSearchNotFound) {
+			return "", errs.ErrSavedSearchNotFound
+		}
+		return "", errs.ErrFailedToDeleteSavedSearch
+	}
+
+	return "saved search deleted successfully", nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/puremike/online_auction_api/internal/utils"
+)
+
+// SessionService lets users see where they are logged in and end those
+// sessions, and lets admins do the same for any user. Ending a session stops
+// its refresh token; access tokens already issued run until they expire.
+type SessionService struct {
+	repo     store.SessionRepository
+	userRepo store.UserRepository
+}
+
+func NewSessionService(repo store.SessionRepository, userRepo store.UserRepository) *SessionService {
+	return &SessionService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// GetSessions lists a user's active sessions, marking the one refreshToken
+// belongs to as current
+func (s *SessionService) GetSessions(ctx context.Context, userID, refreshToken string) ([]*models.Session, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	currentHash := ""
+	if refreshToken != "" {
+		currentHash = utils.HashToken(refreshToken)
+	}
+
+	sessions, err := s.repo.GetSessions(ctx, userID, currentHash)
+	if err != nil {
+		log.Printf("failed to get sessions of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToRetrieveSessions
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := s.repo.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, errs.ErrSessionNotFound) {
+			return err
+		}
+		log.Printf("failed to revoke session %s of user %s: %v", sessionID, userID, err)
+		return errs.ErrFailedToRevokeSession
+	}
+
+	return nil
+}
+
+// RevokeAllSessions logs a user out everywhere and returns how many sessions
+// were ended
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID string) (int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	revoked, err := s.repo.RevokeUserSessions(ctx, userID, "")
+	if err != nil {
+		log.Printf("failed to revoke sessions of user %s: %v", userID, err)
+		return 0, errs.ErrFailedToRevokeSession
+	}
+
+	return revoked, nil
+}
+
+// AdminGetSessions lists the active sessions of any user
+func (s *SessionService) AdminGetSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if _, err := s.userRepo.GetUserById(ctx, userID); err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get user %s to list sessions: %v", userID, err)
+		return nil, errs.ErrFailedToRetrieveSessions
+	}
+
+	return s.GetSessions(ctx, userID, "")
+}
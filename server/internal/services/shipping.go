@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/payments"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// ShippingService lets the winner of an auction choose how it is delivered
+// from the options the seller offers, and prices that choice at checkout
+type ShippingService struct {
+	repo        store.ShippingRepository
+	addressRepo store.AddressRepository
+	auctionRepo store.AuctionRepository
+}
+
+func NewShippingService(repo store.ShippingRepository, addressRepo store.AddressRepository, auctionRepo store.AuctionRepository) *ShippingService {
+	return &ShippingService{
+		repo:        repo,
+		addressRepo: addressRepo,
+		auctionRepo: auctionRepo,
+	}
+}
+
+// validateShippingOptions checks the options a seller offers: each method at
+// most once, flat rates with a price, and weight rates in increasing weight
+// bands that cover the item
+func validateShippingOptions(options []models.ShippingOption, weightKg float64) error {
+
+	seen := map[string]bool{}
+	for _, option := range options {
+		if seen[option.Method] {
+			return errs.ErrInvalidShippingOptions
+		}
+		seen[option.Method] = true
+
+		switch option.Method {
+		case models.ShippingFlat:
+			if option.Price <= 0 {
+				return errs.ErrInvalidShippingOptions
+			}
+		case models.ShippingWeight:
+			if weightKg <= 0 || len(option.Rates) == 0 {
+				return errs.ErrInvalidShippingOptions
+			}
+			for i, rate := range option.Rates {
+				last := i == len(option.Rates)-1
+				if rate.UpToKg == 0 && !last || i > 0 && rate.UpToKg != 0 && rate.UpToKg <= option.Rates[i-1].UpToKg {
+					return errs.ErrInvalidShippingOptions
+				}
+			}
+			if _, ok := shippingCost(option, weightKg); !ok {
+				return errs.ErrInvalidShippingOptions
+			}
+		case models.ShippingFree, models.ShippingPickup:
+		default:
+			return errs.ErrInvalidShippingOptions
+		}
+	}
+
+	return nil
+}
+
+// shippingCost prices an option for an item of the given weight, in cents. A
+// weight-based option is priced by the first band the weight fits in.
+func shippingCost(option models.ShippingOption, weightKg float64) (int64, bool) {
+	switch option.Method {
+	case models.ShippingFlat:
+		return payments.ToCents(option.Price), true
+	case models.ShippingWeight:
+		for _, rate := range option.Rates {
+			if rate.UpToKg == 0 || weightKg <= rate.UpToKg {
+				return payments.ToCents(rate.Price), true
+			}
+		}
+		return 0, false
+	}
+	return 0, true
+}
+
+// shippingLabel names the shipping line item, e.g. "Shipping (flat)"
+func shippingLabel(method string) string {
+	if method == "" {
+		return "Shipping"
+	}
+	return "Shipping (" + method + ")"
+}
+
+func findShippingOption(auction *models.Auction, method string) (models.ShippingOption, bool) {
+	i := slices.IndexFunc(auction.ShippingOptions, func(o models.ShippingOption) bool { return o.Method == method })
+	if i < 0 {
+		return models.ShippingOption{}, false
+	}
+	return auction.ShippingOptions[i], true
+}
+
+// SelectShipping records how the winner of a closed auction wants it
+// delivered. It can be changed until the auction is paid.
+func (s *ShippingService) SelectShipping(ctx context.Context, auctionID, buyerID string, req *models.ShippingSelectionRequest) (*models.ShippingSelection, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	auction, err := s.auctionRepo.GetAuctionById(ctx, auctionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAuctionNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToSelectShipping
+	}
+
+	if auction.Status != "closed" || auction.WinnerID == "" || auction.WinnerID == auction.SellerID || auction.WinnerID != buyerID {
+		return nil, errs.ErrNotAllowedToSelectShipping
+	}
+	if auction.IsPaid {
+		return nil, errs.ErrAuctionAlreadyPaid
+	}
+
+	option, ok := findShippingOption(auction, req.Method)
+	if !ok {
+		return nil, errs.ErrShippingMethodNotOffered
+	}
+
+	cost, ok := shippingCost(option, auction.WeightKg)
+	if !ok {
+		return nil, errs.ErrShippingMethodNotOffered
+	}
+
+	selection := &models.ShippingSelection{
+		AuctionID: auctionID,
+		BuyerID:   buyerID,
+		Method:    req.Method,
+		Cost:      payments.FromCents(cost),
+	}
+
+	if req.Method != models.ShippingPickup {
+		if req.AddressID == "" {
+			return nil, errs.ErrShippingAddressRequired
+		}
+
+		address, err := s.addressRepo.GetAddressByID(ctx, req.AddressID, buyerID)
+		if err != nil {
+			if errors.Is(err, errs.ErrAddressNotFound) {
+				return nil, err
+			}
+			log.Printf("failed to get address %s of buyer %s: %v", req.AddressID, buyerID, err)
+			return nil, errs.ErrFailedToSelectShipping
+		}
+		selection.Address = address
+	}
+
+	if err := s.repo.SaveShipping(ctx, selection); err != nil {
+		log.Printf("failed to save shipping of auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToSelectShipping
+	}
+
+	return selection, nil
+}
+
+// GetShipping returns the shipping chosen for an auction to its buyer, its
+// seller or an admin. The seller only sees where to ship once the auction is
+// paid.
+func (s *ShippingService) GetShipping(ctx context.Context, auctionID string, user *models.User) (*models.ShippingSelection, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	auction, err := s.auctionRepo.GetAuctionById(ctx, auctionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAuctionNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToRetrieveShipping
+	}
+
+	isBuyer := auction.Status == "closed" && auction.WinnerID == user.ID && auction.WinnerID != auction.SellerID
+	isSeller := auction.SellerID == user.ID
+	if !user.IsAdmin && !isBuyer && !isSeller {
+		return nil, errs.ErrNotAllowedToViewShipping
+	}
+
+	selection, err := s.repo.GetShipping(ctx, auctionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrShippingSelectionNotFound) {
+			return nil, err
+		}
+		log.Printf("failed to get shipping of auction %s: %v", auctionID, err)
+		return nil, errs.ErrFailedToRetrieveShipping
+	}
+
+	if !user.IsAdmin && !isBuyer && !auction.IsPaid {
+		selection.Address = nil
+	}
+
+	return selection, nil
+}
+
+// CheckoutShipping prices the buyer's shipping choice for checkout, in cents,
+// against the options the auction offers now. An auction without shipping
+// options ships nothing, and returns an empty method.
+func (s *ShippingService) CheckoutShipping(ctx context.Context, auction *models.Auction, buyerID string) (string, int64, error) {
+
+	if len(auction.ShippingOptions) == 0 {
+		return "", 0, nil
+	}
+
+	selection, err := s.repo.GetShipping(ctx, auction.ID)
+	if err != nil {
+		if errors.Is(err, errs.ErrShippingSelectionNotFound) {
+			return "", 0, errs.ErrShippingNotSelected
+		}
+		log.Printf("failed to get shipping of auction %s: %v", auction.ID, err)
+		return "", 0, errs.ErrFailedToCreateStripeCheckout
+	}
+
+	if selection.BuyerID != buyerID {
+		return "", 0, errs.ErrShippingNotSelected
+	}
+
+	// the seller may have changed the options since the buyer chose
+	option, ok := findShippingOption(auction, selection.Method)
+	if !ok {
+		return "", 0, errs.ErrShippingNotSelected
+	}
+
+	cost, ok := shippingCost(option, auction.WeightKg)
+	if !ok {
+		return "", 0, errs.ErrShippingNotSelected
+	}
+
+	return selection.Method, cost, nil
+}
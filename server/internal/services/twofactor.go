@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/auth"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/puremike/online_auction_api/internal/utils"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorService manages TOTP two-factor authentication. Users enroll an
+// authenticator app with a secret, confirm it with a first code and get
+// single-use recovery codes for when the app is lost. Logins of enrolled
+// users stop at a challenge token until a code is given.
+type TwoFactorService struct {
+	repo         store.TwoFactorRepository
+	tokens       store.UserTokenRepository
+	sealer       *auth.Sealer
+	issuer       string
+	challengeTTL time.Duration
+	maxAttempts  int
+}
+
+func NewTwoFactorService(repo store.TwoFactorRepository, tokens store.UserTokenRepository, sealer *auth.Sealer, issuer string, challengeTTL time.Duration, maxAttempts int) *TwoFactorService {
+	return &TwoFactorService{
+		repo:         repo,
+		tokens:       tokens,
+		sealer:       sealer,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// Setup starts an enrollment with a new secret. Two-factor stays off until the
+// enrollment is confirmed; starting again replaces an unconfirmed secret.
+func (t *TwoFactorService) Setup(ctx context.Context, user *models.User) (*models.TwoFactorSetupResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	sealed, err := t.sealer.Seal(secret)
+	if err != nil {
+		log.Printf("failed to seal totp secret of user %s: %v", user.ID, err)
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	if err := t.repo.StartTwoFactor(ctx, user.ID, sealed); err != nil {
+		if errors.Is(err, errs.ErrTwoFactorAlreadyEnabled) {
+			return nil, err
+		}
+		log.Printf("failed to start two-factor setup of user %s: %v", user.ID, err)
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(t.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm turns two-factor on once the user proves the app was enrolled with
+// a code from it, and returns their recovery codes
+func (t *TwoFactorService) Confirm(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	tf, err := t.getTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.EnabledAt != nil {
+		return nil, errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := t.sealer.Open(tf.Secret)
+	if err != nil {
+		log.Printf("failed to open totp secret of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	step, ok := auth.ValidateTOTP(secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, errs.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	if err := t.repo.EnableTwoFactor(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, errs.ErrTwoFactorAlreadyEnabled) {
+			return nil, err
+		}
+		log.Printf("failed to enable two-factor of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor off. It takes a code from the app or a recovery
+// code, so a stolen session alone can't remove it.
+func (t *TwoFactorService) Disable(ctx context.Context, userID, code string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := t.verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := t.repo.DisableTwoFactor(ctx, userID); err != nil {
+		log.Printf("failed to disable two-factor of user %s: %v", userID, err)
+		return errs.ErrFailedToSetUpTwoFactor
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes, given a code from
+// the app
+func (t *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := t.verifyTOTP(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	if err := t.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		log.Printf("failed to replace recovery codes of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToSetUpTwoFactor
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Enabled reports whether a user has confirmed two-factor
+func (t *TwoFactorService) Enabled(ctx context.Context, userID string) (bool, error) {
+
+	tf, err := t.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrTwoFactorNotEnabled) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return tf.EnabledAt != nil, nil
+}
+
+// Challenge issues the token a login whose password checked out is completed
+// with, together with a code
+func (t *TwoFactorService) Challenge(ctx context.Context, user *models.User) (string, error) {
+
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.tokens.CreateToken(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenTwoFactorLogin,
+		TokenHash: hash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(t.challengeTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CompleteChallenge checks the code given for a login challenge and returns
+// the ID of the user logging in. A challenge takes maxAttempts wrong codes
+// before the user has to enter their password again.
+func (t *TwoFactorService) CompleteChallenge(ctx context.Context, challenge, code string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	token, err := t.tokens.GetActiveToken(ctx, utils.HashToken(challenge), models.TokenTwoFactorLogin)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidToken) {
+			return "", errs.ErrInvalidTwoFactorChallenge
+		}
+		log.Printf("failed to get two-factor login challenge: %v", err)
+		return "", errs.ErrFailedToVerifyTwoFactor
+	}
+
+	if err := t.verify(ctx, token.UserID, code); err != nil {
+		if errors.Is(err, errs.ErrInvalidTwoFactorCode) {
+			if err := t.tokens.FailTokenAttempt(ctx, token.ID, t.maxAttempts); err != nil {
+				log.Printf("failed to count wrong code against challenge %s: %v", token.ID, err)
+			}
+		}
+		return "", err
+	}
+
+	if err := t.tokens.UseToken(ctx, token.ID); err != nil {
+		if errors.Is(err, errs.ErrInvalidToken) {
+			return "", errs.ErrInvalidTwoFactorChallenge
+		}
+		log.Printf("failed to use two-factor login challenge %s: %v", token.ID, err)
+		return "", errs.ErrFailedToVerifyTwoFactor
+	}
+
+	return token.UserID, nil
+}
+
+// verify accepts a code from the app or an unused recovery code
+func (t *TwoFactorService) verify(ctx context.Context, userID, code string) error {
+
+	code = normalizeCode(code)
+	if len(code) != 6 {
+		tf, err := t.getTwoFactor(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if tf.EnabledAt == nil {
+			return errs.ErrTwoFactorNotEnabled
+		}
+
+		if err := t.repo.UseRecoveryCode(ctx, userID, utils.HashToken(code)); err != nil {
+			if errors.Is(err, errs.ErrInvalidTwoFactorCode) {
+				return err
+			}
+			log.Printf("failed to use recovery code of user %s: %v", userID, err)
+			return errs.ErrFailedToVerifyTwoFactor
+		}
+		return nil
+	}
+
+	return t.verifyTOTP(ctx, userID, code)
+}
+
+// verifyTOTP accepts a code from the app, once
+func (t *TwoFactorService) verifyTOTP(ctx context.Context, userID, code string) error {
+
+	tf, err := t.getTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if tf.EnabledAt == nil {
+		return errs.ErrTwoFactorNotEnabled
+	}
+
+	secret, err := t.sealer.Open(tf.Secret)
+	if err != nil {
+		log.Printf("failed to open totp secret of user %s: %v", userID, err)
+		return errs.ErrFailedToVerifyTwoFactor
+	}
+
+	step, ok := auth.ValidateTOTP(secret, normalizeCode(code), time.Now())
+	if !ok || step <= tf.LastStep {
+		return errs.ErrInvalidTwoFactorCode
+	}
+
+	if err := t.repo.UseTOTPStep(ctx, userID, step); err != nil {
+		if errors.Is(err, errs.ErrInvalidTwoFactorCode) {
+			return err
+		}
+		log.Printf("failed to record totp step of user %s: %v", userID, err)
+		return errs.ErrFailedToVerifyTwoFactor
+	}
+
+	return nil
+}
+
+func (t *TwoFactorService) getTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error) {
+
+	tf, err := t.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrTwoFactorNotEnabled) {
+			return nil, err
+		}
+		log.Printf("failed to get two-factor of user %s: %v", userID, err)
+		return nil, errs.ErrFailedToVerifyTwoFactor
+	}
+
+	return tf, nil
+}
+
+// normalizeCode drops the spaces and dashes apps and recovery codes are
+// displayed with
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx and the hashes they
+// are stored under
+func newRecoveryCodes() (codes, hashes []string, err error) {
+
+	for range recoveryCodeCount {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	return codes, hashes, nil
+}
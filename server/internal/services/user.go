@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/puremike/online_auction_api/internal/cached"
 	"github.com/puremike/online_auction_api/internal/config"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/store"
 	"github.com/puremike/online_auction_api/internal/utils"
 	"golang.org/x/text/cases"
@@ -21,16 +23,22 @@ import (
 )
 
 type UserService struct {
-	repo   store.UserRepository
-	app    *config.Application
-	cached cached.CachedUserInterface
+	repo         store.UserRepository
+	sessionRepo  store.SessionRepository
+	feedbackRepo store.FeedbackRepository
+	app          *config.Application
+	cached       cached.CachedUserInterface
+	twoFactor    TwoFactorServiceInterface
 }
 
-func NewUserService(repo store.UserRepository, app *config.Application, cached cached.CachedUserInterface) *UserService {
+func NewUserService(repo store.UserRepository, sessionRepo store.SessionRepository, feedbackRepo store.FeedbackRepository, app *config.Application, cached cached.CachedUserInterface, twoFactor TwoFactorServiceInterface) *UserService {
 	return &UserService{
-		repo:   repo,
-		app:    app,
-		cached: cached,
+		repo:         repo,
+		sessionRepo:  sessionRepo,
+		feedbackRepo: feedbackRepo,
+		app:          app,
+		cached:       cached,
+		twoFactor:    twoFactor,
 	}
 }
 
@@ -78,7 +86,19 @@ func (u *UserService) CreateUser(ctx context.Context, user *models.User) (*model
 	return res, nil
 }
 
-func (u *UserService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+// Login checks a user's password. Users with two-factor get a challenge token
+// to complete the login with instead of a session; with
+// TWO_FACTOR_REQUIRED_FOR_ADMINS set, admins without it are turned away.
+func (u *UserService) Login(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error) {
+	return u.login(ctx, req, client, false)
+}
+
+// AdminLogin is Login for admins only
+func (u *UserService) AdminLogin(ctx context.Context, req *models.LoginRequest, client *models.Client) (*models.LoginResponse, error) {
+	return u.login(ctx, req, client, true)
+}
+
+func (u *UserService) login(ctx context.Context, req *models.LoginRequest, client *models.Client, admin bool) (*models.LoginResponse, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
@@ -99,6 +119,86 @@ func (u *UserService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		return &models.LoginResponse{}, errs.ErrInvalidCredentials
 	}
 
+	if admin && !user.IsAdmin {
+		return &models.LoginResponse{}, errs.ErrNotAnAdmin
+	}
+
+	enabled, err := u.twoFactor.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Printf("failed to check two-factor of user %s: %v", user.ID, err)
+		return &models.LoginResponse{}, errs.ErrFailedToVerifyTwoFactor
+	}
+
+	if enabled {
+		challenge, err := u.twoFactor.Challenge(ctx, user)
+		if err != nil {
+			log.Printf("failed to issue two-factor challenge to user %s: %v", user.ID, err)
+			return &models.LoginResponse{}, errs.ErrFailedToVerifyTwoFactor
+		}
+		return &models.LoginResponse{ID: user.ID, Username: user.Username, TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	if user.IsAdmin && u.app.AppConfig.TwoFactorConf.RequiredForAdmins {
+		return &models.LoginResponse{}, errs.ErrTwoFactorRequired
+	}
+
+	return u.issueTokens(ctx, user, client)
+}
+
+// CompleteTwoFactorLogin exchanges a login challenge and a code from the
+// user's authenticator app, or a recovery code, for a session
+func (u *UserService) CompleteTwoFactorLogin(ctx context.Context, req *models.TwoFactorLoginRequest, client *models.Client) (*models.LoginResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	userID, err := u.twoFactor.CompleteChallenge(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		return &models.LoginResponse{}, err
+	}
+
+	user, err := u.repo.GetUserById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return &models.LoginResponse{}, errs.ErrInvalidTwoFactorChallenge
+		}
+		return &models.LoginResponse{}, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	return u.issueTokens(ctx, user, client)
+}
+
+// issueTokens starts a session: an access token and the first refresh token of
+// a new family
+func (u *UserService) issueTokens(ctx context.Context, user *models.User, client *models.Client) (*models.LoginResponse, error) {
+
+	token, err := u.accessToken(user)
+	if err != nil {
+		return &models.LoginResponse{}, err
+	}
+
+	refreshToken, err := u.app.JwtAUth.GenerateRefreshToken()
+	if err != nil {
+		return &models.LoginResponse{}, errs.ErrFailedToGenRefreshToken
+	}
+
+	session := &models.Session{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: time.Now().Add(u.app.AppConfig.AuthConfig.RefreshTokenExp),
+	}
+	if err := u.sessionRepo.CreateSession(ctx, session, &models.RefreshToken{TokenHash: utils.HashToken(refreshToken)}); err != nil {
+		log.Printf("failed to store refresh token of user %s: %v", user.ID, err)
+		return &models.LoginResponse{}, errs.ErrFailedToStoreToken
+	}
+
+	return &models.LoginResponse{ID: user.ID, Username: user.Username, Token: token, RefreshToken: refreshToken}, nil
+}
+
+func (u *UserService) accessToken(user *models.User) (string, error) {
+
 	claims := jwt.MapClaims{
 		"sub":     user.ID,
 		"isAdmin": user.IsAdmin,
@@ -111,28 +211,10 @@ func (u *UserService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 
 	token, err := u.app.JwtAUth.GenerateToken(claims)
 	if err != nil {
-		if errors.Is(err, errs.ErrFailedToGenToken) {
-			return &models.LoginResponse{}, errs.ErrFailedToGenToken
-		}
-		return &models.LoginResponse{}, fmt.Errorf("problem generating token: %w", err)
-	}
-
-	refreshToken, err := u.app.JwtAUth.GenerateRefreshToken()
-	if err != nil {
-		if errors.Is(err, errs.ErrFailedToGenRefreshToken) {
-			return &models.LoginResponse{}, errs.ErrFailedToGenRefreshToken
-		}
-		return &models.LoginResponse{}, fmt.Errorf("problem generating refresh token: %w", err)
-	}
-
-	if err := u.repo.StoreRefreshToken(ctx, user.ID, refreshToken, time.Now().Add(u.app.AppConfig.AuthConfig.RefreshTokenExp)); err != nil {
-		if errors.Is(err, errs.ErrFailedToStoreToken) {
-			return &models.LoginResponse{}, errs.ErrFailedToStoreToken
-		}
-		return &models.LoginResponse{}, fmt.Errorf("problem storing refresh token: %w", err)
+		return "", errs.ErrFailedToGenToken
 	}
 
-	return &models.LoginResponse{ID: user.ID, Username: user.Username, Token: token, RefreshToken: refreshToken}, nil
+	return token, nil
 }
 
 func (u *UserService) UserProfile(ctx context.Context, username string) (*models.UserResponse, error) {
@@ -148,13 +230,22 @@ func (u *UserService) UserProfile(ctx context.Context, username string) (*models
 		return &models.UserResponse{}, fmt.Errorf("failed to retrieve user: %w", err)
 
 	}
+
+	// a profile is still useful without its reputation
+	feedback, err := u.feedbackRepo.GetFeedbackScores(ctx, user.ID)
+	if err != nil {
+		log.Printf("failed to get feedback scores of user %s: %v", user.ID, err)
+	}
+
 	return &models.UserResponse{
-		ID:        user.ID,
-		Username:  MyCaser.String(user.Username),
-		Email:     user.Email,
-		FullName:  MyCaser.String(user.FullName),
-		Location:  MyCaser.String(user.Location),
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Username:      MyCaser.String(user.Username),
+		Email:         user.Email,
+		FullName:      MyCaser.String(user.FullName),
+		Location:      MyCaser.String(user.Location),
+		CreatedAt:     user.CreatedAt,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Feedback:      feedback,
 	}, nil
 }
 
@@ -172,44 +263,75 @@ func (u *UserService) MeProfile(ctx context.Context, userID string) (*models.Use
 
 	}
 	return &models.UserResponse{
-		ID:        user.ID,
-		Username:  MyCaser.String(user.Username),
-		Email:     user.Email,
-		FullName:  MyCaser.String(user.FullName),
-		Location:  MyCaser.String(user.Location),
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Username:      MyCaser.String(user.Username),
+		Email:         user.Email,
+		FullName:      MyCaser.String(user.FullName),
+		Location:      MyCaser.String(user.Location),
+		CreatedAt:     user.CreatedAt,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}, nil
 }
 
-func (u *UserService) Refresh(ctx context.Context, refreshToken string) (string, error) {
+// Refresh exchanges a refresh token for a new access token and the next
+// refresh token of its session. The old refresh token stops working; presenting
+// it again revokes the session, since only a stolen copy would be reused.
+func (u *UserService) Refresh(ctx context.Context, refreshToken string, client *models.Client) (*models.LoginResponse, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	userId, err := u.repo.ValidateRefreshToken(ctx, refreshToken)
-	if err != nil || userId == "" {
-		if errors.Is(err, errs.ErrUserNotFound) {
-			return "", fmt.Errorf("user not found")
+	nextToken, err := u.app.JwtAUth.GenerateRefreshToken()
+	if err != nil {
+		return nil, errs.ErrFailedToGenRefreshToken
+	}
+
+	next := &models.RefreshToken{TokenHash: utils.HashToken(nextToken)}
+	if err := u.sessionRepo.RotateRefreshToken(ctx, utils.HashToken(refreshToken), next, client); err != nil {
+		if errors.Is(err, errs.ErrInvalidRefreshToken) {
+			return nil, err
+		}
+		if errors.Is(err, errs.ErrRefreshTokenReused) {
+			log.Printf("refresh token reused, revoked its session")
+			return nil, err
 		}
-		return "", fmt.Errorf("invalid user details: %w", err)
+		log.Printf("failed to rotate refresh token: %v", err)
+		return nil, errs.ErrFailedToStoreToken
 	}
 
-	// Generate new JWT
-	claims := jwt.MapClaims{
-		"sub": userId,
-		"iss": u.app.AppConfig.AuthConfig.Iss,
-		"aud": u.app.AppConfig.AuthConfig.Aud,
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"exp": time.Now().Add(u.app.AppConfig.AuthConfig.TokenExp).Unix(),
+	user, err := u.repo.GetUserById(ctx, next.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil, errs.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
 	}
 
-	newToken, err := u.app.JwtAUth.GenerateToken(claims)
+	token, err := u.accessToken(user)
 	if err != nil {
-		return "", errs.ErrFailedToGenToken
+		return nil, err
+	}
+
+	return &models.LoginResponse{ID: user.ID, Username: user.Username, Token: token, RefreshToken: nextToken}, nil
+}
+
+// Logout revokes the session the refresh token belongs to, so it can't be
+// used after the cookies are cleared
+func (u *UserService) Logout(ctx context.Context, refreshToken string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	if err := u.sessionRepo.RevokeSessionByToken(ctx, utils.HashToken(refreshToken)); err != nil {
+		log.Printf("failed to revoke refresh token on logout: %v", err)
+		return errs.NewHTTPError("failed to log out", http.StatusInternalServerError)
 	}
 
-	return newToken, nil
+	return nil
 }
 
 func (u *UserService) UpdateProfile(ctx context.Context, req *models.User, id string) (string, error) {
@@ -232,66 +354,78 @@ func (u *UserService) UpdateProfile(ctx context.Context, req *models.User, id st
 		return "", errs.ErrFailedToUpdateUser
 	}
 
+	// the cached user would keep the old email and its verification
+	if err := u.cached.EvictUser(ctx, id); err != nil {
+		log.Printf("failed to evict updated user %s from cache: %v", id, err)
+	}
+
 	return "user updated successfully", nil
 }
 
-func (u *UserService) ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string) (string, error) {
+// ChangePassword sets a new password and signs the user out of every session.
+// The caller gets a new session in place of the one that was revoked.
+func (u *UserService) ChangePassword(ctx context.Context, req *models.PasswordUpdateRequest, id string, client *models.Client) (*models.LoginResponse, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
 	if req.OldPassword == "" || len(req.OldPassword) < 8 || req.ConfirmPassword == "" || len(req.ConfirmPassword) < 8 || req.NewPassword == "" || len(req.NewPassword) < 8 {
-		return "", errs.ErrInvalidPassword
+		return nil, errs.ErrInvalidPassword
 	}
 
 	existingUser, err := u.cached.GetUserFromCache(ctx, id)
 	if err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) {
-			return "", errs.ErrUserNotFound
+			return nil, errs.ErrUserNotFound
 		}
-		return "", fmt.Errorf("failed to retrieve user: %w", err)
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
 	}
 
 	log.Print(existingUser)
 
 	if err := utils.CompareHashedPassword(existingUser.Password, req.OldPassword); err != nil {
-		return "", errs.ErrInvalidPassword
+		return nil, errs.ErrInvalidPassword
 	}
 
 	if req.NewPassword != req.ConfirmPassword {
-		return "", errs.ErrPasswordsDoNotMatch
+		return nil, errs.ErrPasswordsDoNotMatch
 	}
 
 	if req.OldPassword == req.NewPassword {
-		return "", errs.ErrPasswordCannotBeSame
+		return nil, errs.ErrPasswordCannotBeSame
 	}
 
 	hashedPassword, err := utils.HashedPassword(req.NewPassword)
 	if err != nil {
-		return "", errs.ErrFailedToHashPassword
+		return nil, errs.ErrFailedToHashPassword
 	}
 
 	if err := u.repo.ChangePassword(ctx, hashedPassword, id); err != nil {
-		return "", errs.ErrFailedToChangePassword
+		return nil, errs.ErrFailedToChangePassword
+	}
+
+	// the cached user still holds the old password hash
+	if err := u.cached.EvictUser(ctx, id); err != nil {
+		log.Printf("failed to evict user %s from cache after a password change: %v", id, err)
 	}
 
-	return "password changed successfully", nil
+	return u.issueTokens(ctx, existingUser, client)
 }
 
-func (u *UserService) GetUsers(ctx context.Context) (*[]models.UserResponse, error) {
+func (u *UserService) GetUsers(ctx context.Context, page *pagination.Params) (*pagination.Page[models.UserResponse], error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
 	defer cancel()
 
-	users, err := u.repo.GetUsers(ctx)
+	users, total, err := u.repo.GetUsers(ctx, page)
 	if err != nil {
-		return &[]models.UserResponse{}, errors.New("failed to retrieve users")
+		return nil, errors.New("failed to retrieve users")
 	}
 
-	res := &[]models.UserResponse{}
+	res := []models.UserResponse{}
 
 	for _, user := range *users {
-		*res = append(*res, models.UserResponse{
+		res = append(res, models.UserResponse{
 			ID:        user.ID,
 			Username:  MyCaser.String(user.Username),
 			Email:     user.Email,
@@ -300,7 +434,10 @@ func (u *UserService) GetUsers(ctx context.Context) (*[]models.UserResponse, err
 			CreatedAt: user.CreatedAt,
 		})
 	}
-	return res, nil
+
+	return pagination.NewPage(res, page, total, func(last models.UserResponse) *pagination.Cursor {
+		return pagination.Keyset(last.CreatedAt, last.ID)
+	}), nil
 }
 
 func (u *UserService) DeleteUser(ctx context.Context, id string) (string, error) {
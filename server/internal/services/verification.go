@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/cached"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/mailer"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/puremike/online_auction_api/internal/utils"
+)
+
+// VerificationService proves users own the email address they signed up with
+// by mailing them a link with a single-use token. Only the token's hash is
+// stored, and a token only verifies the address it was sent to.
+type VerificationService struct {
+	repo     store.UserTokenRepository
+	mailer   mailer.Mailer
+	cached   cached.CachedUserInterface
+	tokenTTL time.Duration
+	cooldown time.Duration
+	url      string
+}
+
+func NewVerificationService(repo store.UserTokenRepository, mailer mailer.Mailer, cached cached.CachedUserInterface, tokenTTL, cooldown time.Duration, url string) *VerificationService {
+	return &VerificationService{
+		repo:     repo,
+		mailer:   mailer,
+		cached:   cached,
+		tokenTTL: tokenTTL,
+		cooldown: cooldown,
+		url:      url,
+	}
+}
+
+// SendVerification mails a user a new verification link, invalidating the
+// ones sent before
+func (v *VerificationService) SendVerification(ctx context.Context, userID, email string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		return errs.ErrFailedToSendVerification
+	}
+
+	if err := v.repo.CreateToken(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   models.TokenEmailVerification,
+		TokenHash: hash,
+		Email:     email,
+		ExpiresAt: time.Now().Add(v.tokenTTL),
+	}); err != nil {
+		log.Printf("failed to store verification token of user %s: %v", userID, err)
+		return errs.ErrFailedToSendVerification
+	}
+
+	msg := &mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Confirm this is your email address by opening the link below. It expires in %s.\n\n%s?token=%s\n\nIf you didn't sign up, ignore this email.", v.tokenTTL, v.url, url.QueryEscape(token)),
+	}
+	if err := v.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send verification email to user %s through %s: %v", userID, v.mailer.Name(), err)
+		return errs.ErrFailedToSendVerification
+	}
+
+	return nil
+}
+
+// ResendVerification mails an unverified user another link, at most once per
+// cooldown
+func (v *VerificationService) ResendVerification(ctx context.Context, user *models.User) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if user.EmailVerifiedAt != nil {
+		return errs.ErrEmailAlreadyVerified
+	}
+
+	last, err := v.repo.GetLatestToken(ctx, user.ID, models.TokenEmailVerification)
+	if err != nil && !errors.Is(err, errs.ErrTokenNotFound) {
+		log.Printf("failed to get last verification token of user %s: %v", user.ID, err)
+		return errs.ErrFailedToSendVerification
+	}
+	if last != nil && time.Since(last.CreatedAt) < v.cooldown {
+		return errs.ErrVerificationResendTooSoon
+	}
+
+	return v.SendVerification(ctx, user.ID, user.Email)
+}
+
+// VerifyEmail consumes a token from a verification link
+func (v *VerificationService) VerifyEmail(ctx context.Context, token string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	userID, err := v.repo.VerifyEmail(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidToken) {
+			return err
+		}
+		log.Printf("failed to verify email: %v", err)
+		return errs.ErrFailedToVerifyEmail
+	}
+
+	// the cached user would still be unverified
+	if err := v.cached.EvictUser(ctx, userID); err != nil {
+		log.Printf("failed to evict verified user %s from cache: %v", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+type WatchlistService struct {
+	repo store.WatchlistRepository
+}
+
+func NewWatchlistService(repo store.WatchlistRepository) *WatchlistService {
+	return &WatchlistService{
+		repo: repo,
+	}
+}
+
+func (w *WatchlistService) WatchAuction(ctx context.Context, req *models.Watch, sellerID string) (*models.WatchResponse, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if req.UserID == "" || req.AuctionID == "" {
+		return nil, errs.ErrInvalidAuctionDetails
+	}
+
+	if req.UserID == sellerID {
+		return nil, errs.ErrCannotWatchOwnAuction
+	}
+
+	watch := &models.Watch{
+		UserID:            req.UserID,
+		AuctionID:         req.AuctionID,
+		NotifyPriceChange: req.NotifyPriceChange,
+	}
+
+	if err := w.repo.AddWatch(ctx, watch); err != nil {
+		return nil, errs.ErrFailedToWatchAuction
+	}
+
+	return &models.WatchResponse{
+		AuctionID:         watch.AuctionID,
+		NotifyPriceChange: watch.NotifyPriceChange,
+		CreatedAt:         watch.CreatedAt,
+	}, nil
+}
+
+func (w *WatchlistService) UnwatchAuction(ctx context.Context, userID, auctionID string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	if err := w.repo.RemoveWatch(ctx, userID, auctionID); err != nil {
+		if errors.Is(err, errs.ErrWatchNotFound) {
+			return "", errs.ErrWatchNotFound
+		}
+		return "", errs.ErrFailedToUnwatchAuction
+	}
+
+	return "auction removed from watchlist", nil
+}
+
+func (w *WatchlistService) GetWatchedAuctions(ctx context.Context, userID string, page *pagination.Params) (*pagination.Page[models.CreateAuctionResponse], error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryDefaultContext)
+	defer cancel()
+
+	auctions, total, err := w.repo.GetWatchedAuctions(ctx, userID, page)
+	if err != nil {
+		return nil, errors.New("failed to retrieve watched auctions")
+	}
+
+	// the watchlist is ordered by end time, soonest first
+	return auctionPage(*auctions, page, total, func(last models.CreateAuctionResponse) *pagination.Cursor {
+		return pagination.Keyset(last.EndTime, last.ID)
+	}), nil
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type AddressStore struct {
+	db *sql.DB
+}
+
+const addressColumns = `id, user_id, label, full_name, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at`
+
+func scanAddress(row interface{ Scan(dest ...any) error }, a *models.Address) error {
+	return row.Scan(&a.ID, &a.UserID, &a.Label, &a.FullName, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Country, &a.Phone, &a.IsDefault, &a.CreatedAt, &a.UpdatedAt)
+}
+
+// CreateAddress adds an address to the user's address book. A user's first
+// address becomes their default, and a new default replaces the old one.
+func (a *AddressStore) CreateAddress(ctx context.Context, address *models.Address) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// serializes address book changes of the same user
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, address.UserID); err != nil {
+		return err
+	}
+
+	var hasDefault bool
+	if err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM address WHERE user_id = $1 AND is_default)`, address.UserID).Scan(&hasDefault); err != nil {
+		return err
+	}
+
+	if !hasDefault {
+		address.IsDefault = true
+	} else if address.IsDefault {
+		if _, err = tx.ExecContext(ctx, `UPDATE address SET is_default = FALSE, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND is_default`, address.UserID); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO address (user_id, label, full_name, line1, line2, city, region, postal_code, country, phone, is_default)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + addressColumns
+
+	if err = scanAddress(tx.QueryRowContext(ctx, query, address.UserID, address.Label, address.FullName, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.Country, address.Phone, address.IsDefault), address); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (a *AddressStore) GetAddresses(ctx context.Context, userID string, page *pagination.Params) ([]*models.Address, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM address WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+addressColumns+` FROM address WHERE user_id = $1`, []any{userID}, page)
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	addresses := []*models.Address{}
+	for rows.Next() {
+		address := &models.Address{}
+		if err := scanAddress(rows, address); err != nil {
+			return nil, 0, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return addresses, total, nil
+}
+
+func (a *AddressStore) GetAddressByID(ctx context.Context, id, userID string) (*models.Address, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	address := &models.Address{}
+	if err := scanAddress(a.db.QueryRowContext(ctx, `SELECT `+addressColumns+` FROM address WHERE id = $1 AND user_id = $2`, id, userID), address); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrAddressNotFound
+		}
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// UpdateAddress replaces an address. Making it the default unsets the old
+// default; the default can't be unset directly, only replaced.
+func (a *AddressStore) UpdateAddress(ctx context.Context, address *models.Address) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, address.UserID); err != nil {
+		return err
+	}
+
+	if address.IsDefault {
+		if _, err = tx.ExecContext(ctx, `UPDATE address SET is_default = FALSE, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND is_default AND id <> $2`, address.UserID, address.ID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE address
+		SET label = $1, full_name = $2, line1 = $3, line2 = $4, city = $5, region = $6, postal_code = $7, country = $8, phone = $9,
+			is_default = is_default OR $10, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11 AND user_id = $12
+		RETURNING ` + addressColumns
+
+	if err = scanAddress(tx.QueryRowContext(ctx, query, address.Label, address.FullName, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.Country, address.Phone, address.IsDefault, address.ID, address.UserID), address); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrAddressNotFound
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAddress removes an address. If it was the default, the user's newest
+// remaining address becomes the default.
+func (a *AddressStore) DeleteAddress(ctx context.Context, id, userID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return err
+	}
+
+	var wasDefault bool
+	if err = tx.QueryRowContext(ctx, `DELETE FROM address WHERE id = $1 AND user_id = $2 RETURNING is_default`, id, userID).Scan(&wasDefault); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrAddressNotFound
+		}
+		return err
+	}
+
+	if wasDefault {
+		query := `UPDATE address SET is_default = TRUE, updated_at = CURRENT_TIMESTAMP
+			WHERE id = (SELECT id FROM address WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1)`
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -3,17 +3,51 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"html"
 	"log"
 	"strconv"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 )
 
 type AuctionStore struct {
 	db *sql.DB
 }
 
+// auctionSortClauses maps the accepted sort keys to fixed ORDER BY clauses so no
+// user input ever reaches the query text. newest is paged by keyset and has no
+// entry here.
+var auctionSortClauses = map[string]string{
+	models.AuctionSortEndingSoon: `CASE WHEN end_time > NOW() THEN 0 ELSE 1 END, end_time ASC, id ASC`,
+	models.AuctionSortPriceAsc:   `current_price ASC, created_at DESC, id DESC`,
+	models.AuctionSortPriceDesc:  `current_price DESC, created_at DESC, id DESC`,
+	models.AuctionSortMostBids:   `(SELECT COUNT(*) FROM bid b WHERE b.auction_id = auctions.id) DESC, created_at DESC, id DESC`,
+}
+
+// Search snippets mark matched terms with control characters listings have no
+// use for, so the seller's text around them can be HTML-escaped before they
+// are turned into <mark> tags. A stray one only ever becomes a <mark>.
+const (
+	headlineStart = "\x01"
+	headlineStop  = "\x02"
+
+	searchHeadlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// highlightSnippet escapes a ts_headline snippet built from seller text and
+// wraps its matched terms in <mark> tags, the only markup it may contain
+func highlightSnippet(snippet string) string {
+	return headlineMarks.Replace(html.EscapeString(snippet))
+}
+
 func (a *AuctionStore) GetAuctionById(ctx context.Context, id string) (*models.Auction, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
@@ -21,48 +55,58 @@ func (a *AuctionStore) GetAuctionById(ctx context.Context, id string) (*models.A
 
 	auction := &models.Auction{}
 
-	query := `SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, created_at FROM auctions WHERE id = $1`
+	query := `SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, hold_threshold, shipping_options, weight_kg, created_at FROM auctions WHERE id = $1`
 
-	if err := a.db.QueryRowContext(ctx, query, id).Scan(&auction.ID, &auction.SellerID, &auction.WinnerID, &auction.Title, &auction.Description, &auction.StartingPrice, &auction.CurrentPrice, &auction.Type, &auction.Status, &auction.StartTime, &auction.EndTime, &auction.ImagePath, &auction.CreatedAt); err != nil {
+	var shippingOptions []byte
+	if err := a.db.QueryRowContext(ctx, query, id).Scan(&auction.ID, &auction.SellerID, &auction.WinnerID, &auction.Title, &auction.Description, &auction.StartingPrice, &auction.CurrentPrice, &auction.Type, &auction.Status, &auction.StartTime, &auction.EndTime, &auction.ImagePath, &auction.Category, &auction.IsPaid, &auction.HoldThreshold, &shippingOptions, &auction.WeightKg, &auction.CreatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.ErrAuctionNotFound
 		}
 		return nil, err
 	}
 
+	if err := json.Unmarshal(shippingOptions, &auction.ShippingOptions); err != nil {
+		return nil, err
+	}
+
 	return auction, nil
 }
 
-func (a *AuctionStore) GetAuctionBySellerId(ctx context.Context, sellerID string) (*[]models.Auction, error) {
+func (a *AuctionStore) GetAuctionBySellerId(ctx context.Context, sellerID string, page *pagination.Params) (*[]models.Auction, int, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
 	auctions := []models.Auction{}
 
-	query := `SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, created_at FROM auctions WHERE seller_id = $1`
+	var total int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM auctions WHERE seller_id = $1`, sellerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, created_at FROM auctions WHERE seller_id = $1`, []any{sellerID}, page)
 
-	rows, err := a.db.QueryContext(ctx, query, sellerID)
+	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
 		var a models.Auction
-		err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.CreatedAt)
+		err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.CreatedAt)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		auctions = append(auctions, a)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return &auctions, nil
+	return &auctions, total, nil
 }
 
 func (a *AuctionStore) GetAuctionByWinnerId(ctx context.Context, winnerID string) (*models.Auction, error) {
@@ -109,41 +153,111 @@ func (a *AuctionStore) CloseAuction(ctx context.Context, status, id string) erro
 	return nil
 }
 
-func (a *AuctionStore) GetAuctions(ctx context.Context, limit, offset int, filter *models.AuctionFilter) (*[]models.Auction, error) {
+func (a *AuctionStore) GetAuctions(ctx context.Context, page *pagination.Params, filter *models.AuctionFilter) (*[]models.Auction, int, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
 	var auctions []models.Auction
 
-	query := `SELECT id, seller_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, created_at FROM auctions WHERE 1=1`
+	columns := `id, seller_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, hold_threshold, created_at`
 
 	args := []any{}
 
+	// full-text search always binds the query as $1 so the rank, headline and match share it
+	if filter.Query != "" {
+		args = append(args, filter.Query)
+		columns += `, ts_rank(search_vector, websearch_to_tsquery('english', $1)) AS rank` +
+			`, ts_headline('english', title, websearch_to_tsquery('english', $1), '` + searchHeadlineOptions + `')` +
+			`, ts_headline('english', COALESCE(description, ''), websearch_to_tsquery('english', $1), '` + searchHeadlineOptions + `')`
+	}
+
+	// the conditions are shared by the page query and the total count
+	where := ` WHERE 1=1`
+
+	if filter.Query != "" {
+		where += ` AND search_vector @@ websearch_to_tsquery('english', $1)`
+	}
+
 	if filter.Type != "" {
-		query += ` AND type = $` + strconv.Itoa(len(args)+1)
+		where += ` AND type = $` + strconv.Itoa(len(args)+1)
 		args = append(args, filter.Type)
 	}
 	if filter.Status != "" {
-		query += ` AND status = $` + strconv.Itoa(len(args)+1)
+		where += ` AND status = $` + strconv.Itoa(len(args)+1)
 		args = append(args, filter.Status)
 	}
 
 	if filter.Category != "" {
-		query += ` AND category = $` + strconv.Itoa(len(args)+1)
+		where += ` AND category = $` + strconv.Itoa(len(args)+1)
 		args = append(args, filter.Category)
 	}
 
 	if filter.StartingPrice != 0 {
-		query += ` AND starting_price = $` + strconv.Itoa(len(args)+1)
+		where += ` AND starting_price = $` + strconv.Itoa(len(args)+1)
 		args = append(args, filter.StartingPrice)
 	}
 
-	query += ` ORDER BY created_at DESC LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
-	args = append(args, limit, offset)
+	if filter.MinPrice != 0 {
+		where += ` AND current_price >= $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.MinPrice)
+	}
+
+	if filter.MaxPrice != 0 {
+		where += ` AND current_price <= $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.MaxPrice)
+	}
+
+	if filter.Keyword != "" {
+		where += ` AND strpos(lower(title || ' ' || COALESCE(description, '')), lower($` + strconv.Itoa(len(args)+1) + `)) > 0`
+		args = append(args, filter.Keyword)
+	}
+
+	if filter.SellerID != "" {
+		where += ` AND seller_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.SellerID)
+	}
+
+	if filter.EndingWithinHours > 0 {
+		where += ` AND end_time > NOW() AND end_time <= NOW() + make_interval(hours => $` + strconv.Itoa(len(args)+1) + `)`
+		args = append(args, filter.EndingWithinHours)
+	}
+
+	if filter.HasBids != nil {
+		if *filter.HasBids {
+			where += ` AND EXISTS (SELECT 1 FROM bid b WHERE b.auction_id = auctions.id)`
+		} else {
+			where += ` AND NOT EXISTS (SELECT 1 FROM bid b WHERE b.auction_id = auctions.id)`
+		}
+	}
+
+	if filter.IsPaid != nil {
+		where += ` AND is_paid = $` + strconv.Itoa(len(args)+1)
+		args = append(args, *filter.IsPaid)
+	}
+
+	var total int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM auctions`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + columns + ` FROM auctions` + where
+
+	switch {
+	case filter.OrderedByCreation():
+		query, args = createdDesc.page(query, args, page)
+	case filter.Sort != "":
+		clause, ok := auctionSortClauses[filter.Sort]
+		if !ok {
+			return nil, 0, errs.ErrInvalidSortKey
+		}
+		query, args = limitOffset(query+` ORDER BY `+clause, args, page)
+	default:
+		query, args = limitOffset(query+` ORDER BY rank DESC, created_at DESC, id DESC`, args, page)
+	}
 
 	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer rows.Close()
@@ -151,8 +265,20 @@ func (a *AuctionStore) GetAuctions(ctx context.Context, limit, offset int, filte
 	for rows.Next() {
 		var a models.Auction
 
-		if err := rows.Scan(&a.ID, &a.SellerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.CreatedAt); err != nil {
-			return nil, err
+		dest := []any{&a.ID, &a.SellerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.HoldThreshold, &a.CreatedAt}
+
+		if filter.Query != "" {
+			a.Highlight = &models.SearchHighlight{}
+			dest = append(dest, &a.Rank, &a.Highlight.Title, &a.Highlight.Description)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+
+		if a.Highlight != nil {
+			a.Highlight.Title = highlightSnippet(a.Highlight.Title)
+			a.Highlight.Description = highlightSnippet(a.Highlight.Description)
 		}
 
 		auctions = append(auctions, a)
@@ -160,10 +286,10 @@ func (a *AuctionStore) GetAuctions(ctx context.Context, limit, offset int, filte
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return &auctions, nil
+	return &auctions, total, nil
 }
 
 func (a *AuctionStore) CreateAuction(ctx context.Context, auction *models.Auction) (*models.Auction, error) {
@@ -171,7 +297,12 @@ func (a *AuctionStore) CreateAuction(ctx context.Context, auction *models.Auctio
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `INSERT INTO auctions (seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, created_at`
+	query := `INSERT INTO auctions (seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, hold_threshold, shipping_options, weight_kg) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, hold_threshold, weight_kg, created_at`
+
+	shippingOptions, err := marshalShippingOptions(auction.ShippingOptions)
+	if err != nil {
+		return nil, err
+	}
 
 	tx, err := a.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -180,7 +311,7 @@ func (a *AuctionStore) CreateAuction(ctx context.Context, auction *models.Auctio
 
 	defer tx.Rollback()
 
-	if err = tx.QueryRowContext(ctx, query, auction.SellerID, auction.WinnerID, auction.Title, auction.Description, auction.StartingPrice, auction.CurrentPrice, auction.Type, auction.Status, auction.StartTime, auction.EndTime, auction.ImagePath, auction.Category, auction.IsPaid).Scan(&auction.ID, &auction.SellerID, &auction.WinnerID, &auction.Title, &auction.Description, &auction.StartingPrice, &auction.CurrentPrice, &auction.Type, &auction.Status, &auction.StartTime, &auction.EndTime, &auction.ImagePath, &auction.Category, &auction.IsPaid, &auction.CreatedAt); err != nil {
+	if err = tx.QueryRowContext(ctx, query, auction.SellerID, auction.WinnerID, auction.Title, auction.Description, auction.StartingPrice, auction.CurrentPrice, auction.Type, auction.Status, auction.StartTime, auction.EndTime, auction.ImagePath, auction.Category, auction.IsPaid, auction.HoldThreshold, shippingOptions, auction.WeightKg).Scan(&auction.ID, &auction.SellerID, &auction.WinnerID, &auction.Title, &auction.Description, &auction.StartingPrice, &auction.CurrentPrice, &auction.Type, &auction.Status, &auction.StartTime, &auction.EndTime, &auction.ImagePath, &auction.Category, &auction.IsPaid, &auction.HoldThreshold, &auction.WeightKg, &auction.CreatedAt); err != nil {
 		return nil, err
 	}
 
@@ -195,7 +326,12 @@ func (a *AuctionStore) UpdateAuction(ctx context.Context, auction *models.Auctio
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `UPDATE auctions SET seller_id = $1, title = $2, description = $3, starting_price = $4, current_price = $5, type = $6, status = $7, start_time = $8, end_time = $9, winner_id = $10 WHERE id = $11`
+	query := `UPDATE auctions SET seller_id = $1, title = $2, description = $3, starting_price = $4, current_price = $5, type = $6, status = $7, start_time = $8, end_time = $9, winner_id = $10, hold_threshold = $11, shipping_options = $12, weight_kg = $13 WHERE id = $14`
+
+	shippingOptions, err := marshalShippingOptions(auction.ShippingOptions)
+	if err != nil {
+		return err
+	}
 
 	tx, err := a.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -204,7 +340,7 @@ func (a *AuctionStore) UpdateAuction(ctx context.Context, auction *models.Auctio
 
 	defer tx.Rollback()
 
-	if _, err = tx.ExecContext(ctx, query, auction.SellerID, auction.Title, auction.Description, auction.StartingPrice, auction.CurrentPrice, auction.Type, auction.Status, auction.StartTime, auction.EndTime, auction.WinnerID, id); err != nil {
+	if _, err = tx.ExecContext(ctx, query, auction.SellerID, auction.Title, auction.Description, auction.StartingPrice, auction.CurrentPrice, auction.Type, auction.Status, auction.StartTime, auction.EndTime, auction.WinnerID, auction.HoldThreshold, shippingOptions, auction.WeightKg, id); err != nil {
 		return err
 	}
 
@@ -215,6 +351,17 @@ func (a *AuctionStore) UpdateAuction(ctx context.Context, auction *models.Auctio
 	return nil
 }
 
+// marshalShippingOptions stores an auction without shipping options as an empty list
+func marshalShippingOptions(options []models.ShippingOption) ([]byte, error) {
+	if options == nil {
+		options = []models.ShippingOption{}
+	}
+	return json.Marshal(options)
+}
+
+// DeleteAuction deletes an auction and, through cascading keys, what hangs off
+// it. Invoices are kept for the books, so an auction that was invoiced returns
+// errs.ErrAuctionHasInvoice instead.
 func (a *AuctionStore) DeleteAuction(ctx context.Context, id string) error {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
@@ -229,7 +376,20 @@ func (a *AuctionStore) DeleteAuction(ctx context.Context, id string) error {
 
 	defer tx.Rollback()
 
+	var invoiced bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM invoice WHERE auction_id = $1)`, id).Scan(&invoiced); err != nil {
+		return err
+	}
+	if invoiced {
+		return errs.ErrAuctionHasInvoice
+	}
+
 	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			// an invoice was issued after the check above
+			return errs.ErrAuctionHasInvoice
+		}
 		return err
 	}
 
@@ -240,17 +400,22 @@ func (a *AuctionStore) DeleteAuction(ctx context.Context, id string) error {
 	return nil
 }
 
-func (a *AuctionStore) GetWonAuctionsByWinnerID(ctx context.Context, winnerID string) (*[]models.Auction, error) {
+func (a *AuctionStore) GetWonAuctionsByWinnerID(ctx context.Context, winnerID string, page *pagination.Params) (*[]models.Auction, int, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, is_paid, created_at FROM auctions WHERE winner_id = $1 AND status = 'closed';`
+	var total int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM auctions WHERE winner_id = $1 AND status = 'closed'`, winnerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT id, seller_id, winner_id, title, description, starting_price, current_price, type, status, start_time, end_time, image_path, category, is_paid, created_at FROM auctions WHERE winner_id = $1 AND status = 'closed'`, []any{winnerID}, page)
 
-	rows, err := a.db.QueryContext(ctx, query, winnerID)
+	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("Failed to get won auctions: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer rows.Close()
@@ -259,52 +424,35 @@ func (a *AuctionStore) GetWonAuctionsByWinnerID(ctx context.Context, winnerID st
 
 	for rows.Next() {
 		var a models.Auction
-		err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.IsPaid, &a.CreatedAt)
+		err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.CreatedAt)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		auctions = append(auctions, a)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 
 	}
 
-	return &auctions, nil
+	return &auctions, total, nil
 }
 
-func (a *AuctionStore) UpdateAuctionPaymentStatus(ctx context.Context, isPaid bool, id string) error {
+func (a *AuctionStore) GetBiddedAuctions(ctx context.Context, bidderID string, page *pagination.Params) (*[]models.Auction, int, error) {
+
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `UPDATE auctions SET is_paid = $1 WHERE id = $2`
-
-	tx, err := a.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	defer tx.Rollback()
+	where := ` WHERE EXISTS (SELECT 1 FROM bid b WHERE b.auction_id = a.id AND b.bidder_id = $1)`
 
-	if _, err = tx.ExecContext(ctx, query, isPaid, id); err != nil {
-		return err
+	var total int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM auctions a`+where, bidderID).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (a *AuctionStore) GetBiddedAuctions(ctx context.Context, bidderID string) (*[]models.Auction, error) {
-
-	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
-	defer cancel()
-
-	query := `SELECT DISTINCT 
+	query, args := keyset{at: "a.created_at", id: "a.id"}.page(`SELECT 
   a.id,
   a.seller_id,
   a.winner_id,
@@ -320,14 +468,12 @@ func (a *AuctionStore) GetBiddedAuctions(ctx context.Context, bidderID string) (
   a.category,
   a.is_paid,
   a.created_at
-FROM auctions a
-JOIN bid b ON a.id = b.auction_id
-WHERE b.bidder_id = $1;`
+FROM auctions a`+where, []any{bidderID}, page)
 
-	rows, err := a.db.QueryContext(ctx, query, bidderID)
+	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("SQL query error: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer rows.Close()
@@ -339,17 +485,17 @@ WHERE b.bidder_id = $1;`
 		err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.CreatedAt)
 		if err != nil {
 			log.Printf("SQL query error: %v", err)
-			return nil, err
+			return nil, 0, err
 		}
 
 		auctions = append(auctions, a)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 
 	}
 
-	return &auctions, nil
+	return &auctions, total, nil
 
 }
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightSnippetEscapesSellerText(t *testing.T) {
+
+	snippet := "<b>Vintage</b> " + headlineStart + "lamp" + headlineStop + " <img src=x onerror=alert(1)>"
+
+	assert.Equal(t, "&lt;b&gt;Vintage&lt;/b&gt; <mark>lamp</mark> &lt;img src=x onerror=alert(1)&gt;", highlightSnippet(snippet))
+}
+
+func TestAuctionSortClauses(t *testing.T) {
+	tests := []struct {
+		sort   string
+		clause string
+	}{
+		{models.AuctionSortEndingSoon, `CASE WHEN end_time > NOW() THEN 0 ELSE 1 END, end_time ASC, id ASC`},
+		{models.AuctionSortPriceAsc, `current_price ASC, created_at DESC, id DESC`},
+		{models.AuctionSortPriceDesc, `current_price DESC, created_at DESC, id DESC`},
+		{models.AuctionSortMostBids, `(SELECT COUNT(*) FROM bid b WHERE b.auction_id = auctions.id) DESC, created_at DESC, id DESC`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			assert.True(t, models.IsValidAuctionSort(tt.sort))
+			assert.Equal(t, tt.clause, auctionSortClauses[tt.sort])
+		})
+	}
+
+	assert.Len(t, auctionSortClauses, len(tests), "Expected a clause only for the keys above")
+	assert.NotContains(t, auctionSortClauses, models.AuctionSortNewest, "Expected newest to be paged by keyset")
+	assert.NotContains(t, auctionSortClauses, "bogus")
+}
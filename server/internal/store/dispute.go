@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type DisputeStore struct {
+	db *sql.DB
+}
+
+const disputeColumns = `id, order_id, auction_id, buyer_id, seller_id, reason, description, status, deadline, return_carrier, return_tracking_number,
+	refund_amount, resolution_note, resolved_by, resolved_at, created_at, updated_at`
+
+func scanDispute(row interface{ Scan(dest ...any) error }, d *models.Dispute) error {
+	return row.Scan(&d.ID, &d.OrderID, &d.AuctionID, &d.BuyerID, &d.SellerID, &d.Reason, &d.Description, &d.Status, &d.Deadline, &d.ReturnCarrier, &d.ReturnTrackingNumber,
+		&d.RefundAmount, &d.ResolutionNote, &d.ResolvedBy, &d.ResolvedAt, &d.CreatedAt, &d.UpdatedAt)
+}
+
+const disputeMessageColumns = `id, dispute_id, sender_id, sender_role, body, image_path, created_at`
+
+func scanDisputeMessage(row interface{ Scan(dest ...any) error }, m *models.DisputeMessage) error {
+	return row.Scan(&m.ID, &m.DisputeID, &m.SenderID, &m.SenderRole, &m.Body, &m.ImagePath, &m.CreatedAt)
+}
+
+// CreateDispute opens a dispute and marks its order disputed in the same
+// transaction. The order must currently be in one of the orderFrom statuses;
+// an order is disputed at most once.
+func (d *DisputeStore) CreateDispute(ctx context.Context, dispute *models.Dispute, orderFrom []string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, dispute.OrderID).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrOrderNotFound
+		}
+		return err
+	}
+
+	if !slices.Contains(orderFrom, current) {
+		return fmt.Errorf("%w: %s -> disputed", errs.ErrIllegalOrderTransition, current)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET status = 'disputed', disputed_at = CURRENT_TIMESTAMP, status_changed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, dispute.OrderID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO disputes (order_id, auction_id, buyer_id, seller_id, reason, description, status, deadline)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + disputeColumns
+
+	if err := scanDispute(tx.QueryRowContext(ctx, query, dispute.OrderID, dispute.AuctionID, dispute.BuyerID, dispute.SellerID, dispute.Reason, dispute.Description, dispute.Status, dispute.Deadline), dispute); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *DisputeStore) GetDispute(ctx context.Context, id string) (*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	dispute := &models.Dispute{}
+	if err := scanDispute(d.db.QueryRowContext(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = $1`, id), dispute); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrDisputeNotFound
+		}
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// GetDisputes returns the disputes a user opened as buyer or received as seller
+func (d *DisputeStore) GetDisputes(ctx context.Context, userID string, page *pagination.Params) ([]*models.Dispute, int, error) {
+	return d.getDisputes(ctx, `buyer_id = $1 OR seller_id = $1`, []any{userID}, page)
+}
+
+// GetDisputesByStatus returns the disputes in a status, or all disputes when
+// status is empty
+func (d *DisputeStore) GetDisputesByStatus(ctx context.Context, status string, page *pagination.Params) ([]*models.Dispute, int, error) {
+	return d.getDisputes(ctx, `$1 = '' OR status = $1`, []any{status}, page)
+}
+
+func (d *DisputeStore) getDisputes(ctx context.Context, where string, args []any, page *pagination.Params) ([]*models.Dispute, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM disputes WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+disputeColumns+` FROM disputes WHERE (`+where+`)`, args, page)
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	disputes := []*models.Dispute{}
+	for rows.Next() {
+		dispute := &models.Dispute{}
+		if err := scanDispute(rows, dispute); err != nil {
+			return nil, 0, err
+		}
+		disputes = append(disputes, dispute)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return disputes, total, nil
+}
+
+// TransitionDispute moves a dispute to dispute.Status if it is currently in
+// one of the from statuses, storing its deadline, return shipment, refund and
+// resolution alongside. A non-empty orderStatus settles the dispute's order in
+// the same transaction. dispute is updated to the stored row.
+func (d *DisputeStore) TransitionDispute(ctx context.Context, dispute *models.Dispute, from []string, orderStatus string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM disputes WHERE id = $1 FOR UPDATE`, dispute.ID).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrDisputeNotFound
+		}
+		return err
+	}
+
+	if !slices.Contains(from, current) {
+		return fmt.Errorf("%w: %s -> %s", errs.ErrIllegalDisputeTransition, current, dispute.Status)
+	}
+
+	query := `UPDATE disputes
+		SET status = $1,
+			deadline = $2,
+			return_carrier = $3,
+			return_tracking_number = $4,
+			refund_amount = $5,
+			resolution_note = $6,
+			resolved_by = $7,
+			resolved_at = CASE WHEN $1 IN ('refunded', 'rejected') THEN CURRENT_TIMESTAMP ELSE resolved_at END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING ` + disputeColumns
+
+	if err := scanDispute(tx.QueryRowContext(ctx, query, dispute.Status, dispute.Deadline, dispute.ReturnCarrier, dispute.ReturnTrackingNumber,
+		dispute.RefundAmount, dispute.ResolutionNote, dispute.ResolvedBy, dispute.ID), dispute); err != nil {
+		return err
+	}
+
+	if orderStatus != "" {
+		query := `UPDATE orders
+			SET status = $1,
+				completed_at = CASE WHEN $1 = 'completed' THEN CURRENT_TIMESTAMP ELSE completed_at END,
+				status_changed_at = CURRENT_TIMESTAMP,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 AND status = 'disputed'`
+
+		if _, err := tx.ExecContext(ctx, query, orderStatus, dispute.OrderID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetOverdueDisputes returns the disputes waiting on a buyer or seller whose
+// deadline has passed, oldest deadline first
+func (d *DisputeStore) GetOverdueDisputes(ctx context.Context) ([]*models.Dispute, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + disputeColumns + ` FROM disputes
+		WHERE status IN ('open', 'awaiting_return', 'return_shipped') AND deadline < CURRENT_TIMESTAMP
+		ORDER BY deadline LIMIT 100`
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	disputes := []*models.Dispute{}
+	for rows.Next() {
+		dispute := &models.Dispute{}
+		if err := scanDispute(rows, dispute); err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+
+	return disputes, rows.Err()
+}
+
+func (d *DisputeStore) CreateDisputeMessage(ctx context.Context, message *models.DisputeMessage) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO dispute_message (dispute_id, sender_id, sender_role, body, image_path)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + disputeMessageColumns
+
+	return scanDisputeMessage(d.db.QueryRowContext(ctx, query, message.DisputeID, message.SenderID, message.SenderRole, message.Body, message.ImagePath), message)
+}
+
+// GetDisputeMessages returns the thread of a dispute, oldest first
+func (d *DisputeStore) GetDisputeMessages(ctx context.Context, disputeID string, page *pagination.Params) ([]*models.DisputeMessage, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM dispute_message WHERE dispute_id = $1`, disputeID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := keyset{at: "created_at", id: "id", asc: true}.page(`SELECT `+disputeMessageColumns+` FROM dispute_message WHERE dispute_id = $1`, []any{disputeID}, page)
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	messages := []*models.DisputeMessage{}
+	for rows.Next() {
+		message := &models.DisputeMessage{}
+		if err := scanDisputeMessage(rows, message); err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
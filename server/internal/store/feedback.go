@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type FeedbackStore struct {
+	db *sql.DB
+}
+
+const feedbackColumns = `id, order_id, auction_id, from_user_id, to_user_id, role, rating, comment, reply, replied_at, created_at, updated_at`
+
+func scanFeedback(row interface{ Scan(dest ...any) error }, f *models.Feedback) error {
+	return row.Scan(&f.ID, &f.OrderID, &f.AuctionID, &f.FromUserID, &f.ToUserID, &f.Role, &f.Rating, &f.Comment, &f.Reply, &f.RepliedAt, &f.CreatedAt, &f.UpdatedAt)
+}
+
+// CreateFeedback records a rating. A user rates the other party of an order
+// once; a second rating returns errs.ErrFeedbackAlreadyLeft.
+func (f *FeedbackStore) CreateFeedback(ctx context.Context, feedback *models.Feedback) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO feedback (order_id, auction_id, from_user_id, to_user_id, role, rating, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (order_id, from_user_id) DO NOTHING
+		RETURNING ` + feedbackColumns
+
+	if err := scanFeedback(f.db.QueryRowContext(ctx, query, feedback.OrderID, feedback.AuctionID, feedback.FromUserID, feedback.ToUserID, feedback.Role, feedback.Rating, feedback.Comment), feedback); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrFeedbackAlreadyLeft
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (f *FeedbackStore) GetFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	feedback := &models.Feedback{}
+	if err := scanFeedback(f.db.QueryRowContext(ctx, `SELECT `+feedbackColumns+` FROM feedback WHERE id = $1`, id), feedback); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrFeedbackNotFound
+		}
+		return nil, err
+	}
+
+	return feedback, nil
+}
+
+// UpdateFeedback changes the rating and comment of a feedback
+func (f *FeedbackStore) UpdateFeedback(ctx context.Context, feedback *models.Feedback) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE feedback SET rating = $1, comment = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING ` + feedbackColumns
+
+	if err := scanFeedback(f.db.QueryRowContext(ctx, query, feedback.Rating, feedback.Comment, feedback.ID), feedback); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrFeedbackNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ReplyToFeedback stores the rated user's reply. A feedback is replied to
+// once; replying again returns errs.ErrFeedbackAlreadyReplied.
+func (f *FeedbackStore) ReplyToFeedback(ctx context.Context, feedback *models.Feedback) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE feedback SET reply = $1, replied_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND replied_at IS NULL
+		RETURNING ` + feedbackColumns
+
+	if err := scanFeedback(f.db.QueryRowContext(ctx, query, feedback.Reply, feedback.ID), feedback); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrFeedbackAlreadyReplied
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetReceivedFeedback returns the feedback a user received in a role
+func (f *FeedbackStore) GetReceivedFeedback(ctx context.Context, userID, role string, page *pagination.Params) ([]*models.Feedback, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := f.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feedback WHERE to_user_id = $1 AND role = $2`, userID, role).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+feedbackColumns+` FROM feedback WHERE to_user_id = $1 AND role = $2`, []any{userID, role}, page)
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	feedback := []*models.Feedback{}
+	for rows.Next() {
+		entry := &models.Feedback{}
+		if err := scanFeedback(rows, entry); err != nil {
+			return nil, 0, err
+		}
+		feedback = append(feedback, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return feedback, total, nil
+}
+
+// GetFeedbackScores tallies the ratings a user received as a seller and as a buyer
+func (f *FeedbackStore) GetFeedbackScores(ctx context.Context, userID string) (*models.UserFeedback, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	rows, err := f.db.QueryContext(ctx, `SELECT role, rating, COUNT(*) FROM feedback WHERE to_user_id = $1 GROUP BY role, rating`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	scores := &models.UserFeedback{}
+	for rows.Next() {
+		var role, rating string
+		var count int
+		if err := rows.Scan(&role, &rating, &count); err != nil {
+			return nil, err
+		}
+
+		score := &scores.AsBuyer
+		if role == models.FeedbackRoleSeller {
+			score = &scores.AsSeller
+		}
+
+		switch rating {
+		case models.FeedbackPositive:
+			score.Positive = count
+		case models.FeedbackNeutral:
+			score.Neutral = count
+		case models.FeedbackNegative:
+			score.Negative = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, score := range []*models.FeedbackScore{&scores.AsSeller, &scores.AsBuyer} {
+		score.Score = score.Positive - score.Negative
+		if rated := score.Positive + score.Negative; rated > 0 {
+			score.PositivePercent = math.Round(float64(score.Positive)/float64(rated)*1000) / 10
+		}
+	}
+
+	return scores, nil
+}
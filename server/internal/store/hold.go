@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type HoldStore struct {
+	db *sql.DB
+}
+
+const holdColumns = `id, auction_id, bidder_id, amount, provider, provider_hold_id, status, authorized_at, created_at, updated_at`
+
+func scanHold(row interface{ Scan(dest ...any) error }, h *models.BidHold) error {
+	return row.Scan(&h.ID, &h.AuctionID, &h.BidderID, &h.Amount, &h.Provider, &h.ProviderHoldID, &h.Status, &h.AuthorizedAt, &h.CreatedAt, &h.UpdatedAt)
+}
+
+func (h *HoldStore) CreateHold(ctx context.Context, hold *models.BidHold) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO bid_hold (id, auction_id, bidder_id, amount, provider, provider_hold_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + holdColumns
+
+	return scanHold(h.db.QueryRowContext(ctx, query, hold.ID, hold.AuctionID, hold.BidderID, hold.Amount, hold.Provider, hold.ProviderHoldID, hold.Status), hold)
+}
+
+// GetLiveHold returns the bidder's hold on an auction that was not released
+func (h *HoldStore) GetLiveHold(ctx context.Context, auctionID, bidderID string) (*models.BidHold, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	hold := &models.BidHold{}
+	if err := scanHold(h.db.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM bid_hold WHERE auction_id = $1 AND bidder_id = $2 AND status <> 'released'`, auctionID, bidderID), hold); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrBidHoldNotFound
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// GetLiveHolds returns every hold on an auction that was not released
+func (h *HoldStore) GetLiveHolds(ctx context.Context, auctionID string) ([]*models.BidHold, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	rows, err := h.db.QueryContext(ctx, `SELECT `+holdColumns+` FROM bid_hold WHERE auction_id = $1 AND status <> 'released' ORDER BY created_at`, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []*models.BidHold{}
+	for rows.Next() {
+		hold := &models.BidHold{}
+		if err := scanHold(rows, hold); err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// UpdateHoldStatus records the status of a hold at the provider. The time it
+// was first authorized is kept, since the authorization expires from then.
+func (h *HoldStore) UpdateHoldStatus(ctx context.Context, hold *models.BidHold) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE bid_hold
+		SET status = $1,
+			authorized_at = CASE WHEN $1 = 'authorized' THEN COALESCE(authorized_at, CURRENT_TIMESTAMP) ELSE authorized_at END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING ` + holdColumns
+
+	if err := scanHold(h.db.QueryRowContext(ctx, query, hold.Status, hold.ID), hold); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrBidHoldNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type InvoiceStore struct {
+	db *sql.DB
+}
+
+const invoiceColumns = `id, number, payment_id, auction_id, order_id, buyer_id, buyer_name, buyer_email, seller_id, seller_name, seller_email,
+	auction_title, currency, lines, subtotal, fees, shipping, tax, total, payment_reference, issued_at`
+
+func scanInvoice(row interface{ Scan(dest ...any) error }, i *models.Invoice) error {
+	var lines []byte
+	if err := row.Scan(&i.ID, &i.Number, &i.PaymentID, &i.AuctionID, &i.OrderID, &i.BuyerID, &i.BuyerName, &i.BuyerEmail, &i.SellerID, &i.SellerName, &i.SellerEmail,
+		&i.AuctionTitle, &i.Currency, &lines, &i.Subtotal, &i.Fees, &i.Shipping, &i.Tax, &i.Total, &i.PaymentReference, &i.IssuedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(lines, &i.Lines)
+}
+
+// CreateInvoice issues the invoice of a payment, numbered next in the year it
+// is issued. The counter is incremented in the same transaction as the insert,
+// so a failed insert doesn't use up a number. A payment gets one invoice: if it
+// already has one, that invoice is returned instead.
+func (i *InvoiceStore) CreateInvoice(ctx context.Context, invoice *models.Invoice) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	lines, err := json.Marshal(invoice.Lines)
+	if err != nil {
+		return err
+	}
+
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// serializes invoicing of the same payment
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM payment WHERE id = $1 FOR UPDATE`, invoice.PaymentID); err != nil {
+		return err
+	}
+
+	err = scanInvoice(tx.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoice WHERE payment_id = $1`, invoice.PaymentID), invoice)
+	if err == nil {
+		return tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	var year, sequence int
+	counter := `INSERT INTO invoice_counter (year, last_number) VALUES (EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::INTEGER, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_counter.last_number + 1
+		RETURNING year, last_number`
+	if err := tx.QueryRowContext(ctx, counter).Scan(&year, &sequence); err != nil {
+		return err
+	}
+
+	invoice.Number = fmt.Sprintf("INV-%d-%06d", year, sequence)
+
+	insert := `INSERT INTO invoice (number, year, sequence, payment_id, auction_id, order_id, buyer_id, buyer_name, buyer_email, seller_id, seller_name, seller_email,
+			auction_title, currency, lines, subtotal, fees, shipping, tax, total, payment_reference)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING ` + invoiceColumns
+
+	if err := scanInvoice(tx.QueryRowContext(ctx, insert, invoice.Number, year, sequence, invoice.PaymentID, invoice.AuctionID, invoice.OrderID,
+		invoice.BuyerID, invoice.BuyerName, invoice.BuyerEmail, invoice.SellerID, invoice.SellerName, invoice.SellerEmail,
+		invoice.AuctionTitle, invoice.Currency, lines, invoice.Subtotal, invoice.Fees, invoice.Shipping, invoice.Tax, invoice.Total, invoice.PaymentReference), invoice); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *InvoiceStore) GetInvoiceByPaymentID(ctx context.Context, paymentID string) (*models.Invoice, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	invoice := &models.Invoice{}
+	if err := scanInvoice(i.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoice WHERE payment_id = $1`, paymentID), invoice); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type LedgerStore struct {
+	db *sql.DB
+}
+
+const (
+	ledgerEntryColumns = `id, seller_id, kind, amount, gross, platform_fee, status, payment_id, auction_id, available_at, created_at`
+	payoutColumns      = `id, seller_id, amount, status, provider, provider_payout_id, error, created_at, completed_at`
+)
+
+func scanLedgerEntry(row interface{ Scan(dest ...any) error }, e *models.LedgerEntry) error {
+	return row.Scan(&e.ID, &e.SellerID, &e.Kind, &e.Amount, &e.Gross, &e.PlatformFee, &e.Status, &e.PaymentID, &e.AuctionID, &e.AvailableAt, &e.CreatedAt)
+}
+
+func scanPayout(row interface{ Scan(dest ...any) error }, p *models.Payout) error {
+	return row.Scan(&p.ID, &p.SellerID, &p.Amount, &p.Status, &p.Provider, &p.ProviderPayoutID, &p.Error, &p.CreatedAt, &p.CompletedAt)
+}
+
+// CreditSale records the seller's share of a completed payment as held funds.
+// It returns false if the payment was already credited.
+func (l *LedgerStore) CreditSale(ctx context.Context, entry *models.LedgerEntry) (bool, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO ledger_entry (seller_id, kind, amount, gross, platform_fee, status, payment_id, auction_id, available_at)
+		VALUES ($1, 'sale', $2, $3, $4, 'held', $5, $6, $7)
+		ON CONFLICT (payment_id) WHERE kind = 'sale' DO NOTHING
+		RETURNING ` + ledgerEntryColumns
+
+	if err := scanLedgerEntry(l.db.QueryRowContext(ctx, query, entry.SellerID, entry.Amount, entry.Gross, entry.PlatformFee, entry.PaymentID, entry.AuctionID, entry.AvailableAt), entry); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DebitRefund takes the seller's share of a refund back out of the sale it
+// refunds: the same fraction of the credited amount as the refund is of
+// everything the buyer paid (deposit included), never more than what is left
+// of the sale. Once the payment is fully refunded whatever is left of the sale
+// is debited, so rounding can't leave the seller a cent. The debit is held or
+// available like the sale itself. Each refund is debited once.
+func (l *LedgerStore) DebitRefund(ctx context.Context, paymentID, refundID string, refundAmount float64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO ledger_entry (seller_id, kind, amount, status, payment_id, auction_id, refund_id, available_at)
+		SELECT s.seller_id, 'refund',
+			-CASE WHEN p.status = 'refunded' THEN left_over.amount ELSE LEAST(ROUND(s.amount * $2 / (p.amount + p.deposit), 2), left_over.amount) END,
+			s.status, s.payment_id, s.auction_id, $3, s.available_at
+		FROM ledger_entry s
+		JOIN payment p ON p.id = s.payment_id
+		CROSS JOIN LATERAL (
+			SELECT s.amount + COALESCE(SUM(r.amount), 0) AS amount FROM ledger_entry r WHERE r.payment_id = s.payment_id AND r.kind = 'refund'
+		) left_over
+		WHERE s.payment_id = $1 AND s.kind = 'sale'
+		ON CONFLICT (refund_id) WHERE refund_id IS NOT NULL DO NOTHING`
+
+	_, err := l.db.ExecContext(ctx, query, paymentID, refundAmount, refundID)
+	return err
+}
+
+// ReleasePayment makes the held funds of a payment available, e.g. once its
+// order is completed. It returns the number of entries released.
+func (l *LedgerStore) ReleasePayment(ctx context.Context, paymentID string) (int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	res, err := l.db.ExecContext(ctx, `UPDATE ledger_entry SET status = 'available', available_at = LEAST(available_at, CURRENT_TIMESTAMP) WHERE payment_id = $1 AND status = 'held'`, paymentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ReleaseDue makes held entries available and returns the released sale
+// entries: those of a completed order, and those without an order whose hold
+// period has passed. Funds of any other order stay held.
+func (l *LedgerStore) ReleaseDue(ctx context.Context) ([]*models.LedgerEntry, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE ledger_entry SET status = 'available'
+		WHERE status = 'held' AND (
+			EXISTS (SELECT 1 FROM orders o WHERE o.payment_id = ledger_entry.payment_id AND o.status = 'completed')
+			OR (available_at <= CURRENT_TIMESTAMP AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.payment_id = ledger_entry.payment_id))
+		)
+		RETURNING ` + ledgerEntryColumns
+
+	rows, err := l.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	released := []*models.LedgerEntry{}
+	for rows.Next() {
+		entry := &models.LedgerEntry{}
+		if err := scanLedgerEntry(rows, entry); err != nil {
+			return nil, err
+		}
+		if entry.Kind == models.LedgerSale {
+			released = append(released, entry)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return released, nil
+}
+
+func (l *LedgerStore) GetBalance(ctx context.Context, sellerID string) (*models.Balance, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT
+		COALESCE((SELECT SUM(amount) FROM ledger_entry WHERE seller_id = $1 AND status = 'held'), 0),
+		COALESCE((SELECT SUM(amount) FROM ledger_entry WHERE seller_id = $1 AND status = 'available'), 0),
+		COALESCE((SELECT SUM(amount) FROM payout WHERE seller_id = $1 AND status = 'paid'), 0)`
+
+	balance := &models.Balance{}
+	if err := l.db.QueryRowContext(ctx, query, sellerID).Scan(&balance.Held, &balance.Available, &balance.PaidOut); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// CreatePayout starts a payout of the seller's whole available balance and
+// debits it from the ledger. Payouts of the same seller are serialized, so the
+// balance can't be paid out twice.
+func (l *LedgerStore) CreatePayout(ctx context.Context, sellerID, provider string) (*models.Payout, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('payout:' || $1))`, sellerID); err != nil {
+		return nil, err
+	}
+
+	var available float64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM ledger_entry WHERE seller_id = $1 AND status = 'available'`, sellerID).Scan(&available); err != nil {
+		return nil, err
+	}
+
+	if available <= 0 {
+		return nil, errs.ErrNothingToPayOut
+	}
+
+	payout := &models.Payout{}
+	insert := `INSERT INTO payout (seller_id, amount, provider) VALUES ($1, $2, $3) RETURNING ` + payoutColumns
+	if err := scanPayout(tx.QueryRowContext(ctx, insert, sellerID, available, provider), payout); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ledger_entry (seller_id, kind, amount, status, payout_id) VALUES ($1, 'payout', $2, 'available', $3)`, sellerID, -available, payout.ID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+func (l *LedgerStore) CompletePayout(ctx context.Context, payout *models.Payout) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE payout SET status = 'paid', provider_payout_id = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'pending' RETURNING status, completed_at`
+
+	return l.db.QueryRowContext(ctx, query, payout.ProviderPayoutID, payout.ID).Scan(&payout.Status, &payout.CompletedAt)
+}
+
+// FailPayout marks a pending payout failed and credits its amount back
+func (l *LedgerStore) FailPayout(ctx context.Context, payout *models.Payout) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `UPDATE payout SET status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'pending' RETURNING status, completed_at`
+	if err := tx.QueryRowContext(ctx, query, payout.Error, payout.ID).Scan(&payout.Status, &payout.CompletedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ledger_entry (seller_id, kind, amount, status, payout_id) VALUES ($1, 'payout_reversal', $2, 'available', $3)`, payout.SellerID, payout.Amount, payout.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (l *LedgerStore) GetPayouts(ctx context.Context, sellerID string, page *pagination.Params) ([]*models.Payout, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := l.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM payout WHERE seller_id = $1`, sellerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+payoutColumns+` FROM payout WHERE seller_id = $1`, []any{sellerID}, page)
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	payouts := []*models.Payout{}
+	for rows.Next() {
+		payout := &models.Payout{}
+		if err := scanPayout(rows, payout); err != nil {
+			return nil, 0, err
+		}
+		payouts = append(payouts, payout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return payouts, total, nil
+}
@@ -0,0 +1,66 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.AuctionRepository = (*MockAuctionStore)(nil)
+
+type MockAuctionStore struct {
+	mock.Mock
+}
+
+func (a *MockAuctionStore) GetAuctionById(ctx context.Context, id string) (*models.Auction, error) {
+	ret := a.Called(ctx, id)
+	return ret.Get(0).(*models.Auction), ret.Error(1)
+}
+
+func (a *MockAuctionStore) GetAuctions(ctx context.Context, page *pagination.Params, filter *models.AuctionFilter) (*[]models.Auction, int, error) {
+	ret := a.Called(ctx, page, filter)
+	return ret.Get(0).(*[]models.Auction), ret.Int(1), ret.Error(2)
+}
+
+func (a *MockAuctionStore) CreateAuction(ctx context.Context, auction *models.Auction) (*models.Auction, error) {
+	ret := a.Called(ctx, auction)
+	return ret.Get(0).(*models.Auction), ret.Error(1)
+}
+
+func (a *MockAuctionStore) CloseAuction(ctx context.Context, status, id string) error {
+	ret := a.Called(ctx, status, id)
+	return ret.Error(0)
+}
+
+func (a *MockAuctionStore) UpdateAuction(ctx context.Context, auction *models.Auction, id string) error {
+	ret := a.Called(ctx, auction, id)
+	return ret.Error(0)
+}
+
+func (a *MockAuctionStore) DeleteAuction(ctx context.Context, id string) error {
+	ret := a.Called(ctx, id)
+	return ret.Error(0)
+}
+
+func (a *MockAuctionStore) GetWonAuctionsByWinnerID(ctx context.Context, winnerID string, page *pagination.Params) (*[]models.Auction, int, error) {
+	ret := a.Called(ctx, winnerID, page)
+	return ret.Get(0).(*[]models.Auction), ret.Int(1), ret.Error(2)
+}
+
+func (a *MockAuctionStore) GetBiddedAuctions(ctx context.Context, bidderID string, page *pagination.Params) (*[]models.Auction, int, error) {
+	ret := a.Called(ctx, bidderID, page)
+	return ret.Get(0).(*[]models.Auction), ret.Int(1), ret.Error(2)
+}
+
+func (a *MockAuctionStore) GetAuctionByWinnerId(ctx context.Context, winnerID string) (*models.Auction, error) {
+	ret := a.Called(ctx, winnerID)
+	return ret.Get(0).(*models.Auction), ret.Error(1)
+}
+
+func (a *MockAuctionStore) GetAuctionBySellerId(ctx context.Context, sellerID string, page *pagination.Params) (*[]models.Auction, int, error) {
+	ret := a.Called(ctx, sellerID, page)
+	return ret.Get(0).(*[]models.Auction), ret.Int(1), ret.Error(2)
+}
@@ -0,0 +1,61 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.LedgerRepository = (*MockLedgerStore)(nil)
+
+type MockLedgerStore struct {
+	mock.Mock
+}
+
+func (l *MockLedgerStore) CreditSale(ctx context.Context, entry *models.LedgerEntry) (bool, error) {
+	ret := l.Called(ctx, entry)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (l *MockLedgerStore) DebitRefund(ctx context.Context, paymentID, refundID string, refundAmount float64) error {
+	ret := l.Called(ctx, paymentID, refundID, refundAmount)
+	return ret.Error(0)
+}
+
+func (l *MockLedgerStore) ReleasePayment(ctx context.Context, paymentID string) (int64, error) {
+	ret := l.Called(ctx, paymentID)
+	return ret.Get(0).(int64), ret.Error(1)
+}
+
+func (l *MockLedgerStore) ReleaseDue(ctx context.Context) ([]*models.LedgerEntry, error) {
+	ret := l.Called(ctx)
+	return ret.Get(0).([]*models.LedgerEntry), ret.Error(1)
+}
+
+func (l *MockLedgerStore) GetBalance(ctx context.Context, sellerID string) (*models.Balance, error) {
+	ret := l.Called(ctx, sellerID)
+	return ret.Get(0).(*models.Balance), ret.Error(1)
+}
+
+func (l *MockLedgerStore) CreatePayout(ctx context.Context, sellerID, provider string) (*models.Payout, error) {
+	ret := l.Called(ctx, sellerID, provider)
+	return ret.Get(0).(*models.Payout), ret.Error(1)
+}
+
+func (l *MockLedgerStore) CompletePayout(ctx context.Context, payout *models.Payout) error {
+	ret := l.Called(ctx, payout)
+	return ret.Error(0)
+}
+
+func (l *MockLedgerStore) FailPayout(ctx context.Context, payout *models.Payout) error {
+	ret := l.Called(ctx, payout)
+	return ret.Error(0)
+}
+
+func (l *MockLedgerStore) GetPayouts(ctx context.Context, sellerID string, page *pagination.Params) ([]*models.Payout, int, error) {
+	ret := l.Called(ctx, sellerID, page)
+	return ret.Get(0).([]*models.Payout), ret.Int(1), ret.Error(2)
+}
@@ -0,0 +1,29 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.NotificationRepository = (*MockNotificationStore)(nil)
+
+type MockNotificationStore struct {
+	mock.Mock
+}
+
+func (n *MockNotificationStore) CreateNotification(ctx context.Context, notification *store.Notification) error {
+	ret := n.Called(ctx, notification)
+	return ret.Error(0)
+}
+
+func (n *MockNotificationStore) GetNotifications(ctx context.Context, userID string) ([]*store.Notification, error) {
+	ret := n.Called(ctx, userID)
+	return ret.Get(0).([]*store.Notification), ret.Error(1)
+}
+
+func (n *MockNotificationStore) DeleteNotificationByAuction(ctx context.Context, auctionID string) error {
+	ret := n.Called(ctx, auctionID)
+	return ret.Error(0)
+}
@@ -0,0 +1,86 @@
+package mock_store
+
+import (
+	"context"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.PaymentRepository = (*MockPaymentStore)(nil)
+
+type MockPaymentStore struct {
+	mock.Mock
+}
+
+func (p *MockPaymentStore) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	ret := p.Called(ctx, payment)
+	return ret.Error(0)
+}
+
+func (p *MockPaymentStore) GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error) {
+	ret := p.Called(ctx, orderID, buyerID)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) GetPendingPayment(ctx context.Context, auctionID string) (*models.Payment, error) {
+	ret := p.Called(ctx, auctionID)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) UpdatePayment(ctx context.Context, paymentStatus, id string) error {
+	ret := p.Called(ctx, paymentStatus, id)
+	return ret.Error(0)
+}
+
+func (p *MockPaymentStore) TransitionPayment(ctx context.Context, id, to string, from []string) (string, error) {
+	ret := p.Called(ctx, id, to, from)
+	return ret.String(0), ret.Error(1)
+}
+
+func (p *MockPaymentStore) GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error) {
+	ret := p.Called(ctx, orderID)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) GetPaymentBySessionID(ctx context.Context, sessionID string) (*models.Payment, error) {
+	ret := p.Called(ctx, sessionID)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) GetStalePendingPayments(ctx context.Context, olderThan time.Duration) ([]*models.Payment, error) {
+	ret := p.Called(ctx, olderThan)
+	return ret.Get(0).([]*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) GetPaymentByIntentID(ctx context.Context, paymentIntentID string) (*models.Payment, error) {
+	ret := p.Called(ctx, paymentIntentID)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) SetPaymentIntent(ctx context.Context, id, paymentIntentID string) error {
+	ret := p.Called(ctx, id, paymentIntentID)
+	return ret.Error(0)
+}
+
+func (p *MockPaymentStore) CreateRefund(ctx context.Context, refund *models.PaymentRefund, from []string) (*models.Payment, error) {
+	ret := p.Called(ctx, refund, from)
+	return ret.Get(0).(*models.Payment), ret.Error(1)
+}
+
+func (p *MockPaymentStore) CompleteRefund(ctx context.Context, refund *models.PaymentRefund) error {
+	ret := p.Called(ctx, refund)
+	return ret.Error(0)
+}
+
+func (p *MockPaymentStore) FailRefund(ctx context.Context, refundID string) error {
+	ret := p.Called(ctx, refundID)
+	return ret.Error(0)
+}
+
+func (p *MockPaymentStore) GetRefunds(ctx context.Context, paymentID string) ([]*models.PaymentRefund, error) {
+	ret := p.Called(ctx, paymentID)
+	return ret.Get(0).([]*models.PaymentRefund), ret.Error(1)
+}
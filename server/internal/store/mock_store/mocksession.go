@@ -0,0 +1,45 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.SessionRepository = (*MockSessionStore)(nil)
+
+type MockSessionStore struct {
+	mock.Mock
+}
+
+func (s *MockSessionStore) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	ret := s.Called(ctx, session, token)
+	return ret.Error(0)
+}
+
+func (s *MockSessionStore) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, client *models.Client) error {
+	ret := s.Called(ctx, tokenHash, next, client)
+	return ret.Error(0)
+}
+
+func (s *MockSessionStore) GetSessions(ctx context.Context, userID, currentHash string) ([]*models.Session, error) {
+	ret := s.Called(ctx, userID, currentHash)
+	return ret.Get(0).([]*models.Session), ret.Error(1)
+}
+
+func (s *MockSessionStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	ret := s.Called(ctx, userID, sessionID)
+	return ret.Error(0)
+}
+
+func (s *MockSessionStore) RevokeSessionByToken(ctx context.Context, tokenHash string) error {
+	ret := s.Called(ctx, tokenHash)
+	return ret.Error(0)
+}
+
+func (s *MockSessionStore) RevokeUserSessions(ctx context.Context, userID, keepHash string) (int64, error) {
+	ret := s.Called(ctx, userID, keepHash)
+	return ret.Get(0).(int64), ret.Error(1)
+}
@@ -0,0 +1,50 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.TwoFactorRepository = (*MockTwoFactorStore)(nil)
+
+type MockTwoFactorStore struct {
+	mock.Mock
+}
+
+func (s *MockTwoFactorStore) GetTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error) {
+	ret := s.Called(ctx, userID)
+	return ret.Get(0).(*models.TwoFactor), ret.Error(1)
+}
+
+func (s *MockTwoFactorStore) StartTwoFactor(ctx context.Context, userID, secret string) error {
+	ret := s.Called(ctx, userID, secret)
+	return ret.Error(0)
+}
+
+func (s *MockTwoFactorStore) EnableTwoFactor(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	ret := s.Called(ctx, userID, step, codeHashes)
+	return ret.Error(0)
+}
+
+func (s *MockTwoFactorStore) DisableTwoFactor(ctx context.Context, userID string) error {
+	ret := s.Called(ctx, userID)
+	return ret.Error(0)
+}
+
+func (s *MockTwoFactorStore) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	ret := s.Called(ctx, userID, step)
+	return ret.Error(0)
+}
+
+func (s *MockTwoFactorStore) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	ret := s.Called(ctx, userID, codeHash)
+	return ret.Error(0)
+}
+
+func (s *MockTwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	ret := s.Called(ctx, userID, codeHashes)
+	return ret.Error(0)
+}
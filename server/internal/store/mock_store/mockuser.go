@@ -2,9 +2,9 @@ package mock_store
 
 import (
 	"context"
-	"time"
 
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 	"github.com/puremike/online_auction_api/internal/store"
 	"github.com/stretchr/testify/mock"
 )
@@ -56,25 +56,17 @@ func (u *MockUserStore) GetUserByUsername(ctx context.Context, username string)
 	ret := u.Called(ctx, username)
 	return ret.Get(0).(*models.User), ret.Error(1)
 }
-func (u *MockUserStore) StoreRefreshToken(ctx context.Context, userID, refreshToken string, expires_at time.Time) error {
-	ret := u.Called(ctx, userID, refreshToken, expires_at)
-	return ret.Error(0)
-}
 func (u *MockUserStore) UpdateUser(ctx context.Context, user *models.User, id string) error {
 	ret := u.Called(ctx, user, id)
 	return ret.Error(0)
 }
-func (u *MockUserStore) ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	ret := u.Called(ctx, refreshToken)
-	return ret.String(0), ret.Error(1)
-}
 func (u *MockUserStore) ChangePassword(ctx context.Context, pass, id string) error {
 	ret := u.Called(ctx, pass, id)
 	return ret.Error(0)
 }
-func (u *MockUserStore) GetUsers(ctx context.Context) (*[]models.User, error) {
-	ret := u.Called(ctx)
-	return ret.Get(0).(*[]models.User), ret.Error(1)
+func (u *MockUserStore) GetUsers(ctx context.Context, page *pagination.Params) (*[]models.User, int, error) {
+	ret := u.Called(ctx, page)
+	return ret.Get(0).(*[]models.User), ret.Int(1), ret.Error(2)
 }
 func (u *MockUserStore) DeleteUser(ctx context.Context, id string) error {
 	ret := u.Called(ctx, id)
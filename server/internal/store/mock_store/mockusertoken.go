@@ -0,0 +1,50 @@
+package mock_store
+
+import (
+	"context"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.UserTokenRepository = (*MockUserTokenStore)(nil)
+
+type MockUserTokenStore struct {
+	mock.Mock
+}
+
+func (u *MockUserTokenStore) CreateToken(ctx context.Context, token *models.UserToken) error {
+	ret := u.Called(ctx, token)
+	return ret.Error(0)
+}
+
+func (u *MockUserTokenStore) GetLatestToken(ctx context.Context, userID, purpose string) (*models.UserToken, error) {
+	ret := u.Called(ctx, userID, purpose)
+	return ret.Get(0).(*models.UserToken), ret.Error(1)
+}
+
+func (u *MockUserTokenStore) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+	ret := u.Called(ctx, tokenHash)
+	return ret.String(0), ret.Error(1)
+}
+
+func (u *MockUserTokenStore) ResetPassword(ctx context.Context, tokenHash, password string) (string, error) {
+	ret := u.Called(ctx, tokenHash, password)
+	return ret.String(0), ret.Error(1)
+}
+
+func (u *MockUserTokenStore) GetActiveToken(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error) {
+	ret := u.Called(ctx, tokenHash, purpose)
+	return ret.Get(0).(*models.UserToken), ret.Error(1)
+}
+
+func (u *MockUserTokenStore) UseToken(ctx context.Context, id string) error {
+	ret := u.Called(ctx, id)
+	return ret.Error(0)
+}
+
+func (u *MockUserTokenStore) FailTokenAttempt(ctx context.Context, id string, maxAttempts int) error {
+	ret := u.Called(ctx, id, maxAttempts)
+	return ret.Error(0)
+}
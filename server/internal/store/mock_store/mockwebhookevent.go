@@ -0,0 +1,37 @@
+package mock_store
+
+import (
+	"context"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+	"github.com/puremike/online_auction_api/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ store.WebhookEventRepository = (*MockWebhookEventStore)(nil)
+
+type MockWebhookEventStore struct {
+	mock.Mock
+}
+
+func (w *MockWebhookEventStore) ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent, staleAfter time.Duration) (bool, error) {
+	ret := w.Called(ctx, event, staleAfter)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (w *MockWebhookEventStore) ClaimWebhookEventReplay(ctx context.Context, id string) (*models.WebhookEvent, error) {
+	ret := w.Called(ctx, id)
+	return ret.Get(0).(*models.WebhookEvent), ret.Error(1)
+}
+
+func (w *MockWebhookEventStore) FinishWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	ret := w.Called(ctx, event)
+	return ret.Error(0)
+}
+
+func (w *MockWebhookEventStore) GetWebhookEvents(ctx context.Context, status string, page *pagination.Params) ([]*models.WebhookEvent, int, error) {
+	ret := w.Called(ctx, status, page)
+	return ret.Get(0).([]*models.WebhookEvent), ret.Int(1), ret.Error(2)
+}
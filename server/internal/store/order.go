@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type OrderStore struct {
+	db *sql.DB
+}
+
+const orderColumns = `id, payment_id, auction_id, buyer_id, seller_id, status, shipping_method, carrier, tracking_number,
+	shipped_at, delivered_at, completed_at, disputed_at, created_at, updated_at`
+
+func scanOrder(row interface{ Scan(dest ...any) error }, o *models.Order) error {
+	return row.Scan(&o.ID, &o.PaymentID, &o.AuctionID, &o.BuyerID, &o.SellerID, &o.Status, &o.ShippingMethod, &o.Carrier, &o.TrackingNumber,
+		&o.ShippedAt, &o.DeliveredAt, &o.CompletedAt, &o.DisputedAt, &o.CreatedAt, &o.UpdatedAt)
+}
+
+// CreateOrder opens the order of a paid auction and reports whether it is new.
+// A payment gets one order: if it already has one, that order is returned
+// instead.
+func (o *OrderStore) CreateOrder(ctx context.Context, order *models.Order) (bool, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO orders (id, payment_id, auction_id, buyer_id, seller_id, shipping_method)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (payment_id) DO NOTHING`
+
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, order.ID, order.PaymentID, order.AuctionID, order.BuyerID, order.SellerID, order.ShippingMethod)
+	if err != nil {
+		return false, err
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if err = scanOrder(tx.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE payment_id = $1`, order.PaymentID), order); err != nil {
+		return false, err
+	}
+
+	return created > 0, tx.Commit()
+}
+
+func (o *OrderStore) GetOrder(ctx context.Context, id string) (*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	order := &models.Order{}
+	if err := scanOrder(o.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1`, id), order); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetOrders returns the orders a user bought or sold
+func (o *OrderStore) GetOrders(ctx context.Context, userID string, page *pagination.Params) ([]*models.Order, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := o.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders WHERE buyer_id = $1 OR seller_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+orderColumns+` FROM orders WHERE (buyer_id = $1 OR seller_id = $1)`, []any{userID}, page)
+
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	orders := []*models.Order{}
+	for rows.Next() {
+		order := &models.Order{}
+		if err := scanOrder(rows, order); err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// TransitionOrder moves an order to order.Status if it is currently in one of
+// the from statuses, recording the carrier and tracking number that came with
+// the change and when it happened. order is updated to the stored row.
+func (o *OrderStore) TransitionOrder(ctx context.Context, order *models.Order, from []string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, order.ID).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrOrderNotFound
+		}
+		return err
+	}
+
+	if !slices.Contains(from, current) {
+		return fmt.Errorf("%w: %s -> %s", errs.ErrIllegalOrderTransition, current, order.Status)
+	}
+
+	query := `UPDATE orders
+		SET status = $1,
+			carrier = CASE WHEN $1 = 'shipped' THEN $2 ELSE carrier END,
+			tracking_number = CASE WHEN $1 = 'shipped' THEN $3 ELSE tracking_number END,
+			shipped_at = CASE WHEN $1 = 'shipped' THEN CURRENT_TIMESTAMP ELSE shipped_at END,
+			delivered_at = CASE WHEN $1 = 'delivered' THEN CURRENT_TIMESTAMP ELSE delivered_at END,
+			completed_at = CASE WHEN $1 = 'completed' THEN CURRENT_TIMESTAMP ELSE completed_at END,
+			disputed_at = CASE WHEN $1 = 'disputed' THEN CURRENT_TIMESTAMP ELSE disputed_at END,
+			status_changed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING ` + orderColumns
+
+	if err := scanOrder(tx.QueryRowContext(ctx, query, order.Status, order.Carrier, order.TrackingNumber, order.ID), order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStaleOrders returns the orders that have been in a status for longer than olderThan
+func (o *OrderStore) GetStaleOrders(ctx context.Context, status string, olderThan time.Duration) ([]*models.Order, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE status = $1 AND status_changed_at < $2 ORDER BY status_changed_at LIMIT 100`
+
+	rows, err := o.db.QueryContext(ctx, query, status, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orders := []*models.Order{}
+	for rows.Next() {
+		order := &models.Order{}
+		if err := scanOrder(rows, order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"strconv"
+
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+// keyset is a (timestamp, id) ordering that pagination cursors can resume from.
+// Columns are fixed by the store and never come from user input.
+type keyset struct {
+	at, id string
+	asc    bool
+}
+
+var createdDesc = keyset{at: "created_at", id: "id"}
+
+// page appends the keyset condition for the cursor (if any), the ordering and
+// the look-ahead LIMIT/OFFSET. query must already contain a WHERE clause.
+func (k keyset) page(query string, args []any, p *pagination.Params) (string, []any) {
+
+	op, dir := "<", "DESC"
+	if k.asc {
+		op, dir = ">", "ASC"
+	}
+
+	if at, id, ok := p.After(); ok {
+		query += ` AND (` + k.at + `, ` + k.id + `) ` + op + ` ($` + strconv.Itoa(len(args)+1) + `, $` + strconv.Itoa(len(args)+2) + `)`
+		args = append(args, at, id)
+	}
+
+	query += ` ORDER BY ` + k.at + ` ` + dir + `, ` + k.id + ` ` + dir
+
+	return limitOffset(query, args, p)
+}
+
+func limitOffset(query string, args []any, p *pagination.Params) (string, []any) {
+	query += ` LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	return query, append(args, p.Fetch(), p.Offset())
+}
@@ -3,8 +3,13 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
+	"slices"
+	"time"
 
+	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
 )
 
@@ -12,12 +17,27 @@ type PaymentStore struct {
 	db *sql.DB
 }
 
+const paymentColumns = `id, auction_id, buyer_id, order_id, session_id, amount, fee, tax, tax_lines, deposit, shipping, shipping_method, status, refunded_amount, COALESCE(payment_intent_id, ''), created_at, updated_at`
+
+func scanPayment(row interface{ Scan(dest ...any) error }, p *models.Payment) error {
+	var taxLines []byte
+	if err := row.Scan(&p.ID, &p.AuctionID, &p.BuyerID, &p.OrderID, &p.SessionID, &p.Amount, &p.Fee, &p.Tax, &taxLines, &p.Deposit, &p.Shipping, &p.ShippingMethod, &p.Status, &p.RefundedAmount, &p.PaymentIntentID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(taxLines, &p.TaxLines)
+}
+
 func (p *PaymentStore) CreatePayment(ctx context.Context, payment *models.Payment) error {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `INSERT INTO payment (auction_id, buyer_id, order_id, session_id, amount, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO payment (auction_id, buyer_id, order_id, session_id, amount, fee, tax, tax_lines, deposit, shipping, shipping_method, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
+
+	taxLines, err := json.Marshal(payment.TaxLines)
+	if err != nil {
+		return err
+	}
 
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -26,7 +46,7 @@ func (p *PaymentStore) CreatePayment(ctx context.Context, payment *models.Paymen
 
 	defer tx.Rollback()
 
-	if err = tx.QueryRowContext(ctx, query, payment.AuctionID, payment.BuyerID, payment.OrderID, payment.SessionID, payment.Amount, payment.Status).Scan(&payment.ID); err != nil {
+	if err = tx.QueryRowContext(ctx, query, payment.AuctionID, payment.BuyerID, payment.OrderID, payment.SessionID, payment.Amount, payment.Fee, payment.Tax, taxLines, payment.Deposit, payment.Shipping, payment.ShippingMethod, payment.Status).Scan(&payment.ID); err != nil {
 		return err
 	}
 
@@ -43,9 +63,9 @@ func (p *PaymentStore) GetPayment(ctx context.Context, orderID, buyerID string)
 
 	var payment models.Payment
 
-	query := `SELECT id, auction_id, buyer_id, order_id, session_id, amount, status, created_at FROM payment WHERE order_id = $1 AND buyer_id = $2`
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE order_id = $1 AND buyer_id = $2`
 
-	if err := p.db.QueryRowContext(ctx, query, orderID, buyerID).Scan(&payment.ID, &payment.AuctionID, &payment.BuyerID, &payment.OrderID, &payment.SessionID, &payment.Amount, &payment.Status, &payment.CreatedAt); err != nil {
+	if err := scanPayment(p.db.QueryRowContext(ctx, query, orderID, buyerID), &payment); err != nil {
 		log.Printf("query failed: %v", err)
 		return nil, err
 	}
@@ -53,6 +73,97 @@ func (p *PaymentStore) GetPayment(ctx context.Context, orderID, buyerID string)
 	return &payment, nil
 }
 
+// GetPendingPayment returns the open checkout for an auction, if there is one.
+// At most one pending payment per auction is allowed by idx_payment_pending_auction.
+func (p *PaymentStore) GetPendingPayment(ctx context.Context, auctionID string) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var payment models.Payment
+
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE auction_id = $1 AND status = 'pending'`
+
+	if err := scanPayment(p.db.QueryRowContext(ctx, query, auctionID), &payment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetStalePendingPayments returns the payments that have been pending for longer than olderThan, oldest first
+func (p *PaymentStore) GetStalePendingPayments(ctx context.Context, olderThan time.Duration) ([]*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE status = 'pending' AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1) ORDER BY created_at`
+
+	rows, err := p.db.QueryContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	pending := []*models.Payment{}
+	for rows.Next() {
+		payment := &models.Payment{}
+		if err := scanPayment(rows, payment); err != nil {
+			return nil, err
+		}
+		pending = append(pending, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func (p *PaymentStore) GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error) {
+	return p.getPaymentBy(ctx, "order_id", orderID)
+}
+
+func (p *PaymentStore) GetPaymentBySessionID(ctx context.Context, sessionID string) (*models.Payment, error) {
+	return p.getPaymentBy(ctx, "session_id", sessionID)
+}
+
+func (p *PaymentStore) GetPaymentByIntentID(ctx context.Context, paymentIntentID string) (*models.Payment, error) {
+	return p.getPaymentBy(ctx, "payment_intent_id", paymentIntentID)
+}
+
+// getPaymentBy looks a payment up by a unique column; column is never user input
+func (p *PaymentStore) getPaymentBy(ctx context.Context, column, value string) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var payment models.Payment
+
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE ` + column + ` = $1`
+
+	if err := scanPayment(p.db.QueryRowContext(ctx, query, value), &payment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// SetPaymentIntent remembers the provider payment of a checkout, so that events
+// that only reference the payment (such as charge.refunded) can be matched
+func (p *PaymentStore) SetPaymentIntent(ctx context.Context, id, paymentIntentID string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, `UPDATE payment SET payment_intent_id = $1 WHERE id = $2`, paymentIntentID, id)
+	return err
+}
+
 func (p *PaymentStore) UpdatePayment(ctx context.Context, paymentStatus, id string) error {
 
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
@@ -78,27 +189,48 @@ func (p *PaymentStore) UpdatePayment(ctx context.Context, paymentStatus, id stri
 	return nil
 }
 
-func (p *PaymentStore) UpdateAuctionPaymentStatus(ctx context.Context, isPaid bool, id string) error {
+// TransitionPayment moves a payment to status to if its current status is one of
+// from. The payment row is locked for the duration, so concurrent webhook
+// deliveries for the same payment are applied one at a time. Completing a
+// payment marks its auction as paid in the same transaction. The status the
+// payment had before is returned, also when the transition is rejected.
+func (p *PaymentStore) TransitionPayment(ctx context.Context, id, to string, from []string) (string, error) {
+
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `UPDATE auctions SET is_paid = $1 WHERE id = $2`
-
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("query failed: %v", err)
-		return err
+		return "", err
 	}
 
 	defer tx.Rollback()
 
-	if _, err = tx.ExecContext(ctx, query, isPaid, id); err != nil {
-		return err
+	var current, auctionID string
+	if err := tx.QueryRowContext(ctx, `SELECT status, auction_id FROM payment WHERE id = $1 FOR UPDATE`, id).Scan(&current, &auctionID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", errs.ErrPaymentNotFound
+		}
+		return "", err
+	}
+
+	if !slices.Contains(from, current) {
+		return current, fmt.Errorf("%w: %s -> %s", errs.ErrIllegalPaymentTransition, current, to)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE payment SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, to, id); err != nil {
+		return current, err
+	}
+
+	if to == "completed" {
+		if _, err := tx.ExecContext(ctx, `UPDATE auctions SET is_paid = TRUE WHERE id = $1`, auctionID); err != nil {
+			return current, err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
-		return err
+		return current, err
 	}
 
-	return nil
+	return current, nil
 }
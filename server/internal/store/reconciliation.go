@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type ReconciliationStore struct {
+	db *sql.DB
+}
+
+const paymentMismatchColumns = `id, payment_id, auction_id, session_id, local_status, provider_status, action, detail, created_at`
+
+func scanPaymentMismatch(row interface{ Scan(dest ...any) error }, m *models.PaymentMismatch) error {
+	return row.Scan(&m.ID, &m.PaymentID, &m.AuctionID, &m.SessionID, &m.LocalStatus, &m.ProviderStatus, &m.Action, &m.Detail, &m.CreatedAt)
+}
+
+func (r *ReconciliationStore) CreatePaymentMismatch(ctx context.Context, mismatch *models.PaymentMismatch) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO payment_mismatch (payment_id, auction_id, session_id, local_status, provider_status, action, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, mismatch.PaymentID, mismatch.AuctionID, mismatch.SessionID, mismatch.LocalStatus, mismatch.ProviderStatus, mismatch.Action, mismatch.Detail).Scan(&mismatch.ID, &mismatch.CreatedAt)
+}
+
+// GetPaymentMismatches lists mismatches newest first, optionally only those resolved by the given action
+func (r *ReconciliationStore) GetPaymentMismatches(ctx context.Context, action string, page *pagination.Params) ([]*models.PaymentMismatch, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	where := ` WHERE ($1 = '' OR action = $1)`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM payment_mismatch`+where, action).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+paymentMismatchColumns+` FROM payment_mismatch`+where, []any{action}, page)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	mismatches := []*models.PaymentMismatch{}
+	for rows.Next() {
+		mismatch := &models.PaymentMismatch{}
+		if err := scanPaymentMismatch(rows, mismatch); err != nil {
+			return nil, 0, err
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return mismatches, total, nil
+}
+
+// FixAuctionPaidFlags makes is_paid agree with the payments of each auction:
+// an auction is paid while it has a completed or partially refunded payment.
+// It returns the corrected auctions with their new flag.
+func (r *ReconciliationStore) FixAuctionPaidFlags(ctx context.Context) (map[string]bool, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE auctions a SET is_paid = e.paid
+		FROM (
+			SELECT x.id, EXISTS (
+				SELECT 1 FROM payment p WHERE p.auction_id = x.id AND p.status IN ('completed', 'partially_refunded')
+			) AS paid
+			FROM auctions x
+			WHERE x.is_paid OR EXISTS (SELECT 1 FROM payment p WHERE p.auction_id = x.id)
+		) e
+		WHERE a.id = e.id AND a.is_paid <> e.paid
+		RETURNING a.id, a.is_paid`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	fixed := map[string]bool{}
+	for rows.Next() {
+		var id string
+		var isPaid bool
+		if err := rows.Scan(&id, &isPaid); err != nil {
+			return nil, err
+		}
+		fixed[id] = isPaid
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return fixed, nil
+}
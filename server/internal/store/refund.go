@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+const refundColumns = `id, payment_id, provider_refund_id, amount, reason, status, requested_by, created_at, updated_at`
+
+func scanRefund(row interface{ Scan(dest ...any) error }, r *models.PaymentRefund) error {
+	return row.Scan(&r.ID, &r.PaymentID, &r.ProviderRefundID, &r.Amount, &r.Reason, &r.Status, &r.RequestedBy, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// CreateRefund records a refund and adds its amount to the payment's refunded
+// amount, moving the payment to refunded or partially_refunded. The payment
+// must currently be in one of the from statuses and the refund may not exceed
+// what is left to refund. A fully refunded auction is no longer marked paid.
+// The updated payment is returned.
+func (p *PaymentStore) CreateRefund(ctx context.Context, refund *models.PaymentRefund, from []string) (*models.Payment, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM payment WHERE id = $1 FOR UPDATE`, refund.PaymentID).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	if !slices.Contains(from, current) {
+		return nil, fmt.Errorf("%w: %s -> refunded", errs.ErrIllegalPaymentTransition, current)
+	}
+
+	query := `UPDATE payment
+		SET refunded_amount = refunded_amount + $1,
+			status = CASE WHEN refunded_amount + $1 >= amount + deposit THEN 'refunded' ELSE 'partially_refunded' END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND refunded_amount + $1 <= amount + deposit
+		RETURNING ` + paymentColumns
+
+	payment := &models.Payment{}
+	if err := scanPayment(tx.QueryRowContext(ctx, query, refund.Amount, refund.PaymentID), payment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrRefundExceedsPayment
+		}
+		return nil, err
+	}
+
+	insert := `INSERT INTO payment_refund (payment_id, provider_refund_id, amount, reason, status, requested_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + refundColumns
+	if err := scanRefund(tx.QueryRowContext(ctx, insert, refund.PaymentID, refund.ProviderRefundID, refund.Amount, refund.Reason, refund.Status, refund.RequestedBy), refund); err != nil {
+		return nil, err
+	}
+
+	if payment.Status == "refunded" {
+		if _, err := tx.ExecContext(ctx, `UPDATE auctions SET is_paid = FALSE WHERE id = $1`, payment.AuctionID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (p *PaymentStore) CompleteRefund(ctx context.Context, refund *models.PaymentRefund) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE payment_refund SET status = 'succeeded', provider_refund_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 RETURNING status, updated_at`
+
+	return p.db.QueryRowContext(ctx, query, refund.ProviderRefundID, refund.ID).Scan(&refund.Status, &refund.UpdatedAt)
+}
+
+// FailRefund marks a pending refund failed and gives its amount back to the
+// payment, undoing CreateRefund
+func (p *PaymentStore) FailRefund(ctx context.Context, refundID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var paymentID, status string
+	var amount float64
+	if err := tx.QueryRowContext(ctx, `SELECT payment_id, amount, status FROM payment_refund WHERE id = $1 FOR UPDATE`, refundID).Scan(&paymentID, &amount, &status); err != nil {
+		return err
+	}
+
+	if status != models.RefundPending {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE payment_refund SET status = 'failed', updated_at = CURRENT_TIMESTAMP WHERE id = $1`, refundID); err != nil {
+		return err
+	}
+
+	query := `UPDATE payment
+		SET refunded_amount = refunded_amount - $1,
+			status = CASE WHEN refunded_amount - $1 <= 0 THEN 'completed' ELSE 'partially_refunded' END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 RETURNING auction_id`
+
+	var auctionID string
+	if err := tx.QueryRowContext(ctx, query, amount, paymentID).Scan(&auctionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE auctions SET is_paid = TRUE WHERE id = $1`, auctionID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PaymentStore) GetRefunds(ctx context.Context, paymentID string) ([]*models.PaymentRefund, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `SELECT `+refundColumns+` FROM payment_refund WHERE payment_id = $1 ORDER BY created_at DESC`, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	refunds := []*models.PaymentRefund{}
+	for rows.Next() {
+		refund := &models.PaymentRefund{}
+		if err := scanRefund(rows, refund); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type SavedSearchStore struct {
+	db *sql.DB
+}
+
+const savedSearchColumns = `id, user_id, name, type, category, status, min_price, max_price, keyword, notify_new_listings, created_at, updated_at`
+
+func scanSavedSearch(row interface{ Scan(dest ...any) error }, s *models.SavedSearch) error {
+	return row.Scan(&s.ID, &s.UserID, &s.Name, &s.Filter.Type, &s.Filter.Category, &s.Filter.Status, &s.Filter.MinPrice, &s.Filter.MaxPrice, &s.Filter.Keyword, &s.NotifyNewListings, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func (s *SavedSearchStore) CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO saved_search (user_id, name, type, category, status, min_price, max_price, keyword, notify_new_listings) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING ` + savedSearchColumns
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	f := search.Filter
+	if err = scanSavedSearch(tx.QueryRowContext(ctx, query, search.UserID, search.Name, f.Type, f.Category, f.Status, f.MinPrice, f.MaxPrice, f.Keyword, search.NotifyNewListings), search); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SavedSearchStore) GetSavedSearches(ctx context.Context, userID string, page *pagination.Params) ([]*models.SavedSearch, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_search WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := createdDesc.page(`SELECT `+savedSearchColumns+` FROM saved_search WHERE user_id = $1`, []any{userID}, page)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	searches := []*models.SavedSearch{}
+	for rows.Next() {
+		search := &models.SavedSearch{}
+		if err := scanSavedSearch(rows, search); err != nil {
+			return nil, 0, err
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return searches, total, nil
+}
+
+func (s *SavedSearchStore) GetSavedSearchByID(ctx context.Context, id, userID string) (*models.SavedSearch, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	search := &models.SavedSearch{}
+
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_search WHERE id = $1 AND user_id = $2`
+
+	if err := scanSavedSearch(s.db.QueryRowContext(ctx, query, id, userID), search); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrSavedSearchNotFound
+		}
+		return nil, err
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchStore) UpdateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE saved_search SET name = $1, type = $2, category = $3, status = $4, min_price = $5, max_price = $6, keyword = $7, notify_new_listings = $8, updated_at = CURRENT_TIMESTAMP WHERE id = $9 AND user_id = $10 RETURNING ` + savedSearchColumns
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	f := search.Filter
+	if err = scanSavedSearch(tx.QueryRowContext(ctx, query, search.Name, f.Type, f.Category, f.Status, f.MinPrice, f.MaxPrice, f.Keyword, search.NotifyNewListings, search.ID, search.UserID), search); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrSavedSearchNotFound
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SavedSearchStore) DeleteSavedSearch(ctx context.Context, id, userID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `DELETE FROM saved_search WHERE id = $1 AND user_id = $2`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrSavedSearchNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetMatchingSavedSearches returns the saved searches with alerts enabled that the
+// given auction satisfies. Empty filter fields match everything. The seller's own
+// searches are skipped.
+func (s *SavedSearchStore) GetMatchingSavedSearches(ctx context.Context, auction *models.Auction) ([]*models.SavedSearch, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_search
+WHERE notify_new_listings = TRUE
+  AND user_id <> $1
+  AND (type = '' OR type = $2)
+  AND (category = '' OR category = $3)
+  AND (status = '' OR status = $4)
+  AND (min_price = 0 OR min_price <= $5)
+  AND (max_price = 0 OR max_price >= $5)
+  AND (keyword = '' OR strpos(lower($6), lower(keyword)) > 0)`
+
+	rows, err := s.db.QueryContext(ctx, query, auction.SellerID, auction.Type, auction.Category, auction.Status, auction.CurrentPrice, auction.Title+" "+auction.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var searches []*models.SavedSearch
+	for rows.Next() {
+		search := &models.SavedSearch{}
+		if err := scanSavedSearch(rows, search); err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+const sessionColumns = `id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(dest ...any) error }, s *models.Session, extra ...any) error {
+	return row.Scan(append([]any{&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt}, extra...)...)
+}
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, used_at, created_at`
+
+func scanRefreshToken(row interface{ Scan(dest ...any) error }, t *models.RefreshToken) error {
+	return row.Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+}
+
+// CreateSession starts a session with its first refresh token, clearing out
+// the user's expired sessions on the way
+func (s *SessionStore) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1 AND expires_at < CURRENT_TIMESTAMP`, session.UserID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING ` + sessionColumns
+
+	if err := scanSession(tx.QueryRowContext(ctx, query, session.ID, session.UserID, session.UserAgent, session.IPAddress, session.ExpiresAt), session); err != nil {
+		return err
+	}
+
+	token.UserID, token.FamilyID, token.ExpiresAt = session.UserID, session.ID, session.ExpiresAt
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RotateRefreshToken uses up a refresh token and stores next in its session,
+// recording the client as the session's latest. A token that was already used
+// is being replayed, so its session is revoked and errs.ErrRefreshTokenReused
+// returned.
+func (s *SessionStore) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, client *models.Client) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `SELECT t.id, t.user_id, t.family_id, t.token_hash, t.expires_at, t.used_at, t.created_at, s.revoked_at
+		FROM refresh_tokens t JOIN sessions s ON s.id = t.family_id
+		WHERE t.token_hash = $1
+		FOR UPDATE OF t, s`
+
+	current := &models.RefreshToken{}
+	var revokedAt *time.Time
+	if err := tx.QueryRowContext(ctx, query, tokenHash).Scan(&current.ID, &current.UserID, &current.FamilyID, &current.TokenHash, &current.ExpiresAt, &current.UsedAt, &current.CreatedAt, &revokedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return errs.ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	if revokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return errs.ErrInvalidRefreshToken
+	}
+
+	if current.UsedAt != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1`, current.FamilyID); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return errs.ErrRefreshTokenReused
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1`, current.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET user_agent = $1, ip_address = $2, last_used_at = CURRENT_TIMESTAMP WHERE id = $3`, client.UserAgent, client.IPAddress, current.FamilyID); err != nil {
+		return err
+	}
+
+	next.UserID, next.FamilyID, next.ExpiresAt = current.UserID, current.FamilyID, current.ExpiresAt
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSessions returns a user's sessions that are neither revoked nor expired,
+// most recently used first. The one currentHash belongs to is marked current.
+func (s *SessionStore) GetSessions(ctx context.Context, userID, currentHash string) ([]*models.Session, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + sessionColumns + `,
+		EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = sessions.id AND t.token_hash = $2)
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_used_at DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, currentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session := &models.Session{}
+		if err := scanSession(rows, session, &session.Current); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *SessionStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessionByToken ends the session a refresh token belongs to
+func (s *SessionStore) RevokeSessionByToken(ctx context.Context, tokenHash string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $1) AND revoked_at IS NULL`
+
+	_, err := s.db.ExecContext(ctx, query, tokenHash)
+	return err
+}
+
+// RevokeUserSessions ends every session of a user except the one the refresh
+// token keepHash belongs to, if given. It returns how many were ended.
+func (s *SessionStore) RevokeUserSessions(ctx context.Context, userID, keepHash string) (int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL
+		AND id IS DISTINCT FROM (SELECT family_id FROM refresh_tokens WHERE token_hash = $2 AND user_id = $1)`
+
+	res, err := s.db.ExecContext(ctx, query, userID, keepHash)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING ` + refreshTokenColumns
+	return scanRefreshToken(tx.QueryRowContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt), token)
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type ShippingStore struct {
+	db *sql.DB
+}
+
+const shippingColumns = `auction_id, buyer_id, method, cost, address, created_at, updated_at`
+
+func scanShipping(row interface{ Scan(dest ...any) error }, s *models.ShippingSelection) error {
+	var address []byte
+	if err := row.Scan(&s.AuctionID, &s.BuyerID, &s.Method, &s.Cost, &address, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+	if address == nil {
+		s.Address = nil
+		return nil
+	}
+	return json.Unmarshal(address, &s.Address)
+}
+
+// SaveShipping records the buyer's shipping choice for an auction, replacing
+// any earlier one
+func (s *ShippingStore) SaveShipping(ctx context.Context, selection *models.ShippingSelection) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var address []byte
+	if selection.Address != nil {
+		var err error
+		if address, err = json.Marshal(selection.Address); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO auction_shipping (auction_id, buyer_id, method, cost, address)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (auction_id) DO UPDATE
+		SET buyer_id = EXCLUDED.buyer_id, method = EXCLUDED.method, cost = EXCLUDED.cost, address = EXCLUDED.address, updated_at = CURRENT_TIMESTAMP
+		RETURNING ` + shippingColumns
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = scanShipping(tx.QueryRowContext(ctx, query, selection.AuctionID, selection.BuyerID, selection.Method, selection.Cost, address), selection); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *ShippingStore) GetShipping(ctx context.Context, auctionID string) (*models.ShippingSelection, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	selection := &models.ShippingSelection{}
+	if err := scanShipping(s.db.QueryRowContext(ctx, `SELECT `+shippingColumns+` FROM auction_shipping WHERE auction_id = $1`, auctionID), selection); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrShippingSelectionNotFound
+		}
+		return nil, err
+	}
+
+	return selection, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 )
 
 type UserRepository interface {
@@ -13,26 +14,23 @@ type UserRepository interface {
 	GetUserById(ctx context.Context, id string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
-	StoreRefreshToken(ctx context.Context, userID, refreshToken string, expires_at time.Time) error
 	UpdateUser(ctx context.Context, user *models.User, id string) error
-	ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error)
 	ChangePassword(ctx context.Context, pass, id string) error
-	GetUsers(ctx context.Context) (*[]models.User, error)
+	GetUsers(ctx context.Context, page *pagination.Params) (*[]models.User, int, error)
 	DeleteUser(ctx context.Context, id string) error
 }
 
 type AuctionRepository interface {
 	GetAuctionById(ctx context.Context, id string) (*models.Auction, error)
-	GetAuctions(ctx context.Context, limit, offset int, filter *models.AuctionFilter) (*[]models.Auction, error)
+	GetAuctions(ctx context.Context, page *pagination.Params, filter *models.AuctionFilter) (*[]models.Auction, int, error)
 	CreateAuction(ctx context.Context, auction *models.Auction) (*models.Auction, error)
 	CloseAuction(ctx context.Context, status, id string) error
 	UpdateAuction(ctx context.Context, auction *models.Auction, id string) error
 	DeleteAuction(ctx context.Context, id string) error
-	GetWonAuctionsByWinnerID(ctx context.Context, winnerID string) (*[]models.Auction, error)
-	UpdateAuctionPaymentStatus(ctx context.Context, isPaid bool, id string) error
-	GetBiddedAuctions(ctx context.Context, bidderID string) (*[]models.Auction, error)
+	GetWonAuctionsByWinnerID(ctx context.Context, winnerID string, page *pagination.Params) (*[]models.Auction, int, error)
+	GetBiddedAuctions(ctx context.Context, bidderID string, page *pagination.Params) (*[]models.Auction, int, error)
 	GetAuctionByWinnerId(ctx context.Context, winnerID string) (*models.Auction, error)
-	GetAuctionBySellerId(ctx context.Context, sellerID string) (*[]models.Auction, error)
+	GetAuctionBySellerId(ctx context.Context, sellerID string, page *pagination.Params) (*[]models.Auction, int, error)
 }
 
 type BidRepository interface {
@@ -48,7 +46,84 @@ type BidRepository interface {
 type PaymentRepository interface {
 	CreatePayment(ctx context.Context, payment *models.Payment) error
 	GetPayment(ctx context.Context, orderID, buyerID string) (*models.Payment, error)
+	GetPendingPayment(ctx context.Context, auctionID string) (*models.Payment, error)
 	UpdatePayment(ctx context.Context, paymentStatus, id string) error
+	TransitionPayment(ctx context.Context, id, to string, from []string) (string, error)
+	GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error)
+	GetPaymentBySessionID(ctx context.Context, sessionID string) (*models.Payment, error)
+	GetStalePendingPayments(ctx context.Context, olderThan time.Duration) ([]*models.Payment, error)
+	GetPaymentByIntentID(ctx context.Context, paymentIntentID string) (*models.Payment, error)
+	SetPaymentIntent(ctx context.Context, id, paymentIntentID string) error
+	CreateRefund(ctx context.Context, refund *models.PaymentRefund, from []string) (*models.Payment, error)
+	CompleteRefund(ctx context.Context, refund *models.PaymentRefund) error
+	FailRefund(ctx context.Context, refundID string) error
+	GetRefunds(ctx context.Context, paymentID string) ([]*models.PaymentRefund, error)
+}
+
+type WebhookEventRepository interface {
+	ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent, staleAfter time.Duration) (bool, error)
+	ClaimWebhookEventReplay(ctx context.Context, id string) (*models.WebhookEvent, error)
+	FinishWebhookEvent(ctx context.Context, event *models.WebhookEvent) error
+	GetWebhookEvents(ctx context.Context, status string, page *pagination.Params) ([]*models.WebhookEvent, int, error)
+}
+
+type ReconciliationRepository interface {
+	CreatePaymentMismatch(ctx context.Context, mismatch *models.PaymentMismatch) error
+	GetPaymentMismatches(ctx context.Context, action string, page *pagination.Params) ([]*models.PaymentMismatch, int, error)
+	FixAuctionPaidFlags(ctx context.Context) (map[string]bool, error)
+}
+
+type InvoiceRepository interface {
+	CreateInvoice(ctx context.Context, invoice *models.Invoice) error
+	GetInvoiceByPaymentID(ctx context.Context, paymentID string) (*models.Invoice, error)
+}
+
+type HoldRepository interface {
+	CreateHold(ctx context.Context, hold *models.BidHold) error
+	GetLiveHold(ctx context.Context, auctionID, bidderID string) (*models.BidHold, error)
+	GetLiveHolds(ctx context.Context, auctionID string) ([]*models.BidHold, error)
+	UpdateHoldStatus(ctx context.Context, hold *models.BidHold) error
+}
+
+type AddressRepository interface {
+	CreateAddress(ctx context.Context, address *models.Address) error
+	GetAddresses(ctx context.Context, userID string, page *pagination.Params) ([]*models.Address, int, error)
+	GetAddressByID(ctx context.Context, id, userID string) (*models.Address, error)
+	UpdateAddress(ctx context.Context, address *models.Address) error
+	DeleteAddress(ctx context.Context, id, userID string) error
+}
+
+type ShippingRepository interface {
+	SaveShipping(ctx context.Context, selection *models.ShippingSelection) error
+	GetShipping(ctx context.Context, auctionID string) (*models.ShippingSelection, error)
+}
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *models.Order) (bool, error)
+	GetOrder(ctx context.Context, id string) (*models.Order, error)
+	GetOrders(ctx context.Context, userID string, page *pagination.Params) ([]*models.Order, int, error)
+	TransitionOrder(ctx context.Context, order *models.Order, from []string) error
+	GetStaleOrders(ctx context.Context, status string, olderThan time.Duration) ([]*models.Order, error)
+}
+
+type DisputeRepository interface {
+	CreateDispute(ctx context.Context, dispute *models.Dispute, orderFrom []string) error
+	GetDispute(ctx context.Context, id string) (*models.Dispute, error)
+	GetDisputes(ctx context.Context, userID string, page *pagination.Params) ([]*models.Dispute, int, error)
+	GetDisputesByStatus(ctx context.Context, status string, page *pagination.Params) ([]*models.Dispute, int, error)
+	TransitionDispute(ctx context.Context, dispute *models.Dispute, from []string, orderStatus string) error
+	GetOverdueDisputes(ctx context.Context) ([]*models.Dispute, error)
+	CreateDisputeMessage(ctx context.Context, message *models.DisputeMessage) error
+	GetDisputeMessages(ctx context.Context, disputeID string, page *pagination.Params) ([]*models.DisputeMessage, int, error)
+}
+
+type FeedbackRepository interface {
+	CreateFeedback(ctx context.Context, feedback *models.Feedback) error
+	GetFeedback(ctx context.Context, id string) (*models.Feedback, error)
+	UpdateFeedback(ctx context.Context, feedback *models.Feedback) error
+	ReplyToFeedback(ctx context.Context, feedback *models.Feedback) error
+	GetReceivedFeedback(ctx context.Context, userID, role string, page *pagination.Params) ([]*models.Feedback, int, error)
+	GetFeedbackScores(ctx context.Context, userID string) (*models.UserFeedback, error)
 }
 
 type NotificationRepository interface {
@@ -57,27 +132,117 @@ type NotificationRepository interface {
 	DeleteNotificationByAuction(ctx context.Context, auctionID string) error
 }
 
+type WatchlistRepository interface {
+	AddWatch(ctx context.Context, watch *models.Watch) error
+	RemoveWatch(ctx context.Context, userID, auctionID string) error
+	GetWatchedAuctions(ctx context.Context, userID string, page *pagination.Params) (*[]models.Auction, int, error)
+	CountWatchers(ctx context.Context, auctionID string) (int, error)
+	GetPriceChangeWatchers(ctx context.Context, auctionID string) ([]string, error)
+	GetEndingWatches(ctx context.Context, within time.Duration) ([]*models.WatchReminder, error)
+	MarkReminderSent(ctx context.Context, watchID string, leadTime time.Duration) error
+}
+
+type SavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error
+	GetSavedSearches(ctx context.Context, userID string, page *pagination.Params) ([]*models.SavedSearch, int, error)
+	GetSavedSearchByID(ctx context.Context, id, userID string) (*models.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, search *models.SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, id, userID string) error
+	GetMatchingSavedSearches(ctx context.Context, auction *models.Auction) ([]*models.SavedSearch, error)
+}
+
+type LedgerRepository interface {
+	CreditSale(ctx context.Context, entry *models.LedgerEntry) (bool, error)
+	DebitRefund(ctx context.Context, paymentID, refundID string, refundAmount float64) error
+	ReleasePayment(ctx context.Context, paymentID string) (int64, error)
+	ReleaseDue(ctx context.Context) ([]*models.LedgerEntry, error)
+	GetBalance(ctx context.Context, sellerID string) (*models.Balance, error)
+	CreatePayout(ctx context.Context, sellerID, provider string) (*models.Payout, error)
+	CompletePayout(ctx context.Context, payout *models.Payout) error
+	FailPayout(ctx context.Context, payout *models.Payout) error
+	GetPayouts(ctx context.Context, sellerID string, page *pagination.Params) ([]*models.Payout, int, error)
+}
+
+type UserTokenRepository interface {
+	CreateToken(ctx context.Context, token *models.UserToken) error
+	GetLatestToken(ctx context.Context, userID, purpose string) (*models.UserToken, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (string, error)
+	ResetPassword(ctx context.Context, tokenHash, password string) (string, error)
+	GetActiveToken(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error)
+	UseToken(ctx context.Context, id string) error
+	FailTokenAttempt(ctx context.Context, id string, maxAttempts int) error
+}
+
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error)
+	StartTwoFactor(ctx context.Context, userID, secret string) error
+	EnableTwoFactor(ctx context.Context, userID string, step int64, codeHashes []string) error
+	DisableTwoFactor(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, client *models.Client) error
+	GetSessions(ctx context.Context, userID, currentHash string) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeSessionByToken(ctx context.Context, tokenHash string) error
+	RevokeUserSessions(ctx context.Context, userID, keepHash string) (int64, error)
+}
+
 type CSRepository interface {
 	ContactSupport(ctx context.Context, cs *models.ContactSupport) (*models.ContactSupport, error)
 }
 
 type Storage struct {
-	Users         UserRepository
-	Auctions      AuctionRepository
-	Bids          BidRepository
-	Payments      PaymentRepository
-	Notifications NotificationRepository
-	CS            CSRepository
+	Users          UserRepository
+	Auctions       AuctionRepository
+	Bids           BidRepository
+	Payments       PaymentRepository
+	Notifications  NotificationRepository
+	CS             CSRepository
+	Watchlist      WatchlistRepository
+	SavedSearches  SavedSearchRepository
+	WebhookEvents  WebhookEventRepository
+	Ledger         LedgerRepository
+	Reconciliation ReconciliationRepository
+	Invoices       InvoiceRepository
+	Holds          HoldRepository
+	Addresses      AddressRepository
+	Shipping       ShippingRepository
+	Orders         OrderRepository
+	Disputes       DisputeRepository
+	Feedback       FeedbackRepository
+	Tokens         UserTokenRepository
+	Sessions       SessionRepository
+	TwoFactor      TwoFactorRepository
 }
 
 func NewStorage(db *sql.DB) *Storage {
 	return &Storage{
-		Users:         &UserStore{db},
-		Auctions:      &AuctionStore{db},
-		Bids:          &BidStore{db},
-		Payments:      &PaymentStore{db},
-		Notifications: &NotificationStore{db},
-		CS:            &CSStore{db},
+		Users:          &UserStore{db},
+		Auctions:       &AuctionStore{db},
+		Bids:           &BidStore{db},
+		Payments:       &PaymentStore{db},
+		Notifications:  &NotificationStore{db},
+		CS:             &CSStore{db},
+		Watchlist:      &WatchlistStore{db},
+		SavedSearches:  &SavedSearchStore{db},
+		WebhookEvents:  &WebhookEventStore{db},
+		Ledger:         &LedgerStore{db},
+		Reconciliation: &ReconciliationStore{db},
+		Invoices:       &InvoiceStore{db},
+		Holds:          &HoldStore{db},
+		Addresses:      &AddressStore{db},
+		Shipping:       &ShippingStore{db},
+		Orders:         &OrderStore{db},
+		Disputes:       &DisputeStore{db},
+		Feedback:       &FeedbackStore{db},
+		Tokens:         &UserTokenStore{db},
+		Sessions:       &SessionStore{db},
+		TwoFactor:      &TwoFactorStore{db},
 	}
 }
 
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type TwoFactorStore struct {
+	db *sql.DB
+}
+
+// GetTwoFactor returns a user's enrollment, confirmed or not. Users who never
+// started setting up two-factor return errs.ErrTwoFactorNotEnabled.
+func (t *TwoFactorStore) GetTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT user_id, secret, enabled_at, last_step, created_at FROM two_factor WHERE user_id = $1`
+
+	tf := &models.TwoFactor{}
+	if err := t.db.QueryRowContext(ctx, query, userID).Scan(&tf.UserID, &tf.Secret, &tf.EnabledAt, &tf.LastStep, &tf.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// StartTwoFactor stores the secret of a new enrollment, replacing one that was
+// never confirmed. Users who already have two-factor on return
+// errs.ErrTwoFactorAlreadyEnabled.
+func (t *TwoFactorStore) StartTwoFactor(ctx context.Context, userID, secret string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO two_factor (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_step = 0, created_at = CURRENT_TIMESTAMP
+		WHERE two_factor.enabled_at IS NULL`
+
+	res, err := t.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+// EnableTwoFactor confirms an enrollment with the step of the code it was
+// confirmed with, and stores the user's first recovery codes
+func (t *TwoFactorStore) EnableTwoFactor(ctx context.Context, userID string, step int64, codeHashes []string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE two_factor SET enabled_at = CURRENT_TIMESTAMP, last_step = $2 WHERE user_id = $1 AND enabled_at IS NULL`, userID, step)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableTwoFactor drops a user's enrollment and recovery codes
+func (t *TwoFactorStore) DisableTwoFactor(ctx context.Context, userID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records that a code for step was accepted. A step at or before
+// the last accepted one returns errs.ErrInvalidTwoFactorCode, so a code can't
+// be replayed within its validity window.
+func (t *TwoFactorStore) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	res, err := t.db.ExecContext(ctx, `UPDATE two_factor SET last_step = $2 WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_step < $2`, userID, step)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// UseRecoveryCode uses up a recovery code. Unknown and used codes return
+// errs.ErrInvalidTwoFactorCode.
+func (t *TwoFactorStore) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	res, err := t.db.ExecContext(ctx, `UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps a user's recovery codes for new ones
+func (t *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string) error {
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,12 +3,11 @@ package store
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"strings"
-	"time"
 
 	"github.com/puremike/online_auction_api/internal/errs"
 	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
 )
 
 type UserStore struct {
@@ -69,9 +68,9 @@ func (u *UserStore) GetUserByEmail(ctx context.Context, email string) (*models.U
 
 	user := &models.User{}
 
-	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin FROM users WHERE email = $1`
+	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin, email_verified_at FROM users WHERE email = $1`
 
-	if err := u.db.QueryRowContext(ctx, query, strings.ToLower(email)).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin); err != nil {
+	if err := u.db.QueryRowContext(ctx, query, strings.ToLower(email)).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin, &user.EmailVerifiedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.ErrUserNotFound
 		}
@@ -87,9 +86,9 @@ func (u *UserStore) GetUserByUsername(ctx context.Context, username string) (*mo
 
 	user := &models.User{}
 
-	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin FROM users WHERE username = $1`
+	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin, email_verified_at FROM users WHERE username = $1`
 
-	if err := u.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin); err != nil {
+	if err := u.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin, &user.EmailVerifiedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.ErrUserNotFound
 		}
@@ -105,9 +104,9 @@ func (u *UserStore) GetUserById(ctx context.Context, id string) (*models.User, e
 
 	user := &models.User{}
 
-	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin FROM users WHERE id = $1`
+	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin, email_verified_at FROM users WHERE id = $1`
 
-	if err := u.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin); err != nil {
+	if err := u.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.Location, &user.CreatedAt, &user.IsAdmin, &user.EmailVerifiedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.ErrUserNotFound
 		}
@@ -121,7 +120,10 @@ func (u *UserStore) UpdateUser(ctx context.Context, user *models.User, id string
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	query := `UPDATE users SET username = $1, email = $2, full_name = $3, location = $4 WHERE id = $5`
+	// a new email address has to be verified again
+	query := `UPDATE users SET username = $1, email = $2, full_name = $3, location = $4,
+		email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+		WHERE id = $5`
 
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -147,27 +149,8 @@ func (u *UserStore) ChangePassword(ctx context.Context, pass, id string) error {
 
 	query := `UPDATE users SET password = $1 WHERE id = $2`
 
-	tx, err := u.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	defer tx.Rollback()
-
-	if _, err = tx.ExecContext(ctx, query, pass, id); err != nil {
-		return err
-	}
-
-	if err = tx.Commit(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (u *UserStore) StoreRefreshToken(ctx context.Context, userID, refreshToken string, expires_at time.Time) error {
-	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
-	defer cancel()
+	// a changed password ends every login made with the old one
+	revokeQuery := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`
 
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -176,17 +159,11 @@ func (u *UserStore) StoreRefreshToken(ctx context.Context, userID, refreshToken
 
 	defer tx.Rollback()
 
-	// delete existing refresh tokens for the user
-	deleteQuery := `DELETE FROM refresh_tokens WHERE user_id = $1`
-
-	if _, err = tx.ExecContext(ctx, deleteQuery, userID); err != nil {
+	if _, err = tx.ExecContext(ctx, query, pass, id); err != nil {
 		return err
 	}
 
-	// Insert the new refresh token
-	insertQuery := `INSERT INTO refresh_tokens (user_id, token, expires_at) VALUES ($1, $2, $3) `
-
-	if _, err = tx.ExecContext(ctx, insertQuery, userID, refreshToken, expires_at); err != nil {
+	if _, err = tx.ExecContext(ctx, revokeQuery, id); err != nil {
 		return err
 	}
 
@@ -197,39 +174,22 @@ func (u *UserStore) StoreRefreshToken(ctx context.Context, userID, refreshToken
 	return nil
 }
 
-func (u *UserStore) ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error) {
+func (u *UserStore) GetUsers(ctx context.Context, page *pagination.Params) (*[]models.User, int, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
 	defer cancel()
 
-	var userID string
-	var expiresAt time.Time
-
-	query := `SELECT user_id, expires_at FROM refresh_tokens WHERE token = $1`
+	var users []models.User
 
-	if err := u.db.QueryRowContext(ctx, query, refreshToken).Scan(&userID, &expiresAt); err != nil {
-		if err == sql.ErrNoRows {
-			return "", errs.ErrTokenNotFound
-		}
-		return "", err
-	}
-	if time.Now().After(expiresAt) {
-		return "", errors.New("refresh token expired")
+	var total int
+	if err := u.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	return userID, nil
-}
+	query, args := createdDesc.page(`SELECT id, username, email, password, full_name, location, created_at, is_admin, email_verified_at FROM users WHERE 1=1`, nil, page)
 
-func (u *UserStore) GetUsers(ctx context.Context) (*[]models.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
-	defer cancel()
-
-	var users []models.User
-
-	query := `SELECT id, username, email, password, full_name, location, created_at, is_admin FROM users`
-
-	rows, err := u.db.QueryContext(ctx, query)
+	rows, err := u.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer rows.Close()
@@ -237,8 +197,8 @@ func (u *UserStore) GetUsers(ctx context.Context) (*[]models.User, error) {
 	for rows.Next() {
 		var u models.User
 
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.FullName, &u.Location, &u.CreatedAt, &u.IsAdmin); err != nil {
-			return nil, err
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.FullName, &u.Location, &u.CreatedAt, &u.IsAdmin, &u.EmailVerifiedAt); err != nil {
+			return nil, 0, err
 		}
 
 		users = append(users, u)
@@ -246,8 +206,8 @@ func (u *UserStore) GetUsers(ctx context.Context) (*[]models.User, error) {
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return &users, nil
+	return &users, total, nil
 }
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+)
+
+type UserTokenStore struct {
+	db *sql.DB
+}
+
+const userTokenColumns = `id, user_id, purpose, token_hash, email, expires_at, used_at, created_at`
+
+func scanUserToken(row interface{ Scan(dest ...any) error }, t *models.UserToken) error {
+	return row.Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.Email, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+}
+
+// CreateToken stores a new token, dropping the user's unused tokens for the
+// same purpose so only the latest one mailed works
+func (u *UserTokenStore) CreateToken(ctx context.Context, token *models.UserToken) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, token.UserID, token.Purpose); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING ` + userTokenColumns
+
+	if err := scanUserToken(tx.QueryRowContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt), token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLatestToken returns the token most recently issued to a user for a purpose
+func (u *UserTokenStore) GetLatestToken(ctx context.Context, userID, purpose string) (*models.UserToken, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE user_id = $1 AND purpose = $2 ORDER BY created_at DESC LIMIT 1`
+
+	token := &models.UserToken{}
+	if err := scanUserToken(u.db.QueryRowContext(ctx, query, userID, purpose), token); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// VerifyEmail consumes an email verification token and marks the address it
+// was sent to as verified. It returns the ID of the verified user.
+func (u *UserTokenStore) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	token, err := consumeToken(ctx, tx, tokenHash, models.TokenEmailVerification)
+	if err != nil {
+		return "", err
+	}
+
+	// the user may have changed their email since the token was sent
+	res, err := tx.ExecContext(ctx, `UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $1 AND email = $2`, token.UserID, token.Email)
+	if err != nil {
+		return "", err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 0 {
+		return "", errs.ErrInvalidToken
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return token.UserID, nil
+}
+
+// ResetPassword consumes a password reset token, sets the user's new password
+// and revokes their refresh tokens so other sessions can't outlive the reset.
+// It returns the ID of the user.
+func (u *UserTokenStore) ResetPassword(ctx context.Context, tokenHash, password string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	token, err := consumeToken(ctx, tx, tokenHash, models.TokenPasswordReset)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2 AND email = $3`, password, token.UserID, token.Email)
+	if err != nil {
+		return "", err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 0 {
+		return "", errs.ErrInvalidToken
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, token.UserID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return token.UserID, nil
+}
+
+// GetActiveToken returns an unused, unexpired token. Anything else is reported
+// as errs.ErrInvalidToken.
+func (u *UserTokenStore) GetActiveToken(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+
+	token := &models.UserToken{}
+	if err := scanUserToken(u.db.QueryRowContext(ctx, query, tokenHash, purpose), token); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// UseToken marks a token as used. A token already used, for instance by a
+// concurrent request, returns errs.ErrInvalidToken.
+func (u *UserTokenStore) UseToken(ctx context.Context, id string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	res, err := u.db.ExecContext(ctx, `UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errs.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// FailTokenAttempt counts a wrong guess against a token and uses the token up
+// once maxAttempts guesses were wrong
+func (u *UserTokenStore) FailTokenAttempt(ctx context.Context, id string, maxAttempts int) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE user_tokens SET attempts = attempts + 1,
+			used_at = CASE WHEN attempts + 1 >= $2 THEN CURRENT_TIMESTAMP END
+		WHERE id = $1 AND used_at IS NULL`
+
+	_, err := u.db.ExecContext(ctx, query, id, maxAttempts)
+	return err
+}
+
+// consumeToken marks an unused, unexpired token as used. Anything else is
+// reported as errs.ErrInvalidToken so callers can't tell a wrong token from a
+// stale one.
+func consumeToken(ctx context.Context, tx *sql.Tx, tokenHash, purpose string) (*models.UserToken, error) {
+
+	token := &models.UserToken{}
+	if err := scanUserToken(tx.QueryRowContext(ctx, `SELECT `+userTokenColumns+` FROM user_tokens WHERE token_hash = $1 AND purpose = $2 FOR UPDATE`, tokenHash, purpose), token); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if !tokenUsable(token, time.Now()) {
+		return nil, errs.ErrInvalidToken
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1`, token.ID); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// tokenUsable reports whether a token is still unused and unexpired at now
+func tokenUsable(token *models.UserToken, now time.Time) bool {
+	return token.UsedAt == nil && now.Before(token.ExpiresAt)
+}
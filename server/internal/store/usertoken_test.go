@@ -0,0 +1,19 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenUsable(t *testing.T) {
+	now := time.Now()
+	used := now.Add(-time.Minute)
+
+	assert.True(t, tokenUsable(&models.UserToken{ExpiresAt: now.Add(time.Hour)}, now), "fresh token")
+	assert.False(t, tokenUsable(&models.UserToken{ExpiresAt: now.Add(time.Hour), UsedAt: &used}, now), "used token")
+	assert.False(t, tokenUsable(&models.UserToken{ExpiresAt: now.Add(-time.Second)}, now), "expired token")
+	assert.False(t, tokenUsable(&models.UserToken{ExpiresAt: now}, now), "token expiring now")
+}
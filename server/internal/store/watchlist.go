@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type WatchlistStore struct {
+	db *sql.DB
+}
+
+func (w *WatchlistStore) AddWatch(ctx context.Context, watch *models.Watch) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	// watching an auction twice only updates the price change preference
+	query := `INSERT INTO watchlist (user_id, auction_id, notify_price_change) VALUES ($1, $2, $3)
+ON CONFLICT (user_id, auction_id) DO UPDATE SET notify_price_change = EXCLUDED.notify_price_change
+RETURNING id, created_at`
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx, query, watch.UserID, watch.AuctionID, watch.NotifyPriceChange).Scan(&watch.ID, &watch.CreatedAt); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WatchlistStore) RemoveWatch(ctx context.Context, userID, auctionID string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `DELETE FROM watchlist WHERE user_id = $1 AND auction_id = $2`
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, userID, auctionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrWatchNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// watchedOrder lists watched auctions by how soon they end
+var watchedOrder = keyset{at: "a.end_time", id: "a.id", asc: true}
+
+func (w *WatchlistStore) GetWatchedAuctions(ctx context.Context, userID string, page *pagination.Params) (*[]models.Auction, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var total int
+	if err := w.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM watchlist WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := watchedOrder.page(`SELECT a.id, a.seller_id, a.winner_id, a.title, a.description, a.starting_price, a.current_price, a.type, a.status, a.start_time, a.end_time, a.image_path, a.category, a.is_paid, a.created_at
+FROM auctions a
+JOIN watchlist w ON a.id = w.auction_id
+WHERE w.user_id = $1`, []any{userID}, page)
+
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	auctions := []models.Auction{}
+
+	for rows.Next() {
+		var a models.Auction
+		if err := rows.Scan(&a.ID, &a.SellerID, &a.WinnerID, &a.Title, &a.Description, &a.StartingPrice, &a.CurrentPrice, &a.Type, &a.Status, &a.StartTime, &a.EndTime, &a.ImagePath, &a.Category, &a.IsPaid, &a.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		auctions = append(auctions, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return &auctions, total, nil
+}
+
+func (w *WatchlistStore) CountWatchers(ctx context.Context, auctionID string) (int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	var count int
+
+	query := `SELECT COUNT(*) FROM watchlist WHERE auction_id = $1`
+
+	if err := w.db.QueryRowContext(ctx, query, auctionID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (w *WatchlistStore) GetPriceChangeWatchers(ctx context.Context, auctionID string) ([]string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT user_id FROM watchlist WHERE auction_id = $1 AND notify_price_change = TRUE`
+
+	rows, err := w.db.QueryContext(ctx, query, auctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var watchers []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return watchers, nil
+}
+
+// GetEndingWatches returns the watches on open auctions ending within the
+// given time, with the lead times each was already reminded for
+func (w *WatchlistStore) GetEndingWatches(ctx context.Context, within time.Duration) ([]*models.WatchReminder, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `SELECT w.id, w.user_id, a.id, a.title, a.end_time,
+  ARRAY(SELECT r.lead_time_seconds FROM watchlist_reminder r WHERE r.watchlist_id = w.id)
+FROM watchlist w
+JOIN auctions a ON a.id = w.auction_id
+WHERE a.status = 'open'
+  AND a.end_time > NOW()
+  AND a.end_time <= NOW() + make_interval(secs => $1)`
+
+	rows, err := w.db.QueryContext(ctx, query, int(within.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var reminders []*models.WatchReminder
+	for rows.Next() {
+		r := &models.WatchReminder{}
+		var sent []int64
+		if err := rows.Scan(&r.WatchID, &r.UserID, &r.AuctionID, &r.Title, &r.EndTime, pq.Array(&sent)); err != nil {
+			return nil, err
+		}
+		for _, seconds := range sent {
+			r.SentLeadTimes = append(r.SentLeadTimes, time.Duration(seconds)*time.Second)
+		}
+		reminders = append(reminders, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+func (w *WatchlistStore) MarkReminderSent(ctx context.Context, watchID string, leadTime time.Duration) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO watchlist_reminder (watchlist_id, lead_time_seconds) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	if _, err := w.db.ExecContext(ctx, query, watchID, int(leadTime.Seconds())); err != nil {
+		return err
+	}
+
+	return nil
+}
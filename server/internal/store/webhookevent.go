@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/errs"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/pagination"
+)
+
+type WebhookEventStore struct {
+	db *sql.DB
+}
+
+const webhookEventColumns = `id, provider, event_id, event_type, payload, status, error, attempts, received_at, processed_at, updated_at`
+
+var receivedDesc = keyset{at: "received_at", id: "id"}
+
+func scanWebhookEvent(row interface{ Scan(dest ...any) error }, e *models.WebhookEvent) error {
+	return row.Scan(&e.ID, &e.Provider, &e.EventID, &e.EventType, &e.Payload, &e.Status, &e.Error, &e.Attempts, &e.ReceivedAt, &e.ProcessedAt, &e.UpdatedAt)
+}
+
+// ClaimWebhookEvent records a delivery and claims it for processing. A delivery
+// whose event was already processed or ignored, or is being processed right
+// now, is a duplicate and is not claimed. Failed events, and events stuck in
+// processing for longer than staleAfter, are claimed again.
+func (w *WebhookEventStore) ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent, staleAfter time.Duration) (bool, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `INSERT INTO webhook_events (provider, event_id, event_type, payload) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, event_id) DO UPDATE
+		SET status = 'processing', error = '', attempts = webhook_events.attempts + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE webhook_events.status = 'failed'
+			OR (webhook_events.status = 'processing' AND webhook_events.updated_at < CURRENT_TIMESTAMP - make_interval(secs => $5))
+		RETURNING ` + webhookEventColumns
+
+	err := scanWebhookEvent(w.db.QueryRowContext(ctx, query, event.Provider, event.EventID, event.EventType, event.Payload, staleAfter.Seconds()), event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ClaimWebhookEventReplay claims a failed event for another processing attempt
+func (w *WebhookEventStore) ClaimWebhookEventReplay(ctx context.Context, id string) (*models.WebhookEvent, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE webhook_events SET status = 'processing', error = '', attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'failed' RETURNING ` + webhookEventColumns
+
+	event := &models.WebhookEvent{}
+	if err := scanWebhookEvent(w.db.QueryRowContext(ctx, query, id), event); err != nil {
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		var exists bool
+		if err := w.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM webhook_events WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errs.ErrWebhookEventNotFound
+		}
+		return nil, errs.ErrWebhookEventNotReplayable
+	}
+
+	return event, nil
+}
+
+func (w *WebhookEventStore) FinishWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	query := `UPDATE webhook_events SET status = $1, error = $2, processed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 RETURNING processed_at, updated_at`
+
+	return w.db.QueryRowContext(ctx, query, event.Status, event.Error, event.ID).Scan(&event.ProcessedAt, &event.UpdatedAt)
+}
+
+// GetWebhookEvents lists events newest first, optionally only those in the given status
+func (w *WebhookEventStore) GetWebhookEvents(ctx context.Context, status string, page *pagination.Params) ([]*models.WebhookEvent, int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, QueryBackgroundTimeout)
+	defer cancel()
+
+	where := ` WHERE ($1 = '' OR status = $1)`
+
+	var total int
+	if err := w.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_events`+where, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := receivedDesc.page(`SELECT `+webhookEventColumns+` FROM webhook_events`+where, []any{status}, page)
+
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	events := []*models.WebhookEvent{}
+	for rows.Next() {
+		event := &models.WebhookEvent{}
+		if err := scanWebhookEvent(rows, event); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,156 @@
+// Package tax computes the sales tax / VAT charged at checkout from a rules
+// file. Each rule that matches the buyer, the seller and the auction category
+// adds its own tax line, so e.g. a state and a county tax can both apply.
+package tax
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Rule taxes sales to buyers (and optionally from sellers) in the given
+// regions. A region matches a location if it equals the whole location or one
+// of its comma separated parts, ignoring case: "germany" matches
+// "Berlin, Germany". Empty lists match everything.
+type Rule struct {
+	Name             string   `json:"name"`
+	Rate             float64  `json:"rate"` // percentage, e.g. 19 for 19%
+	BuyerRegions     []string `json:"buyer_regions"`
+	SellerRegions    []string `json:"seller_regions"`
+	Categories       []string `json:"categories"`
+	ExemptCategories []string `json:"exempt_categories"`
+	TaxFees          bool     `json:"tax_fees"` // whether the buyer fee is taxed too
+}
+
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Sale is what a checkout is taxed on. Amounts are in cents.
+type Sale struct {
+	BuyerLocation  string
+	SellerLocation string
+	Category       string
+	Price          int64
+	Fee            int64
+}
+
+// Line is the tax of one rule, in cents
+type Line struct {
+	Name   string
+	Rate   float64
+	Amount int64
+}
+
+// Engine applies a set of rules. A nil or zero Engine charges no tax.
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules Rules) (*Engine, error) {
+	for i, rule := range rules.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("tax rule %d has no name", i)
+		}
+		if rule.Rate < 0 || rule.Rate > 100 {
+			return nil, fmt.Errorf("tax rule %q has invalid rate %v", rule.Name, rule.Rate)
+		}
+	}
+
+	return &Engine{rules: rules.Rules}, nil
+}
+
+// Load reads the rules from a JSON file. Without a file no tax is charged.
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		return &Engine{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rules: %w", err)
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse tax rules %s: %w", path, err)
+	}
+
+	return NewEngine(rules)
+}
+
+// Calculate returns the tax lines of a sale, in the order of the rules
+func (e *Engine) Calculate(sale Sale) []Line {
+	lines := []Line{}
+	if e == nil {
+		return lines
+	}
+
+	for _, rule := range e.rules {
+		if !rule.applies(sale) {
+			continue
+		}
+
+		base := sale.Price
+		if rule.TaxFees {
+			base += sale.Fee
+		}
+
+		amount := int64(math.Round(float64(base) * rule.Rate / 100))
+		if amount > 0 {
+			lines = append(lines, Line{Name: rule.Name, Rate: rule.Rate, Amount: amount})
+		}
+	}
+
+	return lines
+}
+
+// Total sums the amounts of tax lines
+func Total(lines []Line) int64 {
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
+
+func (r Rule) applies(sale Sale) bool {
+	category := normalize(sale.Category)
+
+	if slices.ContainsFunc(r.ExemptCategories, func(c string) bool { return normalize(c) == category }) {
+		return false
+	}
+
+	if len(r.Categories) > 0 && !slices.ContainsFunc(r.Categories, func(c string) bool { return normalize(c) == category }) {
+		return false
+	}
+
+	return inRegions(sale.BuyerLocation, r.BuyerRegions) && inRegions(sale.SellerLocation, r.SellerRegions)
+}
+
+func inRegions(location string, regions []string) bool {
+	if len(regions) == 0 {
+		return true
+	}
+
+	parts := []string{normalize(location)}
+	for _, part := range strings.Split(location, ",") {
+		parts = append(parts, normalize(part))
+	}
+
+	for _, region := range regions {
+		if region := normalize(region); region != "" && slices.Contains(parts, region) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
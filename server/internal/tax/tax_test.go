@@ -0,0 +1,61 @@
+package tax
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEngine(t *testing.T) *Engine {
+	engine, err := NewEngine(Rules{Rules: []Rule{
+		{Name: "German VAT", Rate: 19, BuyerRegions: []string{"Germany", "DE"}, ExemptCategories: []string{"books"}, TaxFees: true},
+		{Name: "California sales tax", Rate: 7.25, BuyerRegions: []string{"California", "CA"}, Categories: []string{"electronics", "art"}},
+		{Name: "Los Angeles county tax", Rate: 2.25, BuyerRegions: []string{"Los Angeles"}, Categories: []string{"electronics", "art"}},
+	}})
+	require.NoError(t, err)
+	return engine
+}
+
+func TestCalculateMatchesBuyerRegion(t *testing.T) {
+	lines := testEngine(t).Calculate(Sale{BuyerLocation: "Berlin, germany", Category: "Art", Price: 10000, Fee: 500})
+
+	require.Len(t, lines, 1)
+	assert.Equal(t, Line{Name: "German VAT", Rate: 19, Amount: 1995}, lines[0], "VAT is charged on price and fee")
+}
+
+func TestCalculateStacksRules(t *testing.T) {
+	lines := testEngine(t).Calculate(Sale{BuyerLocation: "Los Angeles, CA", Category: "electronics", Price: 10000, Fee: 500})
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, int64(725), lines[0].Amount, "fee is not taxed")
+	assert.Equal(t, int64(225), lines[1].Amount)
+	assert.Equal(t, int64(950), Total(lines))
+}
+
+func TestCalculateCategories(t *testing.T) {
+	engine := testEngine(t)
+
+	assert.Empty(t, engine.Calculate(Sale{BuyerLocation: "Munich, Germany", Category: "Books", Price: 10000}), "exempt category")
+	assert.Empty(t, engine.Calculate(Sale{BuyerLocation: "San Diego, California", Category: "furniture", Price: 10000}), "category not listed")
+	assert.Empty(t, engine.Calculate(Sale{BuyerLocation: "Lagos, Nigeria", Category: "art", Price: 10000}), "no rule for region")
+}
+
+func TestLoad(t *testing.T) {
+	engine, err := Load("")
+	require.NoError(t, err)
+	assert.Empty(t, engine.Calculate(Sale{BuyerLocation: "Germany", Price: 10000}), "no rules file, no tax")
+
+	path := filepath.Join(t.TempDir(), "tax.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"name": "VAT", "rate": 20, "buyer_regions": ["UK"]}]}`), 0o600))
+
+	engine, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Line{{Name: "VAT", Rate: 20, Amount: 2000}}, engine.Calculate(Sale{BuyerLocation: "London, UK", Price: 10000}))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"name": "VAT", "rate": 120}]}`), 0o600))
+	_, err = Load(path)
+	assert.Error(t, err, "rates above 100% are rejected")
+}
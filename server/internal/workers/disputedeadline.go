@@ -0,0 +1,48 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+// DisputeDeadline periodically sends disputes whose buyer or seller let a
+// deadline pass to an admin, so a dispute can't stall on an unresponsive party
+type DisputeDeadline struct {
+	app     *config.Application
+	service services.DisputeServiceInterface
+}
+
+func NewDisputeDeadline(app *config.Application, service services.DisputeServiceInterface) *DisputeDeadline {
+	return &DisputeDeadline{
+		app:     app,
+		service: service,
+	}
+}
+
+func (d *DisputeDeadline) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.app.AppConfig.DisputeConf.DeadlineInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.escalate(ctx)
+		}
+	}
+}
+
+func (d *DisputeDeadline) escalate(ctx context.Context) {
+	escalated, err := d.service.EscalateOverdueDisputes(ctx)
+	if err != nil {
+		d.app.Logger.Errorw("failed to escalate overdue disputes", "error", err)
+	}
+
+	if escalated > 0 {
+		d.app.Logger.Infow("escalated overdue disputes", "escalated", escalated)
+	}
+}
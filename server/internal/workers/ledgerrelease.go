@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// LedgerRelease periodically makes held seller funds available: those of
+// completed orders whose release failed, and those without an order once their
+// hold period has passed.
+type LedgerRelease struct {
+	app *config.Application
+}
+
+func NewLedgerRelease(app *config.Application) *LedgerRelease {
+	return &LedgerRelease{
+		app: app,
+	}
+}
+
+func (l *LedgerRelease) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.app.AppConfig.LedgerConf.ReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.releaseDue(ctx)
+		}
+	}
+}
+
+func (l *LedgerRelease) releaseDue(ctx context.Context) {
+	released, err := l.app.Store.Ledger.ReleaseDue(ctx)
+	if err != nil {
+		l.app.Logger.Errorw("failed to release held seller funds", "error", err)
+		return
+	}
+
+	for _, entry := range released {
+		var auctionID string
+		if entry.AuctionID != nil {
+			auctionID = *entry.AuctionID
+		}
+
+		message := fmt.Sprintf("$%.2f from your sale is now available for payout", entry.Amount)
+
+		l.app.WsHub.NotificationUpdates <- &models.NotificationEvent{
+			Type:      models.NotificationFundsAvailable,
+			UserID:    entry.SellerID,
+			Message:   message,
+			AuctionID: auctionID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    entry.SellerID,
+			Message:   message,
+			AuctionID: auctionID,
+			IsRead:    false,
+		}
+		if err := l.app.Store.Notifications.CreateNotification(ctx, not); err != nil {
+			l.app.Logger.Errorw("failed to store funds available notification", "entryId", entry.ID, "error", err)
+		}
+	}
+}
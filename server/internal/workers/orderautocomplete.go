@@ -0,0 +1,51 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+// OrderAutoComplete periodically completes orders that were shipped or
+// delivered long ago without the buyer confirming or disputing them, so the
+// seller isn't left waiting on an unresponsive buyer
+type OrderAutoComplete struct {
+	app     *config.Application
+	service services.OrderServiceInterface
+}
+
+func NewOrderAutoComplete(app *config.Application, service services.OrderServiceInterface) *OrderAutoComplete {
+	return &OrderAutoComplete{
+		app:     app,
+		service: service,
+	}
+}
+
+func (o *OrderAutoComplete) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.app.AppConfig.OrderConf.AutoCompleteInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.autoComplete(ctx)
+		}
+	}
+}
+
+func (o *OrderAutoComplete) autoComplete(ctx context.Context) {
+	conf := o.app.AppConfig.OrderConf
+
+	completed, err := o.service.AutoCompleteOrders(ctx, conf.ShippedAutoComplete, conf.DeliveredAutoComplete)
+	if err != nil {
+		o.app.Logger.Errorw("failed to auto-complete orders", "error", err)
+	}
+
+	if completed > 0 {
+		o.app.Logger.Infow("auto-completed orders", "completed", completed)
+	}
+}
@@ -0,0 +1,50 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/services"
+)
+
+// PaymentReconciler periodically checks pending payments against the payment
+// provider, so that payments whose webhook was lost don't stay pending forever
+type PaymentReconciler struct {
+	app     *config.Application
+	service services.PaymentServiceInterface
+}
+
+func NewPaymentReconciler(app *config.Application, service services.PaymentServiceInterface) *PaymentReconciler {
+	return &PaymentReconciler{
+		app:     app,
+		service: service,
+	}
+}
+
+func (r *PaymentReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.app.AppConfig.ReconcileConf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reconcile(ctx)
+		}
+	}
+}
+
+func (r *PaymentReconciler) reconcile(ctx context.Context) {
+	conf := r.app.AppConfig.ReconcileConf
+
+	report, err := r.service.ReconcilePayments(ctx, conf.PendingAge, conf.AbandonAfter)
+	if err != nil {
+		r.app.Logger.Errorw("failed to reconcile payments", "error", err)
+	}
+
+	if report != nil && len(report.Mismatches) > 0 {
+		r.app.Logger.Warnw("payment reconciliation found mismatches", "checked", report.Checked, "mismatches", len(report.Mismatches))
+	}
+}
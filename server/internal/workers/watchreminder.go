@@ -0,0 +1,112 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/config"
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/puremike/online_auction_api/internal/store"
+)
+
+// WatchReminder periodically reminds watchers that an auction is about to end.
+// Each configured lead time is sent at most once per watch, and a watch made
+// after some lead times passed only gets the reminder of the shortest due one.
+type WatchReminder struct {
+	app *config.Application
+}
+
+func NewWatchReminder(app *config.Application) *WatchReminder {
+	return &WatchReminder{
+		app: app,
+	}
+}
+
+func (w *WatchReminder) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.app.AppConfig.WatchlistConf.ReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sendDueReminders(ctx)
+		}
+	}
+}
+
+func (w *WatchReminder) sendDueReminders(ctx context.Context) {
+
+	leadTimes := w.app.AppConfig.WatchlistConf.ReminderLeadTimes
+	if len(leadTimes) == 0 {
+		return
+	}
+
+	watches, err := w.app.Store.Watchlist.GetEndingWatches(ctx, slices.Max(leadTimes))
+	if err != nil {
+		w.app.Logger.Errorw("failed to get ending watches", "error", err)
+		return
+	}
+
+	for _, due := range dueReminders(watches, leadTimes, time.Now()) {
+		r := due.watch
+		message := fmt.Sprintf("Auction %s you are watching ends in %s", r.Title, time.Until(r.EndTime).Round(time.Minute))
+
+		w.app.WsHub.NotificationUpdates <- &models.NotificationEvent{
+			Type:      models.NotificationReminder,
+			UserID:    r.UserID,
+			Message:   message,
+			AuctionID: r.AuctionID,
+			TimeStamp: time.Now(),
+		}
+
+		not := &store.Notification{
+			UserID:    r.UserID,
+			Message:   fmt.Sprintf("%s, auctionId: %s", message, r.AuctionID),
+			AuctionID: r.AuctionID,
+			IsRead:    false,
+		}
+		if err := w.app.Store.Notifications.CreateNotification(ctx, not); err != nil {
+			w.app.Logger.Errorw("failed to store watchlist reminder", "watchId", r.WatchID, "error", err)
+		}
+
+		if err := w.app.Store.Watchlist.MarkReminderSent(ctx, r.WatchID, due.leadTime); err != nil {
+			w.app.Logger.Errorw("failed to mark watchlist reminder as sent", "watchId", r.WatchID, "error", err)
+		}
+	}
+}
+
+// dueReminder is a watch due the reminder of leadTime
+type dueReminder struct {
+	watch    *models.WatchReminder
+	leadTime time.Duration
+}
+
+// dueReminders picks the reminders due at now. A watch is due the reminder of
+// the shortest lead time its auction ends within, unless that one was sent
+// already, so a late watcher gets one reminder instead of every one they missed.
+func dueReminders(watches []*models.WatchReminder, leadTimes []time.Duration, now time.Time) []dueReminder {
+
+	leadTimes = slices.Clone(leadTimes)
+	slices.Sort(leadTimes)
+
+	due := []dueReminder{}
+	for _, watch := range watches {
+		untilEnd := watch.EndTime.Sub(now)
+		if untilEnd <= 0 {
+			continue
+		}
+
+		i := slices.IndexFunc(leadTimes, func(leadTime time.Duration) bool { return untilEnd <= leadTime })
+		if i < 0 || slices.Contains(watch.SentLeadTimes, leadTimes[i]) {
+			continue
+		}
+
+		due = append(due, dueReminder{watch: watch, leadTime: leadTimes[i]})
+	}
+
+	return due
+}
@@ -0,0 +1,48 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/puremike/online_auction_api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueReminders(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	leadTimes := []time.Duration{time.Hour, 24 * time.Hour, 15 * time.Minute, time.Hour}
+
+	watch := func(id string, untilEnd time.Duration, sent ...time.Duration) *models.WatchReminder {
+		return &models.WatchReminder{WatchID: id, EndTime: now.Add(untilEnd), SentLeadTimes: sent}
+	}
+
+	tests := []struct {
+		name     string
+		watch    *models.WatchReminder
+		leadTime time.Duration // 0 when no reminder is due
+	}{
+		{"not yet within the longest lead time", watch("w1", 25*time.Hour), 0},
+		{"within the longest lead time", watch("w2", 23*time.Hour), 24 * time.Hour},
+		{"exactly at a lead time", watch("w3", time.Hour), time.Hour},
+		{"already reminded for the lead time", watch("w4", 23*time.Hour, 24*time.Hour), 0},
+		{"next lead time after an earlier reminder", watch("w5", 30*time.Minute, 24*time.Hour), time.Hour},
+		{"late watcher gets only the shortest due reminder", watch("w6", 10*time.Minute), 15 * time.Minute},
+		{"late watcher reminded once", watch("w7", 5*time.Minute, 15*time.Minute), 0},
+		{"auction already ended", watch("w8", -time.Minute), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due := dueReminders([]*models.WatchReminder{tt.watch}, leadTimes, now)
+
+			if tt.leadTime == 0 {
+				assert.Empty(t, due)
+				return
+			}
+			if assert.Len(t, due, 1) {
+				assert.Equal(t, tt.watch, due[0].watch)
+				assert.Equal(t, tt.leadTime, due[0].leadTime)
+			}
+		})
+	}
+}
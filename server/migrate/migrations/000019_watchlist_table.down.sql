@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS watchlist_reminder;
+DROP TABLE IF EXISTS watchlist;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    auction_id UUID NOT NULL,
+    notify_price_change BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, auction_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_auction_id ON watchlist(auction_id);
+
+-- one row per reminder already delivered, so the worker never sends the same lead time twice
+CREATE TABLE IF NOT EXISTS watchlist_reminder (
+    watchlist_id UUID NOT NULL,
+    lead_time_seconds INTEGER NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (watchlist_id, lead_time_seconds),
+    FOREIGN KEY (watchlist_id) REFERENCES watchlist(id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS saved_search (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name VARCHAR NOT NULL,
+    type VARCHAR NOT NULL DEFAULT '',
+    category VARCHAR NOT NULL DEFAULT '',
+    status VARCHAR NOT NULL DEFAULT '',
+    min_price NUMERIC NOT NULL DEFAULT 0,
+    max_price NUMERIC NOT NULL DEFAULT 0,
+    keyword VARCHAR NOT NULL DEFAULT '',
+    notify_new_listings BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_auctions_search_vector;
+
+ALTER TABLE auctions
+DROP COLUMN IF EXISTS search_vector;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_pending_auction;
+
+ALTER TABLE payment
+DROP COLUMN IF EXISTS fee;
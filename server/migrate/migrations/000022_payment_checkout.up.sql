@@ -0,0 +1,15 @@
+ALTER TABLE payment
+ADD COLUMN fee NUMERIC NOT NULL DEFAULT 0;
+
+-- keep only the newest pending checkout per auction before enforcing uniqueness
+UPDATE payment p
+SET status = 'failed', updated_at = CURRENT_TIMESTAMP
+WHERE p.status = 'pending'
+  AND EXISTS (
+    SELECT 1 FROM payment n
+    WHERE n.auction_id = p.auction_id
+      AND n.status = 'pending'
+      AND n.created_at > p.created_at
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_pending_auction ON payment (auction_id) WHERE status = 'pending';
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS payment_refund;
+
+DROP INDEX IF EXISTS idx_payment_intent_id;
+
+ALTER TABLE payment
+DROP COLUMN IF EXISTS refunded_amount,
+DROP COLUMN IF EXISTS payment_intent_id;
+
+UPDATE payment SET status = 'completed' WHERE status IN ('refunded', 'partially_refunded');
+
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_status_check;
+ALTER TABLE payment
+ADD CONSTRAINT payment_status_check CHECK (status IN ('pending', 'completed', 'failed'));
@@ -0,0 +1,26 @@
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_status_check;
+ALTER TABLE payment
+ADD CONSTRAINT payment_status_check CHECK (status IN ('pending', 'completed', 'failed', 'refunded', 'partially_refunded'));
+
+ALTER TABLE payment
+ADD COLUMN refunded_amount NUMERIC NOT NULL DEFAULT 0,
+ADD COLUMN payment_intent_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_payment_intent_id ON payment(payment_intent_id);
+
+-- pending refunds reserve their amount on the payment before the provider is called
+CREATE TABLE IF NOT EXISTS payment_refund (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL,
+    provider_refund_id VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    requested_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (payment_id) REFERENCES payment(id) ON DELETE CASCADE,
+    FOREIGN KEY (requested_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refund_payment_id ON payment_refund(payment_id);
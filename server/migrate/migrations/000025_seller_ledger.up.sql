@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS payout (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL,
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed')),
+    provider VARCHAR(32) NOT NULL,
+    provider_payout_id VARCHAR(255) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_seller_id ON payout(seller_id, created_at DESC);
+
+-- append-only record of what each seller is owed. Sale entries (and refund
+-- entries against them) are held until the order completes, or until the hold
+-- period passes for payments without an order; payouts are debited from the
+-- available balance.
+CREATE TABLE IF NOT EXISTS ledger_entry (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('sale', 'refund', 'payout', 'payout_reversal')),
+    amount NUMERIC NOT NULL,
+    gross NUMERIC NOT NULL DEFAULT 0,
+    platform_fee NUMERIC NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('held', 'available')),
+    payment_id UUID,
+    auction_id UUID,
+    refund_id UUID,
+    payout_id UUID,
+    available_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (payment_id) REFERENCES payment(id) ON DELETE SET NULL,
+    FOREIGN KEY (refund_id) REFERENCES payment_refund(id) ON DELETE SET NULL,
+    FOREIGN KEY (payout_id) REFERENCES payout(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entry_seller_id ON ledger_entry(seller_id, status);
+CREATE INDEX IF NOT EXISTS idx_ledger_entry_held ON ledger_entry(available_at) WHERE status = 'held';
+-- one sale entry per payment and one entry per refund, so crediting and debiting can be retried safely
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_entry_sale ON ledger_entry(payment_id) WHERE kind = 'sale';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_entry_refund ON ledger_entry(refund_id) WHERE refund_id IS NOT NULL;
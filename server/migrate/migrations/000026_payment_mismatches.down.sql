@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_pending_created;
+
+DROP TABLE IF EXISTS payment_mismatch;
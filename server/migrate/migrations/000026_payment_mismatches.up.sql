@@ -0,0 +1,16 @@
+-- differences the reconciliation worker found between local payments and the provider, and what it did about them
+CREATE TABLE IF NOT EXISTS payment_mismatch (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    session_id VARCHAR(255) NOT NULL DEFAULT '',
+    local_status VARCHAR(50) NOT NULL,
+    provider_status VARCHAR(50) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL CHECK (action IN ('completed', 'failed', 'expired', 'is_paid_fixed', 'unresolved')),
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_mismatch_created ON payment_mismatch(created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_payment_pending_created ON payment(created_at) WHERE status = 'pending';
@@ -0,0 +1,32 @@
+-- last invoice number issued per year; incremented in the transaction that issues the invoice so numbers have no gaps
+CREATE TABLE IF NOT EXISTS invoice_counter (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+-- invoices of completed payments; parties, lines and totals are copied so the invoice doesn't change with later edits
+CREATE TABLE IF NOT EXISTS invoice (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    number VARCHAR(32) NOT NULL UNIQUE,
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL,
+    payment_id UUID NOT NULL UNIQUE REFERENCES payment(id) ON DELETE RESTRICT,
+    auction_id UUID NOT NULL,
+    order_id VARCHAR(255) NOT NULL,
+    buyer_id UUID NOT NULL,
+    buyer_name VARCHAR(255) NOT NULL DEFAULT '',
+    buyer_email VARCHAR(255) NOT NULL DEFAULT '',
+    seller_id UUID NOT NULL,
+    seller_name VARCHAR(255) NOT NULL DEFAULT '',
+    seller_email VARCHAR(255) NOT NULL DEFAULT '',
+    auction_title VARCHAR(255) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    lines JSONB NOT NULL,
+    subtotal NUMERIC NOT NULL,
+    fees NUMERIC NOT NULL DEFAULT 0,
+    tax NUMERIC NOT NULL DEFAULT 0,
+    total NUMERIC NOT NULL,
+    payment_reference VARCHAR(255) NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (year, sequence)
+);
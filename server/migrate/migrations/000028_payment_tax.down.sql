@@ -0,0 +1,3 @@
+ALTER TABLE payment
+DROP COLUMN IF EXISTS tax_lines,
+DROP COLUMN IF EXISTS tax;
@@ -0,0 +1,4 @@
+-- tax charged at checkout on top of the price and buyer fee, with the line of every rule that applied
+ALTER TABLE payment
+ADD COLUMN IF NOT EXISTS tax NUMERIC NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS tax_lines JSONB NOT NULL DEFAULT '[]';
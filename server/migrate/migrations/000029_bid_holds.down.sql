@@ -0,0 +1,7 @@
+ALTER TABLE payment
+DROP COLUMN IF EXISTS deposit;
+
+DROP TABLE IF EXISTS bid_hold;
+
+ALTER TABLE auctions
+DROP COLUMN IF EXISTS hold_threshold;
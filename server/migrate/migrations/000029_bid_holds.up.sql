@@ -0,0 +1,27 @@
+-- bids at or above the threshold need an authorized card hold on the auction (0 = no holds)
+ALTER TABLE auctions
+ADD COLUMN IF NOT EXISTS hold_threshold NUMERIC NOT NULL DEFAULT 0;
+
+-- card pre-authorizations taken from bidders. The winner's hold is captured
+-- when the auction closes and deducted at checkout; the others are released.
+CREATE TABLE IF NOT EXISTS bid_hold (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL,
+    bidder_id UUID NOT NULL,
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    provider VARCHAR(32) NOT NULL,
+    provider_hold_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'authorized', 'captured', 'released')),
+    authorized_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE,
+    FOREIGN KEY (bidder_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- one live hold per bidder and auction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bid_hold_live ON bid_hold(auction_id, bidder_id) WHERE status <> 'released';
+
+-- captured hold deducted from the checkout total
+ALTER TABLE payment
+ADD COLUMN IF NOT EXISTS deposit NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+ALTER TABLE invoice
+DROP COLUMN IF EXISTS shipping;
+
+ALTER TABLE payment
+DROP COLUMN IF EXISTS shipping,
+DROP COLUMN IF EXISTS shipping_method;
+
+DROP TABLE IF EXISTS auction_shipping;
+
+ALTER TABLE auctions
+DROP COLUMN IF EXISTS shipping_options,
+DROP COLUMN IF EXISTS weight_kg;
+
+DROP TABLE IF EXISTS address;
@@ -0,0 +1,49 @@
+-- a user's saved shipping addresses
+CREATE TABLE IF NOT EXISTS address (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    label VARCHAR(32) NOT NULL DEFAULT '',
+    full_name VARCHAR(100) NOT NULL,
+    line1 VARCHAR(200) NOT NULL,
+    line2 VARCHAR(200) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(20) NOT NULL DEFAULT '',
+    country VARCHAR(56) NOT NULL,
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_address_user_created ON address(user_id, created_at DESC, id DESC);
+
+-- at most one default address per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_address_default ON address(user_id) WHERE is_default;
+
+-- shipping methods offered by the seller and the item's weight for weight-based rates
+ALTER TABLE auctions
+ADD COLUMN IF NOT EXISTS shipping_options JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN IF NOT EXISTS weight_kg NUMERIC NOT NULL DEFAULT 0;
+
+-- how the winner wants the item delivered, with a copy of the address chosen
+CREATE TABLE IF NOT EXISTS auction_shipping (
+    auction_id UUID PRIMARY KEY,
+    buyer_id UUID NOT NULL,
+    method VARCHAR(16) NOT NULL CHECK (method IN ('flat', 'free', 'pickup', 'weight')),
+    cost NUMERIC NOT NULL DEFAULT 0 CHECK (cost >= 0),
+    address JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE,
+    FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- shipping charged at checkout, passed on to the seller without fees
+ALTER TABLE payment
+ADD COLUMN IF NOT EXISTS shipping NUMERIC NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE invoice
+ADD COLUMN IF NOT EXISTS shipping NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,30 @@
+-- fulfilment of a paid auction, keyed by the checkout order id of its payment
+CREATE TABLE IF NOT EXISTS orders (
+    id TEXT PRIMARY KEY,
+    payment_id UUID NOT NULL UNIQUE,
+    auction_id UUID NOT NULL,
+    buyer_id UUID NOT NULL,
+    seller_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_shipment' CHECK (status IN ('awaiting_shipment', 'shipped', 'delivered', 'completed', 'disputed')),
+    shipping_method VARCHAR(16) NOT NULL DEFAULT '',
+    carrier VARCHAR(64) NOT NULL DEFAULT '',
+    tracking_number VARCHAR(64) NOT NULL DEFAULT '',
+    dispute_reason TEXT NOT NULL DEFAULT '',
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    disputed_at TIMESTAMP WITH TIME ZONE,
+    status_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (payment_id) REFERENCES payment(id) ON DELETE CASCADE,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE,
+    FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_buyer_created ON orders(buyer_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_seller_created ON orders(seller_id, created_at DESC, id DESC);
+
+-- auto-completion scans orders that have sat in a status too long
+CREATE INDEX IF NOT EXISTS idx_orders_status_changed ON orders(status, status_changed_at);
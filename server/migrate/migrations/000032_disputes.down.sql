@@ -0,0 +1,13 @@
+-- cancelled orders were refunded in full, the closest old status is completed
+UPDATE orders SET status = 'completed' WHERE status = 'cancelled';
+
+ALTER TABLE orders
+DROP CONSTRAINT IF EXISTS orders_status_check,
+ADD CONSTRAINT orders_status_check CHECK (status IN ('awaiting_shipment', 'shipped', 'delivered', 'completed', 'disputed'));
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS dispute_reason TEXT NOT NULL DEFAULT '';
+
+DROP TABLE IF EXISTS dispute_message;
+
+DROP TABLE IF EXISTS disputes;
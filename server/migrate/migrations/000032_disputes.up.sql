@@ -0,0 +1,58 @@
+-- a buyer's claim that something went wrong with an order, arbitrated by an admin
+-- when buyer and seller can't settle it themselves
+CREATE TABLE IF NOT EXISTS disputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id TEXT NOT NULL UNIQUE,
+    auction_id UUID NOT NULL,
+    buyer_id UUID NOT NULL,
+    seller_id UUID NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('not_received', 'not_as_described', 'damaged', 'counterfeit', 'other')),
+    description TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'under_review', 'awaiting_return', 'return_shipped', 'refunded', 'rejected')),
+    -- who has to act by when depends on the status, see models.Dispute
+    deadline TIMESTAMP WITH TIME ZONE,
+    return_carrier VARCHAR(64) NOT NULL DEFAULT '',
+    return_tracking_number VARCHAR(64) NOT NULL DEFAULT '',
+    refund_amount NUMERIC NOT NULL DEFAULT 0,
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_by UUID,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE,
+    FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disputes_buyer_created ON disputes(buyer_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_disputes_seller_created ON disputes(seller_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_disputes_status_created ON disputes(status, created_at DESC, id DESC);
+
+-- the escalation worker scans disputes whose deadline has passed
+CREATE INDEX IF NOT EXISTS idx_disputes_deadline ON disputes(deadline) WHERE deadline IS NOT NULL;
+
+-- the thread of a dispute: messages, evidence images and status changes
+CREATE TABLE IF NOT EXISTS dispute_message (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dispute_id UUID NOT NULL,
+    sender_id UUID,
+    sender_role VARCHAR(10) NOT NULL CHECK (sender_role IN ('buyer', 'seller', 'admin', 'system')),
+    body TEXT NOT NULL DEFAULT '',
+    image_path TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (dispute_id) REFERENCES disputes(id) ON DELETE CASCADE,
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_message_dispute_created ON dispute_message(dispute_id, created_at, id);
+
+-- disputes replace the free-text reason on the order, and a fully refunded
+-- dispute cancels its order
+ALTER TABLE orders
+DROP COLUMN IF EXISTS dispute_reason;
+
+ALTER TABLE orders
+DROP CONSTRAINT IF EXISTS orders_status_check,
+ADD CONSTRAINT orders_status_check CHECK (status IN ('awaiting_shipment', 'shipped', 'delivered', 'completed', 'disputed', 'cancelled'));
@@ -0,0 +1,24 @@
+-- a rating the buyer and the seller of a completed order leave each other
+CREATE TABLE IF NOT EXISTS feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id TEXT NOT NULL,
+    auction_id UUID NOT NULL,
+    from_user_id UUID NOT NULL,
+    to_user_id UUID NOT NULL,
+    -- the part the rated user played in the order
+    role VARCHAR(6) NOT NULL CHECK (role IN ('buyer', 'seller')),
+    rating VARCHAR(8) NOT NULL CHECK (rating IN ('positive', 'neutral', 'negative')),
+    comment TEXT NOT NULL DEFAULT '',
+    reply TEXT NOT NULL DEFAULT '',
+    replied_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, from_user_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- feedback received, listed per role and tallied into reputation scores
+CREATE INDEX IF NOT EXISTS idx_feedback_to_user_role_created ON feedback(to_user_id, role, created_at DESC, id DESC);
@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- accounts created before verification existed keep working
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- single-use tokens mailed to a user. Only the SHA-256 of the token is kept,
+-- along with the address it was sent to, so a token stops working once the
+-- user changes their email.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    purpose VARCHAR(32) NOT NULL CHECK (purpose IN ('email_verification')),
+    token_hash TEXT UNIQUE NOT NULL,
+    email VARCHAR NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose, created_at DESC);
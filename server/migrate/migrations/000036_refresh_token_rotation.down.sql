@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_user;
+DROP INDEX IF EXISTS idx_refresh_tokens_family;
+
+-- hashed tokens can't be turned back into tokens; everyone logs in again
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS used_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS family_id;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
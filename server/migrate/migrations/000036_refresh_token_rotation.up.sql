@@ -0,0 +1,15 @@
+-- refresh tokens are stored as the SHA-256 of the token, hex encoded. Hashing
+-- the existing ones in place keeps current sessions signed in.
+UPDATE refresh_tokens SET token = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+
+-- every refresh replaces the token with a new one in the same family, which
+-- lasts as long as the login it started from. A used token coming back means
+-- it was stolen, and the whole family is revoked.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE refresh_tokens ALTER COLUMN family_id DROP DEFAULT;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE;
+UPDATE refresh_tokens t SET revoked_at = s.revoked_at FROM sessions s WHERE s.id = t.family_id;
+
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS refresh_tokens_family_id_fkey;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,7 @@
+DELETE FROM user_tokens WHERE purpose = 'two_factor_login';
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check CHECK (purpose IN ('email_verification', 'password_reset'));
+ALTER TABLE user_tokens DROP COLUMN IF EXISTS attempts;
+
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS two_factor;
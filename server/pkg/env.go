@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -57,3 +58,19 @@ func GetEnvFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+// GetEnvDurations reads a comma separated list of durations, e.g. "24h,1h,15m"
+func GetEnvDurations(key string, defaultValue []time.Duration) []time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		var durations []time.Duration
+		for _, part := range strings.Split(value, ",") {
+			valueTD, err := time.ParseDuration(strings.TrimSpace(part))
+			if err != nil {
+				return defaultValue
+			}
+			durations = append(durations, valueTD)
+		}
+		return durations
+	}
+	return defaultValue
+}